@@ -1,6 +1,7 @@
 package users
 
 import (
+	"context"
 	"encoding/base64"
 	"io"
 	"net/http"
@@ -15,20 +16,35 @@ import (
 	"goWebExample/internal/infra/di/container"
 	"goWebExample/internal/pkg/handlers"
 	"goWebExample/internal/pkg/module"
-	userRepo "goWebExample/internal/repository/user"
 	"goWebExample/internal/service"
 	"goWebExample/internal/service/user"
 )
 
+// errTwoFactorRequired 启用双重认证的账户无法通过该接口一步登录
+const errTwoFactorRequired = "该账户已启用双重认证，请使用 /users/login 与 /users/login/2fa 两步登录"
+
+// authenticator 登录所需的用户服务
+type authenticator interface {
+	Login(ctx context.Context, username string, password string, ip string) (*user.AuthResponse, error)
+}
+
 // UserHandler 处理用户相关的HTTP请求
 type UserHandler struct {
 	logger *zap.Logger
+	users  func() authenticator // 获取用户服务，未初始化时返回 nil
 }
 
 // NewUserHandler 创建一个新的用户处理器
 func NewUserHandler(logger *zap.Logger) *UserHandler {
 	return &UserHandler{
 		logger: logger,
+		users: func() authenticator {
+			srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+			if !ok || srv == nil {
+				return nil
+			}
+			return srv
+		},
 	}
 }
 
@@ -89,8 +105,8 @@ func (h *UserHandler) Login(ctx *gin.Context) {
 	}
 
 	// 获取用户服务实例
-	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
-	if !ok || srv == nil {
+	srv := h.users()
+	if srv == nil {
 		h.logger.Error("用户服务未初始化")
 		resp := &pb.LoginResponse{
 			Error: "用户服务未初始化",
@@ -107,7 +123,7 @@ func (h *UserHandler) Login(ctx *gin.Context) {
 	}
 
 	// 处理登录请求
-	result, err := srv.Login(req.Username, req.Password, ctx.ClientIP())
+	result, err := srv.Login(ctx.Request.Context(), req.Username, req.Password, ctx.ClientIP())
 	if err != nil {
 		h.logger.Error("登录失败", zap.Error(err))
 		resp := &pb.LoginResponse{
@@ -124,6 +140,22 @@ func (h *UserHandler) Login(ctx *gin.Context) {
 		return
 	}
 
+	// 该接口无法返回第二步登录所需的 mfaToken，启用双重认证的账户需使用 REST 两步登录
+	if result.MFARequired {
+		resp := &pb.LoginResponse{
+			Error: errTwoFactorRequired,
+		}
+
+		// 根据请求的 Content-Type 返回相应格式的响应
+		if strings.Contains(contentType, "application/json") {
+			ctx.JSON(http.StatusForbidden, resp)
+		} else {
+			data, _ := proto.Marshal(resp)
+			ctx.Data(http.StatusForbidden, "application/x-protobuf", data)
+		}
+		return
+	}
+
 	// 构造成功响应
 	resp := &pb.LoginResponse{
 		Token:    result.AccessToken,
@@ -152,18 +184,11 @@ func init() {
 		"user",
 		// 服务创建函数
 		func(logger *zap.Logger, container *container.ServiceContainer) (string, interface{}) {
-			if container != nil && container.DBConnector != nil {
-				userRepository := userRepo.NewUserRepository(container.DBConnector)
-				jwtManager := container.GetJWTManager()
-				if jwtManager == nil {
-					logger.Error("无法初始化用户服务：JWT管理器未初始化")
-					return "", nil
-				}
-				userSvc := user.NewUserService(userRepository, logger, jwtManager)
-				return user.ServiceName, userSvc
+			userSvc := user.NewUserServiceFromContainer(logger, container)
+			if userSvc == nil {
+				return "", nil
 			}
-			logger.Error("无法初始化用户服务：数据库连接器未初始化")
-			return "", nil
+			return user.ServiceName, userSvc
 		},
 		// 处理器创建函数
 		func(logger *zap.Logger) handlers.Handler {
@@ -0,0 +1,84 @@
+package users
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"goWebExample/api/protobuf/users/pb"
+	"goWebExample/internal/service/user"
+)
+
+// fakeAuthenticator 返回固定登录结果的用户服务
+type fakeAuthenticator struct {
+	result *user.AuthResponse
+}
+
+func (f *fakeAuthenticator) Login(context.Context, string, string, string) (*user.AuthResponse, error) {
+	return f.result, nil
+}
+
+// login 以指定 Content-Type 调用登录接口
+func login(t *testing.T, result *user.AuthResponse, contentType string) (int, *pb.LoginResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	h := NewUserHandler(zap.NewNop())
+	h.users = func() authenticator { return &fakeAuthenticator{result: result} }
+	router := gin.New()
+	h.RegisterRoutes(router.Group("/api"))
+
+	var body []byte
+	req := &pb.LoginRequest{Username: "alice", Password: "secret"}
+	if contentType == "application/json" {
+		body, _ = protojson.Marshal(req)
+	} else {
+		body, _ = proto.Marshal(req)
+	}
+
+	w := httptest.NewRecorder()
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/users/proto/login", strings.NewReader(string(body)))
+	httpReq.Header.Set("Content-Type", contentType)
+	router.ServeHTTP(w, httpReq)
+
+	var resp pb.LoginResponse
+	var err error
+	if contentType == "application/json" {
+		err = protojson.Unmarshal(w.Body.Bytes(), &resp)
+	} else {
+		err = proto.Unmarshal(w.Body.Bytes(), &resp)
+	}
+	if err != nil {
+		t.Fatalf("decode response error = %v, body = %s", err, w.Body.String())
+	}
+	return w.Code, &resp
+}
+
+func TestLoginTwoFactorUser(t *testing.T) {
+	challenge := &user.AuthResponse{MFARequired: true, MFAToken: "mfa-token"}
+
+	for _, contentType := range []string{"application/json", "application/x-protobuf"} {
+		t.Run(contentType, func(t *testing.T) {
+			code, resp := login(t, challenge, contentType)
+			if code != http.StatusForbidden || resp.Error != errTwoFactorRequired || resp.Token != "" {
+				t.Errorf("status = %d, response = %v, want the 2-step login required", code, resp)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	result := &user.AuthResponse{AccessToken: "access-token", User: &user.UserDTO{Nickname: "Alice", Email: "alice@example.com"}}
+
+	code, resp := login(t, result, "application/json")
+	if code != http.StatusOK || resp.Token != "access-token" || resp.Email != "alice@example.com" {
+		t.Errorf("status = %d, response = %v, want the access token", code, resp)
+	}
+}
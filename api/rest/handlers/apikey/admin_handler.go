@@ -0,0 +1,422 @@
+package apikey
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"goWebExample/api/rest/handlers/apikey/request"
+	"goWebExample/api/rest/response"
+	"goWebExample/internal/pkg/handlers"
+	"goWebExample/internal/pkg/middleware"
+	"goWebExample/internal/pkg/module"
+	"goWebExample/internal/pkg/rbac"
+	apikeyRepo "goWebExample/internal/repository/apikey"
+	"goWebExample/internal/service"
+	"goWebExample/internal/service/apikey"
+)
+
+func init() {
+	// 注册管理模块，复用 apikey 模块创建的API密钥服务
+	module.GetRegistry().Register(module.NewBaseModule(
+		"apikey-admin",
+		nil,
+		// 处理器创建函数
+		func(logger *zap.Logger) handlers.Handler {
+			return NewAPIKeyAdminHandler(logger)
+		},
+	))
+}
+
+// APIKeyAdminHandler 处理API密钥管理相关的HTTP请求
+type APIKeyAdminHandler struct {
+	logger *zap.Logger
+}
+
+// NewAPIKeyAdminHandler 创建一个新的API密钥管理处理器
+func NewAPIKeyAdminHandler(logger *zap.Logger) *APIKeyAdminHandler {
+	return &APIKeyAdminHandler{
+		logger: logger,
+	}
+}
+
+// GetRouteGroup 获取路由组
+func (h *APIKeyAdminHandler) GetRouteGroup() handlers.RouteGroup {
+	return handlers.Admin
+}
+
+// CreateKey godoc
+// @Summary      创建API密钥
+// @Description  生成新的API密钥与秘钥，秘钥只在本次响应中返回，请妥善保存
+// @Tags         admin-apikeys
+// @Accept       json
+// @Produce      json
+// @Param        request body request.CreateAPIKeyRequest true "创建API密钥请求参数"
+// @Success      200  {object}  response.Response{data=apikey.APIKeyWithSecret}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/apikeys [post]
+func (h *APIKeyAdminHandler) CreateKey(c *gin.Context) {
+	srv, ok := h.apiKeyService(c)
+	if !ok {
+		return
+	}
+
+	var req request.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	key, err := srv.CreateKey(apikey.CreateKeyParams{
+		Description:   req.Description,
+		Scopes:        req.Scopes,
+		AllowedRoutes: req.AllowedRoutes,
+		Limits:        req.Limits(),
+		ExpiredAt:     time.Now().AddDate(0, 0, req.ValidDays),
+	})
+	if err != nil {
+		h.writeError(c, "创建API密钥失败", err)
+		return
+	}
+	h.logger.Info("管理员创建API密钥", zap.String("apiKey", key.APIKey), zap.String("operator", h.operator(c)))
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response.SuccessWithMessage("API密钥已创建，秘钥只显示这一次", key))
+}
+
+// ListKeys godoc
+// @Summary      获取API密钥列表
+// @Description  获取全部API密钥，不包含秘钥
+// @Tags         admin-apikeys
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  response.Response{data=[]apikey.APIKeyDTO}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/apikeys [get]
+func (h *APIKeyAdminHandler) ListKeys(c *gin.Context) {
+	srv, ok := h.apiKeyService(c)
+	if !ok {
+		return
+	}
+
+	keys, err := srv.GetAll()
+	if err != nil {
+		h.writeError(c, "获取API密钥列表失败", err)
+		return
+	}
+	response.SuccessWithData(c, keys)
+}
+
+// GetKey godoc
+// @Summary      获取API密钥详情
+// @Tags         admin-apikeys
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "API密钥ID"
+// @Success      200  {object}  response.Response{data=apikey.APIKeyDTO}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/apikeys/{id} [get]
+func (h *APIKeyAdminHandler) GetKey(c *gin.Context) {
+	srv, ok := h.apiKeyService(c)
+	if !ok {
+		return
+	}
+	id, ok := h.keyID(c)
+	if !ok {
+		return
+	}
+
+	key, err := srv.GetByID(id)
+	if err != nil {
+		h.writeError(c, "获取API密钥失败", err)
+		return
+	}
+	response.SuccessWithData(c, key)
+}
+
+// DisableKey godoc
+// @Summary      禁用API密钥
+// @Description  禁用后该密钥的签名请求与已签发的 access token 立即失效
+// @Tags         admin-apikeys
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "API密钥ID"
+// @Success      200  {object}  response.Response{data=apikey.APIKeyDTO}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/apikeys/{id}/disable [post]
+func (h *APIKeyAdminHandler) DisableKey(c *gin.Context) {
+	srv, ok := h.apiKeyService(c)
+	if !ok {
+		return
+	}
+	id, ok := h.keyID(c)
+	if !ok {
+		return
+	}
+
+	key, err := srv.DisableKey(id)
+	if err != nil {
+		h.writeError(c, "禁用API密钥失败", err)
+		return
+	}
+	h.logger.Info("管理员禁用API密钥", zap.String("apiKey", key.APIKey), zap.String("operator", h.operator(c)))
+	c.JSON(http.StatusOK, response.SuccessWithMessage("API密钥已禁用", key))
+}
+
+// UpdatePermissions godoc
+// @Summary      修改API密钥权限
+// @Description  修改 OAuth2 授权范围与允许访问的路由规则，路由规则对后续请求立即生效，授权范围对新签发的 token 生效
+// @Tags         admin-apikeys
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "API密钥ID"
+// @Param        request body request.UpdateAPIKeyPermissionsRequest true "权限参数"
+// @Success      200  {object}  response.Response{data=apikey.APIKeyDTO}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/apikeys/{id}/permissions [put]
+func (h *APIKeyAdminHandler) UpdatePermissions(c *gin.Context) {
+	srv, ok := h.apiKeyService(c)
+	if !ok {
+		return
+	}
+	id, ok := h.keyID(c)
+	if !ok {
+		return
+	}
+
+	var req request.UpdateAPIKeyPermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	key, err := srv.UpdatePermissions(id, req.Scopes, req.AllowedRoutes)
+	if err != nil {
+		h.writeError(c, "修改API密钥权限失败", err)
+		return
+	}
+	h.logger.Info("管理员修改API密钥权限", zap.String("apiKey", key.APIKey), zap.String("operator", h.operator(c)))
+	c.JSON(http.StatusOK, response.SuccessWithMessage("API密钥权限已修改", key))
+}
+
+// UpdateLimits godoc
+// @Summary      修改API密钥限流与配额
+// @Description  修改每分钟请求数上限与每日、每月配额，对后续请求立即生效，0 表示不限制
+// @Tags         admin-apikeys
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "API密钥ID"
+// @Param        request body request.UpdateAPIKeyLimitsRequest true "限流与配额参数"
+// @Success      200  {object}  response.Response{data=apikey.APIKeyDTO}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/apikeys/{id}/limits [put]
+func (h *APIKeyAdminHandler) UpdateLimits(c *gin.Context) {
+	srv, ok := h.apiKeyService(c)
+	if !ok {
+		return
+	}
+	id, ok := h.keyID(c)
+	if !ok {
+		return
+	}
+
+	var req request.UpdateAPIKeyLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	key, err := srv.UpdateLimits(id, req.Limits())
+	if err != nil {
+		h.writeError(c, "修改API密钥限流与配额失败", err)
+		return
+	}
+	h.logger.Info("管理员修改API密钥限流与配额", zap.String("apiKey", key.APIKey), zap.String("operator", h.operator(c)))
+	c.JSON(http.StatusOK, response.SuccessWithMessage("API密钥限流与配额已修改", key))
+}
+
+// ExtendKey godoc
+// @Summary      续期API密钥
+// @Description  从当前过期时间起延长有效期，已过期的密钥从当前时间起计算
+// @Tags         admin-apikeys
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "API密钥ID"
+// @Param        request body request.ExtendAPIKeyRequest true "续期请求参数"
+// @Success      200  {object}  response.Response{data=apikey.APIKeyDTO}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/apikeys/{id}/extend [post]
+func (h *APIKeyAdminHandler) ExtendKey(c *gin.Context) {
+	srv, ok := h.apiKeyService(c)
+	if !ok {
+		return
+	}
+	id, ok := h.keyID(c)
+	if !ok {
+		return
+	}
+
+	var req request.ExtendAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	key, err := srv.ExtendKey(id, time.Duration(req.Days)*24*time.Hour)
+	if err != nil {
+		h.writeError(c, "续期API密钥失败", err)
+		return
+	}
+	h.logger.Info("管理员续期API密钥", zap.String("apiKey", key.APIKey), zap.String("operator", h.operator(c)))
+	c.JSON(http.StatusOK, response.SuccessWithMessage("API密钥已续期", key))
+}
+
+// RotateSecret godoc
+// @Summary      轮换API密钥秘钥
+// @Description  生成新秘钥并只在本次响应中返回；过渡期内新旧秘钥均可使用，便于客户端平滑切换
+// @Tags         admin-apikeys
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "API密钥ID"
+// @Param        request body request.RotateAPIKeyRequest false "轮换请求参数"
+// @Success      200  {object}  response.Response{data=apikey.APIKeyWithSecret}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/apikeys/{id}/rotate [post]
+func (h *APIKeyAdminHandler) RotateSecret(c *gin.Context) {
+	srv, ok := h.apiKeyService(c)
+	if !ok {
+		return
+	}
+	id, ok := h.keyID(c)
+	if !ok {
+		return
+	}
+
+	var req request.RotateAPIKeyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+			return
+		}
+	}
+	overlap := apikey.DefaultRotationOverlap
+	if req.OverlapHours != nil {
+		overlap = time.Duration(*req.OverlapHours) * time.Hour
+	}
+
+	key, err := srv.RotateSecret(id, overlap)
+	if err != nil {
+		h.writeError(c, "轮换API密钥秘钥失败", err)
+		return
+	}
+	h.logger.Info("管理员轮换API密钥秘钥", zap.String("apiKey", key.APIKey), zap.String("operator", h.operator(c)))
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response.SuccessWithMessage("秘钥已轮换，新秘钥只显示这一次", key))
+}
+
+// apiKeyService 从服务注册器获取API密钥服务
+func (h *APIKeyAdminHandler) apiKeyService(c *gin.Context) (*apikey.APIKeyService, bool) {
+	srv, ok := service.GetRegistry().Get(apikey.ServiceName).(*apikey.APIKeyService)
+	if !ok || srv == nil {
+		h.logger.Error("apikey service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "API密钥服务未初始化"))
+		return nil, false
+	}
+	return srv, true
+}
+
+// keyID 解析路径中的API密钥ID
+func (h *APIKeyAdminHandler) keyID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "无效的API密钥ID"))
+		return 0, false
+	}
+	return id, true
+}
+
+// operator 获取当前操作的管理员用户名
+func (h *APIKeyAdminHandler) operator(c *gin.Context) string {
+	if claims, ok := middleware.GetClaims(c); ok {
+		return claims.Username
+	}
+	return ""
+}
+
+// writeError 将API密钥相关错误映射为 HTTP 状态码并写入响应
+func (h *APIKeyAdminHandler) writeError(c *gin.Context, action string, err error) {
+	var status int
+	switch {
+	case errors.Is(err, apikey.ErrInvalidExpiry),
+		errors.Is(err, apikey.ErrInvalidOverlap),
+		errors.Is(err, apikey.ErrInvalidScope),
+		errors.Is(err, apikey.ErrInvalidLimits),
+		errors.Is(err, apikeyRepo.ErrInvalidRouteRule):
+		status = http.StatusBadRequest
+	case errors.Is(err, apikeyRepo.ErrAPIKeyNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, apikey.ErrKeyDisabled):
+		status = http.StatusConflict
+	default:
+		h.logger.Error(action, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, action))
+		return
+	}
+	c.JSON(status, response.Fail(status, err.Error()))
+}
+
+// RegisterRoutes 注册API密钥管理路由，管理后台路由组已统一完成登录认证
+func (h *APIKeyAdminHandler) RegisterRoutes(adminGroup *gin.RouterGroup) {
+	keysGroup := adminGroup.Group("/apikeys")
+	{
+		keysGroup.GET("", middleware.RequirePermission(rbac.PermAPIKeysRead), h.ListKeys)
+		keysGroup.GET("/:id", middleware.RequirePermission(rbac.PermAPIKeysRead), h.GetKey)
+		keysGroup.POST("", middleware.RequirePermission(rbac.PermAPIKeysWrite), h.CreateKey)
+		keysGroup.POST("/:id/disable", middleware.RequirePermission(rbac.PermAPIKeysWrite), h.DisableKey)
+		keysGroup.PUT("/:id/permissions", middleware.RequirePermission(rbac.PermAPIKeysWrite), h.UpdatePermissions)
+		keysGroup.PUT("/:id/limits", middleware.RequirePermission(rbac.PermAPIKeysWrite), h.UpdateLimits)
+		keysGroup.POST("/:id/extend", middleware.RequirePermission(rbac.PermAPIKeysWrite), h.ExtendKey)
+		keysGroup.POST("/:id/rotate", middleware.RequirePermission(rbac.PermAPIKeysWrite), h.RotateSecret)
+	}
+}
@@ -0,0 +1,42 @@
+package request
+
+import "goWebExample/internal/repository/apikey"
+
+// CreateAPIKeyRequest 创建API密钥请求参数
+//
+// allowedRoutes 为允许访问的路由规则，格式为 "[METHOD[,METHOD...]:]/path/prefix"，如 "GET:/openapi/data"，为空时不限制
+type CreateAPIKeyRequest struct {
+	Description   string   `json:"description" binding:"max=255"`
+	Scopes        []string `json:"scopes" binding:"omitempty,dive,min=1,max=64"`
+	AllowedRoutes []string `json:"allowedRoutes" binding:"omitempty,dive,min=1,max=256"`
+	ValidDays     int      `json:"validDays" binding:"required,min=1,max=3650"` // 有效天数
+	UpdateAPIKeyLimitsRequest
+}
+
+// UpdateAPIKeyPermissionsRequest 修改API密钥权限请求参数，未传的字段视为清空
+type UpdateAPIKeyPermissionsRequest struct {
+	Scopes        []string `json:"scopes" binding:"omitempty,dive,min=1,max=64"`
+	AllowedRoutes []string `json:"allowedRoutes" binding:"omitempty,dive,min=1,max=256"`
+}
+
+// ExtendAPIKeyRequest 续期API密钥请求参数，从当前过期时间（已过期时从当前时间）起延长
+type ExtendAPIKeyRequest struct {
+	Days int `json:"days" binding:"required,min=1,max=3650"`
+}
+
+// RotateAPIKeyRequest 轮换秘钥请求参数，overlapHours 为旧秘钥的过渡期（小时），不传时默认 24 小时，0 表示旧秘钥立即失效
+type RotateAPIKeyRequest struct {
+	OverlapHours *int `json:"overlapHours" binding:"omitempty,min=0,max=168"`
+}
+
+// UpdateAPIKeyLimitsRequest 修改API密钥限流与配额请求参数，0 或不传表示不限制
+type UpdateAPIKeyLimitsRequest struct {
+	RateLimit    int64 `json:"rateLimit" binding:"min=0,max=1000000"`       // 每分钟请求数上限
+	DailyQuota   int64 `json:"dailyQuota" binding:"min=0,max=1000000000"`   // 每日配额
+	MonthlyQuota int64 `json:"monthlyQuota" binding:"min=0,max=1000000000"` // 每月配额
+}
+
+// Limits 转换为限流与配额设置
+func (r UpdateAPIKeyLimitsRequest) Limits() apikey.UsageLimits {
+	return apikey.UsageLimits{RatePerMinute: r.RateLimit, Daily: r.DailyQuota, Monthly: r.MonthlyQuota}
+}
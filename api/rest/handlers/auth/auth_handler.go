@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"goWebExample/api/rest/response"
+	"goWebExample/internal/infra/di/container"
+	"goWebExample/internal/pkg/handlers"
+	"goWebExample/internal/pkg/middleware"
+	"goWebExample/internal/pkg/module"
+	"goWebExample/internal/service"
+	"goWebExample/internal/service/auth"
+	"goWebExample/internal/service/user"
+)
+
+// stateCookie 保存签名 state 的 Cookie，只在 OIDC 路由下发送
+const (
+	stateCookie     = "oidc_state"
+	stateCookiePath = "/api/auth/oidc"
+)
+
+func init() {
+	// 注册模块
+	module.GetRegistry().Register(module.NewBaseModule(
+		"auth",
+		// 服务创建函数
+		func(logger *zap.Logger, container *container.ServiceContainer) (string, interface{}) {
+			authSvc := auth.NewOIDCServiceFromContainer(logger, container)
+			if authSvc == nil {
+				return "", nil
+			}
+			return auth.ServiceName, authSvc
+		},
+		// 处理器创建函数
+		func(logger *zap.Logger) handlers.Handler {
+			return NewAuthHandler(logger)
+		},
+	))
+}
+
+// AuthHandler 处理第三方登录相关的HTTP请求
+type AuthHandler struct {
+	logger *zap.Logger
+}
+
+// NewAuthHandler 创建一个新的第三方登录处理器
+func NewAuthHandler(logger *zap.Logger) *AuthHandler {
+	return &AuthHandler{
+		logger: logger,
+	}
+}
+
+// GetRouteGroup 获取路由组
+func (h *AuthHandler) GetRouteGroup() handlers.RouteGroup {
+	return handlers.API
+}
+
+// ListProviders godoc
+// @Summary      获取第三方登录方式
+// @Description  获取已配置的 OpenID Connect 身份提供方
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  response.Response{data=[]auth.ProviderInfo}
+// @Router       /auth/oidc/providers [get]
+func (h *AuthHandler) ListProviders(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(auth.ServiceName).(*auth.OIDCService)
+	if !ok || srv == nil {
+		response.SuccessWithData(c, []auth.ProviderInfo{})
+		return
+	}
+	response.SuccessWithData(c, srv.Providers())
+}
+
+// Login godoc
+// @Summary      第三方登录
+// @Description  跳转到身份提供方进行授权（授权码 + PKCE），state 保存在 Cookie 中
+// @Tags         auth
+// @Param        provider path string true "身份提供方"
+// @Success      302
+// @Failure      404  {object}  response.Response
+// @Failure      502  {object}  response.Response
+// @Router       /auth/oidc/{provider}/login [get]
+func (h *AuthHandler) Login(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(auth.ServiceName).(*auth.OIDCService)
+	if !ok || srv == nil {
+		h.logger.Error("auth service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "第三方登录服务未初始化"))
+		return
+	}
+
+	start, err := srv.BeginLogin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		h.writeAuthError(c, "发起第三方登录失败", err)
+		return
+	}
+
+	h.setStateCookie(c, start.StateToken, int(start.TTL.Seconds()))
+	c.Redirect(http.StatusFound, start.AuthURL)
+}
+
+// Callback godoc
+// @Summary      第三方登录回调
+// @Description  校验 state，使用授权码换取并校验 ID Token，关联本站用户后返回JWT token；启用会话模式时改为写入会话 Cookie
+// @Tags         auth
+// @Produce      json
+// @Param        provider path string true "身份提供方"
+// @Param        code query string true "授权码"
+// @Param        state query string true "state"
+// @Success      200  {object}  response.Response{data=user.AuthResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      423  {object}  response.Response
+// @Failure      502  {object}  response.Response
+// @Router       /auth/oidc/{provider}/callback [get]
+func (h *AuthHandler) Callback(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(auth.ServiceName).(*auth.OIDCService)
+	if !ok || srv == nil {
+		h.logger.Error("auth service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "第三方登录服务未初始化"))
+		return
+	}
+	userSvc, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || userSvc == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+
+	// state 只能使用一次，无论结果如何都清除
+	stateToken, _ := c.Cookie(stateCookie)
+	h.setStateCookie(c, "", -1)
+
+	// 用户在身份提供方拒绝授权等情况
+	if errCode := c.Query("error"); errCode != "" {
+		h.logger.Warn("身份提供方返回错误", zap.String("provider", c.Param("provider")),
+			zap.String("error", errCode), zap.String("description", c.Query("error_description")))
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "第三方授权未完成"))
+		return
+	}
+
+	result, err := srv.CompleteLogin(c.Request.Context(), c.Param("provider"), c.Query("code"), c.Query("state"), stateToken)
+	if err != nil {
+		h.writeAuthError(c, "第三方登录失败", err)
+		return
+	}
+
+	// 回调由浏览器直接访问，启用会话模式时使用会话 Cookie 登录
+	reqCtx := c.Request.Context()
+	if userSvc.SessionEnabled() {
+		reqCtx = user.WithSession(reqCtx, c.ClientIP(), c.Request.UserAgent())
+	}
+	resp, err := userSvc.LoginWithIdentity(reqCtx, result.Identity, result.Policy, c.ClientIP())
+	if err != nil {
+		h.writeAuthError(c, "第三方登录失败", err)
+		return
+	}
+	if resp.SessionToken != "" {
+		middleware.SetSessionCookie(c, userSvc.SessionConfig(), resp.SessionToken, int(resp.SessionExpiresIn))
+	}
+	response.SuccessWithData(c, resp)
+}
+
+// CSRFToken godoc
+// @Summary      获取CSRF token
+// @Description  为当前会话签发 CSRF token 并写入 CSRF Cookie；使用会话 Cookie 认证时，非 GET 请求需在请求头中提交该 token，登录后需重新获取
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  response.Response{data=middleware.CSRFToken}
+// @Failure      404  {object}  response.Response
+// @Router       /auth/csrf [get]
+func (h *AuthHandler) CSRFToken(c *gin.Context) {
+	token, err := middleware.IssueCSRFToken(c)
+	if errors.Is(err, middleware.ErrCSRFDisabled) {
+		c.JSON(http.StatusNotFound, response.Fail(http.StatusNotFound, err.Error()))
+		return
+	}
+	if err != nil {
+		h.logger.Error("签发CSRF token失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "签发CSRF token失败"))
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	response.SuccessWithData(c, token)
+}
+
+// setStateCookie 写入或清除 state Cookie；回调为跨站顶级跳转，需使用 Lax 才能携带
+func (h *AuthHandler) setStateCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(stateCookie, value, maxAge, stateCookiePath, "", c.Request.TLS != nil, true)
+}
+
+// writeAuthError 根据错误类型返回对应的状态码
+func (h *AuthHandler) writeAuthError(c *gin.Context, action string, err error) {
+	var status int
+	switch {
+	case errors.Is(err, auth.ErrProviderNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, auth.ErrInvalidState):
+		status = http.StatusBadRequest
+	case errors.Is(err, auth.ErrInvalidIdentity):
+		status = http.StatusUnauthorized
+	case errors.Is(err, auth.ErrProviderUnavailable):
+		status = http.StatusBadGateway
+	case errors.Is(err, user.ErrIdentityNotLinked),
+		errors.Is(err, user.ErrAccountDisabled),
+		errors.Is(err, user.ErrEmailNotVerified):
+		status = http.StatusForbidden
+	case errors.Is(err, user.ErrAccountLocked):
+		status = http.StatusLocked
+	case errors.Is(err, user.ErrExternalLoginUnavailable):
+		status = http.StatusServiceUnavailable
+	default:
+		h.logger.Error(action, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, action))
+		return
+	}
+	c.JSON(status, response.Fail(status, err.Error()))
+}
+
+// RegisterRoutes 注册第三方登录与 CSRF token 路由
+func (h *AuthHandler) RegisterRoutes(apiGroup *gin.RouterGroup) {
+	apiGroup.GET("/auth/csrf", h.CSRFToken)
+
+	oidcGroup := apiGroup.Group("/auth/oidc")
+	{
+		oidcGroup.GET("/providers", h.ListProviders)
+		oidcGroup.GET("/:provider/login", h.Login)
+		oidcGroup.GET("/:provider/callback", h.Callback)
+	}
+}
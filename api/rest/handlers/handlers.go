@@ -3,10 +3,13 @@ package handlers
 import (
 	// 在这里导入所有的 handlers
 	_ "goWebExample/api/protobuf/users"
+	_ "goWebExample/api/rest/handlers/apikey"
+	_ "goWebExample/api/rest/handlers/auth"
 	_ "goWebExample/api/rest/handlers/datacenter"
 	_ "goWebExample/api/rest/handlers/info"
 	_ "goWebExample/api/rest/handlers/ly_stop"
 	_ "goWebExample/api/rest/handlers/openapi"
+	_ "goWebExample/api/rest/handlers/scheduler"
 	_ "goWebExample/api/rest/handlers/stream"
 	_ "goWebExample/api/rest/handlers/user"
 )
@@ -0,0 +1,199 @@
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"goWebExample/internal/service"
+	apikeysvc "goWebExample/internal/service/apikey"
+)
+
+// OAuth2 错误码（RFC 6749 第 5.2 节、RFC 7009 第 2.2.1 节）
+const (
+	oauthInvalidRequest       = "invalid_request"
+	oauthInvalidClient        = "invalid_client"
+	oauthInvalidScope         = "invalid_scope"
+	oauthUnsupportedGrantType = "unsupported_grant_type"
+	oauthServerError          = "server_error"
+	oauthUnavailable          = "temporarily_unavailable"
+)
+
+// oauthError OAuth2 错误响应，标准 OAuth 客户端库依赖该格式，因此不使用统一响应结构
+type oauthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// Token godoc
+// @Summary      OAuth2 token 端点
+// @Description  客户端凭证模式（client_credentials）：client_id 为 API Key，client_secret 为 API Secret，支持 HTTP Basic 或表单传参
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        grant_type formData string true "固定为 client_credentials"
+// @Param        scope formData string false "申请的授权范围，空格分隔，为空时授予全部允许的范围"
+// @Param        client_id formData string false "客户端ID（未使用 HTTP Basic 时必填）"
+// @Param        client_secret formData string false "客户端秘钥（未使用 HTTP Basic 时必填）"
+// @Success      200  {object}  apikey.ClientToken
+// @Failure      400  {object}  oauthError
+// @Failure      401  {object}  oauthError
+// @Router       /oauth/token [post]
+func (h *OpenAPIService) Token(c *gin.Context) {
+	srv, ok := h.apiKeyService(c)
+	if !ok {
+		return
+	}
+	clientID, clientSecret, ok := h.clientCredentials(c)
+	if !ok {
+		return
+	}
+
+	if grantType := c.PostForm("grant_type"); grantType != "client_credentials" {
+		h.writeOAuthError(c, http.StatusBadRequest, oauthUnsupportedGrantType, "仅支持 client_credentials")
+		return
+	}
+
+	token, err := srv.IssueClientToken(clientID, clientSecret, c.PostForm("scope"))
+	if err != nil {
+		h.writeOAuthServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, token)
+}
+
+// Introspect godoc
+// @Summary      OAuth2 token 内省
+// @Description  查询 access token 是否有效（RFC 7662），客户端只能查询签发给自己的 token
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        token formData string true "access token"
+// @Success      200  {object}  apikey.Introspection
+// @Failure      400  {object}  oauthError
+// @Failure      401  {object}  oauthError
+// @Router       /oauth/introspect [post]
+func (h *OpenAPIService) Introspect(c *gin.Context) {
+	srv, ok := h.apiKeyService(c)
+	if !ok {
+		return
+	}
+	clientID, clientSecret, ok := h.clientCredentials(c)
+	if !ok {
+		return
+	}
+
+	tokenString := c.PostForm("token")
+	if tokenString == "" {
+		h.writeOAuthError(c, http.StatusBadRequest, oauthInvalidRequest, "缺少 token 参数")
+		return
+	}
+
+	result, err := srv.IntrospectToken(c.Request.Context(), clientID, clientSecret, tokenString)
+	if err != nil {
+		h.writeOAuthServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, result)
+}
+
+// Revoke godoc
+// @Summary      OAuth2 token 吊销
+// @Description  吊销 access token（RFC 7009），token 无效或不属于该客户端时同样返回 200
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Param        token formData string true "access token"
+// @Success      200
+// @Failure      400  {object}  oauthError
+// @Failure      401  {object}  oauthError
+// @Router       /oauth/revoke [post]
+func (h *OpenAPIService) Revoke(c *gin.Context) {
+	srv, ok := h.apiKeyService(c)
+	if !ok {
+		return
+	}
+	clientID, clientSecret, ok := h.clientCredentials(c)
+	if !ok {
+		return
+	}
+
+	tokenString := c.PostForm("token")
+	if tokenString == "" {
+		h.writeOAuthError(c, http.StatusBadRequest, oauthInvalidRequest, "缺少 token 参数")
+		return
+	}
+
+	if err := srv.RevokeToken(c.Request.Context(), clientID, clientSecret, tokenString); err != nil {
+		h.writeOAuthServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// apiKeyService 从服务注册器获取API密钥服务
+func (h *OpenAPIService) apiKeyService(c *gin.Context) (*apikeysvc.APIKeyService, bool) {
+	srv, ok := service.GetRegistry().Get(apikeysvc.ServiceName).(*apikeysvc.APIKeyService)
+	if !ok || srv == nil {
+		h.logger.Error("API密钥服务未初始化")
+		h.writeOAuthError(c, http.StatusServiceUnavailable, oauthUnavailable, "API密钥服务未初始化")
+		return nil, false
+	}
+	return srv, true
+}
+
+// clientCredentials 获取客户端凭证，支持 HTTP Basic（client_secret_basic）与表单参数（client_secret_post），不能同时使用
+func (h *OpenAPIService) clientCredentials(c *gin.Context) (string, string, bool) {
+	formID, formSecret := c.PostForm("client_id"), c.PostForm("client_secret")
+
+	basicID, basicSecret, hasBasic := c.Request.BasicAuth()
+	if hasBasic {
+		if formSecret != "" {
+			h.writeOAuthError(c, http.StatusBadRequest, oauthInvalidRequest, "不能同时使用多种客户端认证方式")
+			return "", "", false
+		}
+		// RFC 6749 第 2.3.1 节：Basic 认证的用户名与密码需先经过 form-urlencoded 编码
+		id, err1 := url.QueryUnescape(basicID)
+		secret, err2 := url.QueryUnescape(basicSecret)
+		if err1 != nil || err2 != nil {
+			h.writeOAuthError(c, http.StatusBadRequest, oauthInvalidRequest, "客户端凭证格式错误")
+			return "", "", false
+		}
+		return id, secret, true
+	}
+
+	if formID == "" || formSecret == "" {
+		c.Header("WWW-Authenticate", `Basic realm="openapi"`)
+		h.writeOAuthError(c, http.StatusUnauthorized, oauthInvalidClient, "缺少客户端凭证")
+		return "", "", false
+	}
+	return formID, formSecret, true
+}
+
+// writeOAuthServiceError 将服务层错误转换为 OAuth2 错误响应
+func (h *OpenAPIService) writeOAuthServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, apikeysvc.ErrInvalidClient):
+		c.Header("WWW-Authenticate", `Basic realm="openapi"`)
+		h.writeOAuthError(c, http.StatusUnauthorized, oauthInvalidClient, err.Error())
+	case errors.Is(err, apikeysvc.ErrInvalidScope):
+		h.writeOAuthError(c, http.StatusBadRequest, oauthInvalidScope, err.Error())
+	case errors.Is(err, apikeysvc.ErrOAuthUnavailable):
+		h.writeOAuthError(c, http.StatusServiceUnavailable, oauthUnavailable, err.Error())
+	default:
+		h.logger.Error("OAuth2请求处理失败", zap.Error(err))
+		h.writeOAuthError(c, http.StatusInternalServerError, oauthServerError, "")
+	}
+}
+
+func (h *OpenAPIService) writeOAuthError(c *gin.Context, status int, code, description string) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, oauthError{Error: code, ErrorDescription: description})
+}
@@ -93,4 +93,13 @@ func (h *OpenAPIService) RegisterRoutes(group *gin.RouterGroup) {
 	// 注册路由
 	group.GET("/status", h.GetStatus)
 	group.GET("/data", h.GetData)
+	group.GET("/usage", h.GetUsage)
+
+	// OAuth2 端点，由 OAuthPathPrefix 豁免 OpenAPI 认证，自行校验客户端凭证
+	oauth := group.Group("/oauth")
+	{
+		oauth.POST("/token", h.Token)
+		oauth.POST("/introspect", h.Introspect)
+		oauth.POST("/revoke", h.Revoke)
+	}
 }
@@ -0,0 +1,54 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"goWebExample/api/rest/response"
+	"goWebExample/internal/pkg/middleware"
+	"goWebExample/internal/service"
+	apikeysvc "goWebExample/internal/service/apikey"
+)
+
+// usageQuery 调用量查询参数
+type usageQuery struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=90"`
+}
+
+// GetUsage godoc
+// @Summary      查询本密钥的调用量
+// @Description  返回当前API密钥的限流与配额设置、今日与本月请求数，以及最近若干天（含今天）的每日请求数；被限流或超出配额而拒绝的请求不计入
+// @Tags         openapi
+// @Accept       json
+// @Produce      json
+// @Param        days query int false "查询天数，默认 30，最大 90"
+// @Success      200  {object}  response.Response{data=apikey.UsageDTO}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      429  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /usage [get]
+func (h *OpenAPIService) GetUsage(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(apikeysvc.ServiceName).(*apikeysvc.APIKeyService)
+	if !ok || srv == nil {
+		h.logger.Error("API密钥服务未初始化")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "API密钥服务未初始化"))
+		return
+	}
+
+	var query usageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	usage, err := srv.GetUsage(c.Request.Context(), c.GetString(middleware.ClientIDKey), query.Days)
+	if err != nil {
+		h.logger.Error("查询调用量失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "查询调用量失败"))
+		return
+	}
+	response.SuccessWithData(c, usage)
+}
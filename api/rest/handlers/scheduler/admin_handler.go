@@ -0,0 +1,419 @@
+package scheduler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"goWebExample/api/rest/handlers/scheduler/request"
+	"goWebExample/api/rest/response"
+	"goWebExample/internal/pkg/handlers"
+	"goWebExample/internal/pkg/middleware"
+	"goWebExample/internal/pkg/module"
+	"goWebExample/internal/pkg/rbac"
+	"goWebExample/internal/service"
+	"goWebExample/internal/service/scheduler"
+)
+
+func init() {
+	// 注册管理模块，复用 scheduler 模块创建的调度服务
+	module.GetRegistry().Register(module.NewBaseModule(
+		"scheduler-admin",
+		nil,
+		// 处理器创建函数
+		func(logger *zap.Logger) handlers.Handler {
+			return NewSchedulerAdminHandler(logger)
+		},
+	))
+}
+
+// SchedulerAdminHandler 处理调度任务管理相关的HTTP请求
+type SchedulerAdminHandler struct {
+	logger *zap.Logger
+}
+
+// NewSchedulerAdminHandler 创建一个新的调度任务管理处理器
+func NewSchedulerAdminHandler(logger *zap.Logger) *SchedulerAdminHandler {
+	return &SchedulerAdminHandler{
+		logger: logger,
+	}
+}
+
+// GetRouteGroup 获取路由组
+func (h *SchedulerAdminHandler) GetRouteGroup() handlers.RouteGroup {
+	return handlers.Admin
+}
+
+// ListTasks godoc
+// @Summary      获取调度任务列表
+// @Description  获取全部调度任务及其状态、上次与下次执行时间、最近一次错误，已暂停的任务状态为 disabled
+// @Tags         admin-scheduler
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  response.Response{data=[]scheduler.TaskInfo}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/scheduler/tasks [get]
+func (h *SchedulerAdminHandler) ListTasks(c *gin.Context) {
+	srv, ok := h.schedulerService(c)
+	if !ok {
+		return
+	}
+	response.SuccessWithData(c, srv.ListTaskInfos())
+}
+
+// GetTask godoc
+// @Summary      获取调度任务详情
+// @Tags         admin-scheduler
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "任务ID"
+// @Success      200  {object}  response.Response{data=scheduler.TaskInfo}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/scheduler/tasks/{id} [get]
+func (h *SchedulerAdminHandler) GetTask(c *gin.Context) {
+	srv, ok := h.schedulerService(c)
+	if !ok {
+		return
+	}
+
+	task, err := srv.GetTaskInfo(c.Param("id"))
+	if err != nil {
+		h.writeError(c, "获取调度任务失败", err)
+		return
+	}
+	response.SuccessWithData(c, task)
+}
+
+// ListTaskRuns godoc
+// @Summary      获取调度任务执行历史
+// @Description  分页获取任务的执行记录，按开始时间倒序；任务删除后执行记录保留至超过保留期被清理
+// @Tags         admin-scheduler
+// @Accept       json
+// @Produce      json
+// @Param        id       path  string true  "任务ID"
+// @Param        page     query int    false "页码" default(1)
+// @Param        pageSize query int    false "每页数量" default(20)
+// @Success      200  {object}  response.ResponseWithPagination{data=[]scheduler.TaskRun}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Failure      503  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/scheduler/tasks/{id}/runs [get]
+func (h *SchedulerAdminHandler) ListTaskRuns(c *gin.Context) {
+	srv, ok := h.schedulerService(c)
+	if !ok {
+		return
+	}
+
+	var req request.ListTaskRunsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	runs, total, err := srv.ListTaskRuns(c.Request.Context(), c.Param("id"), req.Page, req.PageSize)
+	if err != nil {
+		h.writeError(c, "获取调度任务执行历史失败", err)
+		return
+	}
+	response.WithPagination(c, runs, req.Page, req.PageSize, total)
+}
+
+// RunTask godoc
+// @Summary      立即执行调度任务
+// @Description  在后台立即执行一次任务，不影响原有执行计划；已暂停或正在执行的任务不能触发
+// @Tags         admin-scheduler
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "任务ID"
+// @Success      200  {object}  response.Response{data=scheduler.TaskInfo}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/scheduler/tasks/{id}/run [post]
+func (h *SchedulerAdminHandler) RunTask(c *gin.Context) {
+	h.control(c, "执行调度任务失败", "调度任务已触发", "管理员触发调度任务",
+		func(srv scheduler.SchedulerService, id string) error {
+			return srv.RunTask(id)
+		})
+}
+
+// PauseTask godoc
+// @Summary      暂停调度任务
+// @Description  暂停后任务不再按计划执行，正在进行的执行不会被中断；暂停状态会持久化，重启后仍保持
+// @Tags         admin-scheduler
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "任务ID"
+// @Success      200  {object}  response.Response{data=scheduler.TaskInfo}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/scheduler/tasks/{id}/pause [post]
+func (h *SchedulerAdminHandler) PauseTask(c *gin.Context) {
+	h.control(c, "暂停调度任务失败", "调度任务已暂停", "管理员暂停调度任务",
+		func(srv scheduler.SchedulerService, id string) error {
+			return srv.PauseTask(id)
+		})
+}
+
+// ResumeTask godoc
+// @Summary      恢复调度任务
+// @Description  恢复已暂停的任务，按当前执行计划继续执行
+// @Tags         admin-scheduler
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "任务ID"
+// @Success      200  {object}  response.Response{data=scheduler.TaskInfo}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/scheduler/tasks/{id}/resume [post]
+func (h *SchedulerAdminHandler) ResumeTask(c *gin.Context) {
+	h.control(c, "恢复调度任务失败", "调度任务已恢复", "管理员恢复调度任务",
+		func(srv scheduler.SchedulerService, id string) error {
+			return srv.ResumeTask(id)
+		})
+}
+
+// UpdateSchedule godoc
+// @Summary      修改调度任务执行计划
+// @Description  立即按新计划调度并持久化，重启后优先于代码中注册的执行计划
+// @Tags         admin-scheduler
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "任务ID"
+// @Param        request body request.UpdateScheduleRequest true "执行计划参数"
+// @Success      200  {object}  response.Response{data=scheduler.TaskInfo}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/scheduler/tasks/{id}/schedule [put]
+func (h *SchedulerAdminHandler) UpdateSchedule(c *gin.Context) {
+	var req request.UpdateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	h.control(c, "修改调度任务执行计划失败", "调度任务执行计划已修改", "管理员修改调度任务执行计划",
+		func(srv scheduler.SchedulerService, id string) error {
+			return srv.UpdateSchedule(id, req.Schedule)
+		})
+}
+
+// UpdatePolicy godoc
+// @Summary      修改调度任务执行策略
+// @Description  设置任务的单次执行超时时间与失败重试策略，从下一次执行开始生效并持久化；重试耗尽仍失败时触发告警
+// @Tags         admin-scheduler
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "任务ID"
+// @Param        request body request.UpdatePolicyRequest true "执行策略参数"
+// @Success      200  {object}  response.Response{data=scheduler.TaskInfo}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/scheduler/tasks/{id}/policy [put]
+func (h *SchedulerAdminHandler) UpdatePolicy(c *gin.Context) {
+	var req request.UpdatePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+	policy, err := toTaskPolicy(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "时间格式错误"))
+		return
+	}
+
+	h.control(c, "修改调度任务执行策略失败", "调度任务执行策略已修改", "管理员修改调度任务执行策略",
+		func(srv scheduler.SchedulerService, id string) error {
+			return srv.SetTaskPolicy(id, &policy)
+		})
+}
+
+// ResetPolicy godoc
+// @Summary      恢复调度任务默认执行策略
+// @Description  删除任务自己的执行策略，改为使用配置中的默认超时与重试策略
+// @Tags         admin-scheduler
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "任务ID"
+// @Success      200  {object}  response.Response{data=scheduler.TaskInfo}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/scheduler/tasks/{id}/policy [delete]
+func (h *SchedulerAdminHandler) ResetPolicy(c *gin.Context) {
+	h.control(c, "恢复调度任务默认执行策略失败", "调度任务已恢复默认执行策略", "管理员恢复调度任务默认执行策略",
+		func(srv scheduler.SchedulerService, id string) error {
+			return srv.SetTaskPolicy(id, nil)
+		})
+}
+
+// toTaskPolicy 将请求参数转换为任务执行策略，空的退避时间视为 0
+func toTaskPolicy(req request.UpdatePolicyRequest) (scheduler.TaskPolicy, error) {
+	durations := []string{req.Timeout, req.InitialBackoff, req.MaxBackoff}
+	parsed := make([]time.Duration, len(durations))
+	for i, value := range durations {
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return scheduler.TaskPolicy{}, err
+		}
+		parsed[i] = d
+	}
+
+	return scheduler.TaskPolicy{
+		Timeout:        scheduler.Duration(parsed[0]),
+		MaxAttempts:    req.MaxAttempts,
+		InitialBackoff: scheduler.Duration(parsed[1]),
+		MaxBackoff:     scheduler.Duration(parsed[2]),
+		Multiplier:     req.Multiplier,
+		Jitter:         req.Jitter,
+	}, nil
+}
+
+// DeleteTask godoc
+// @Summary      删除调度任务
+// @Description  从调度器、数据库与缓存中删除任务；由代码注册的任务会在服务重启后重新创建，如需长期停用请使用暂停
+// @Tags         admin-scheduler
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "任务ID"
+// @Success      200  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/scheduler/tasks/{id} [delete]
+func (h *SchedulerAdminHandler) DeleteTask(c *gin.Context) {
+	srv, ok := h.schedulerService(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if err := srv.RemoveTask(id); err != nil {
+		h.writeError(c, "删除调度任务失败", err)
+		return
+	}
+	h.logger.Info("管理员删除调度任务", zap.String("task", id), zap.String("operator", h.operator(c)))
+	c.JSON(http.StatusOK, response.SuccessWithMessage("调度任务已删除", nil))
+}
+
+// control 执行任务操作并返回操作后的任务状态
+func (h *SchedulerAdminHandler) control(c *gin.Context, action, message, logMessage string,
+	op func(srv scheduler.SchedulerService, id string) error) {
+	srv, ok := h.schedulerService(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if err := op(srv, id); err != nil {
+		h.writeError(c, action, err)
+		return
+	}
+	h.logger.Info(logMessage, zap.String("task", id), zap.String("operator", h.operator(c)))
+
+	task, err := srv.GetTaskInfo(id)
+	if err != nil {
+		h.writeError(c, action, err)
+		return
+	}
+	c.JSON(http.StatusOK, response.SuccessWithMessage(message, task))
+}
+
+// schedulerService 从服务注册器获取调度服务
+func (h *SchedulerAdminHandler) schedulerService(c *gin.Context) (scheduler.SchedulerService, bool) {
+	srv, ok := service.GetRegistry().Get(scheduler.ServiceName).(scheduler.SchedulerService)
+	if !ok || srv == nil {
+		h.logger.Error("scheduler service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "调度服务未初始化"))
+		return nil, false
+	}
+	return srv, true
+}
+
+// operator 获取当前操作的管理员用户名
+func (h *SchedulerAdminHandler) operator(c *gin.Context) string {
+	if claims, ok := middleware.GetClaims(c); ok {
+		return claims.Username
+	}
+	return ""
+}
+
+// writeError 将调度任务相关错误映射为 HTTP 状态码并写入响应
+func (h *SchedulerAdminHandler) writeError(c *gin.Context, action string, err error) {
+	var status int
+	var message string
+	switch {
+	case errors.Is(err, scheduler.ErrInvalidSchedule):
+		status, message = http.StatusBadRequest, "执行计划格式错误"
+	case errors.Is(err, scheduler.ErrInvalidPolicy):
+		status, message = http.StatusBadRequest, "执行策略无效"
+	case errors.Is(err, scheduler.ErrTaskNotFound):
+		status, message = http.StatusNotFound, "调度任务不存在"
+	case errors.Is(err, scheduler.ErrTaskDisabled):
+		status, message = http.StatusConflict, "调度任务已暂停，请先恢复"
+	case errors.Is(err, scheduler.ErrTaskRunning):
+		status, message = http.StatusConflict, "调度任务正在执行"
+	case errors.Is(err, scheduler.ErrHistoryUnavailable):
+		status, message = http.StatusServiceUnavailable, "执行历史不可用，数据库未连接"
+	default:
+		h.logger.Error(action, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, action))
+		return
+	}
+	c.JSON(status, response.Fail(status, message))
+}
+
+// RegisterRoutes 注册调度任务管理路由，管理后台路由组已统一完成登录认证
+func (h *SchedulerAdminHandler) RegisterRoutes(adminGroup *gin.RouterGroup) {
+	tasksGroup := adminGroup.Group("/scheduler/tasks")
+	{
+		tasksGroup.GET("", middleware.RequirePermission(rbac.PermSchedulerRead), h.ListTasks)
+		tasksGroup.GET("/:id", middleware.RequirePermission(rbac.PermSchedulerRead), h.GetTask)
+		tasksGroup.GET("/:id/runs", middleware.RequirePermission(rbac.PermSchedulerRead), h.ListTaskRuns)
+		tasksGroup.POST("/:id/run", middleware.RequirePermission(rbac.PermSchedulerWrite), h.RunTask)
+		tasksGroup.POST("/:id/pause", middleware.RequirePermission(rbac.PermSchedulerWrite), h.PauseTask)
+		tasksGroup.POST("/:id/resume", middleware.RequirePermission(rbac.PermSchedulerWrite), h.ResumeTask)
+		tasksGroup.PUT("/:id/schedule", middleware.RequirePermission(rbac.PermSchedulerWrite), h.UpdateSchedule)
+		tasksGroup.PUT("/:id/policy", middleware.RequirePermission(rbac.PermSchedulerWrite), h.UpdatePolicy)
+		tasksGroup.DELETE("/:id/policy", middleware.RequirePermission(rbac.PermSchedulerWrite), h.ResetPolicy)
+		tasksGroup.DELETE("/:id", middleware.RequirePermission(rbac.PermSchedulerWrite), h.DeleteTask)
+	}
+}
@@ -0,0 +1,27 @@
+package request
+
+// UpdateScheduleRequest 修改调度任务执行计划请求参数
+//
+// schedule 支持时间间隔（如 "30s"、"5m"，最小 1s）、纯数字秒数与 6 段 cron 表达式（秒 分 时 日 月 周，如 "0 */5 * * * *"）
+type UpdateScheduleRequest struct {
+	Schedule string `json:"schedule" binding:"required,max=100"`
+}
+
+// ListTaskRunsRequest 调度任务执行历史查询参数
+type ListTaskRunsRequest struct {
+	Page     int `form:"page,default=1" binding:"min=1"`
+	PageSize int `form:"pageSize,default=20" binding:"min=1,max=100"`
+}
+
+// UpdatePolicyRequest 修改调度任务执行策略请求参数
+//
+// 时间使用时间间隔格式（如 "30s"、"5m"），timeout 为 "0" 表示不限制单次执行时间；
+// 第 n 次重试前等待 initialBackoff × multiplier^(n-1)，不超过 maxBackoff，并按 jitter 比例随机增减
+type UpdatePolicyRequest struct {
+	Timeout        string  `json:"timeout" binding:"required"`
+	MaxAttempts    int     `json:"maxAttempts" binding:"required,min=1,max=20"`
+	InitialBackoff string  `json:"initialBackoff"`
+	MaxBackoff     string  `json:"maxBackoff"`
+	Multiplier     float64 `json:"multiplier" binding:"required,min=1"`
+	Jitter         float64 `json:"jitter" binding:"min=0,max=1"`
+}
@@ -0,0 +1,157 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"goWebExample/api/rest/handlers/user/request"
+	"goWebExample/api/rest/response"
+	"goWebExample/internal/pkg/handlers"
+	"goWebExample/internal/pkg/middleware"
+	"goWebExample/internal/pkg/module"
+	"goWebExample/internal/pkg/rbac"
+	"goWebExample/internal/service"
+	"goWebExample/internal/service/user"
+)
+
+func init() {
+	// 注册管理模块，复用 user 模块创建的用户服务
+	module.GetRegistry().Register(module.NewBaseModule(
+		"user-admin",
+		nil,
+		// 处理器创建函数
+		func(logger *zap.Logger) handlers.Handler {
+			return NewUserAdminHandler(logger)
+		},
+	))
+}
+
+// UserAdminHandler 处理用户管理相关的HTTP请求
+type UserAdminHandler struct {
+	logger *zap.Logger
+}
+
+// NewUserAdminHandler 创建一个新的用户管理处理器
+func NewUserAdminHandler(logger *zap.Logger) *UserAdminHandler {
+	return &UserAdminHandler{
+		logger: logger,
+	}
+}
+
+// GetRouteGroup 获取路由组
+func (h *UserAdminHandler) GetRouteGroup() handlers.RouteGroup {
+	return handlers.Admin
+}
+
+// UnlockUser godoc
+// @Summary      解锁用户
+// @Description  解除因连续登录失败导致的账户锁定，并清零失败次数
+// @Tags         admin-users
+// @Accept       json
+// @Produce      json
+// @Param        userId path string true "用户ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/users/{userId}/unlock [post]
+func (h *UserAdminHandler) UnlockUser(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+
+	userId := c.Param("userId")
+	if err := srv.UnlockUser(userId); err != nil {
+		switch {
+		case errors.Is(err, user.ErrInvalidUserID):
+			c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, err.Error()))
+		case errors.Is(err, user.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, response.Fail(http.StatusNotFound, err.Error()))
+		default:
+			h.logger.Error("failed to unlock user", zap.String("userId", userId), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "解锁用户失败"))
+		}
+		return
+	}
+
+	h.logger.Info("管理员解锁用户", zap.String("userId", userId), zap.String("operator", c.GetString("username")))
+	c.JSON(http.StatusOK, response.SuccessWithMessage("用户已解锁", nil))
+}
+
+// SetSuperuser godoc
+// @Summary      授予或撤销超级管理员
+// @Description  修改用户的超级管理员状态，需要 users:admin 权限，不能修改自己的状态
+// @Tags         admin-users
+// @Accept       json
+// @Produce      json
+// @Param        userId path string true "用户ID"
+// @Param        request body request.SetSuperuserRequest true "超级管理员状态"
+// @Success      200  {object}  response.Response{data=user.UserDTO}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /admin/users/{userId}/superuser [put]
+func (h *UserAdminHandler) SetSuperuser(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "请先登录"))
+		return
+	}
+
+	var req request.SetSuperuserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	userId := c.Param("userId")
+	updated, err := srv.SetSuperuser(claims.UserID, userId, *req.IsSuperuser)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrInvalidUserID):
+			c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, err.Error()))
+		case errors.Is(err, user.ErrChangeOwnSuperuser):
+			c.JSON(http.StatusForbidden, response.Fail(http.StatusForbidden, err.Error()))
+		case errors.Is(err, user.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, response.Fail(http.StatusNotFound, err.Error()))
+		default:
+			h.logger.Error("failed to set superuser", zap.String("userId", userId), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "修改超级管理员状态失败"))
+		}
+		return
+	}
+
+	h.logger.Info("管理员修改超级管理员状态",
+		zap.String("userId", userId),
+		zap.Bool("isSuperuser", *req.IsSuperuser),
+		zap.String("operator", claims.Username))
+	c.JSON(http.StatusOK, response.SuccessWithMessage("超级管理员状态已修改", updated))
+}
+
+// RegisterRoutes 注册用户管理路由，管理后台路由组已统一完成登录认证
+func (h *UserAdminHandler) RegisterRoutes(adminGroup *gin.RouterGroup) {
+	usersGroup := adminGroup.Group("/users")
+	{
+		usersGroup.POST("/:userId/unlock", middleware.RequirePermission(rbac.PermUsersUnlock), h.UnlockUser)
+		usersGroup.PUT("/:userId/superuser", middleware.RequirePermission(rbac.PermUsersAdmin), h.SetSuperuser)
+	}
+}
@@ -7,10 +7,44 @@ type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 }
 
-// LoginRequest 登录请求参数
+// VerifyEmailRequest 邮箱验证请求参数，token 可通过查询参数或请求体传递
+type VerifyEmailRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
+// ResendVerificationRequest 重新发送验证邮件请求参数
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// LoginRequest 登录请求参数，session 为 true 时使用会话 Cookie 代替 token（需启用会话模式）
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	Session  bool   `json:"session"`
+}
+
+// TwoFactorLoginRequest 双重认证登录第二步请求参数，code 为验证器应用中的 6 位验证码或恢复码
+type TwoFactorLoginRequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+	Code     string `json:"code" binding:"required,max=32"`
+	Session  bool   `json:"session"`
+}
+
+// TwoFactorCodeRequest 确认启用双重认证请求参数
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// DisableTwoFactorRequest 关闭双重认证请求参数
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required,max=32"`
+}
+
+// RefreshTokenRequest 刷新 token 请求参数
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
 }
 
 // UpdateProfileRequest 更新个人资料请求参数
@@ -20,6 +54,17 @@ type UpdateProfileRequest struct {
 	Email    string `json:"email" binding:"omitempty,email"`
 }
 
+// ForgotPasswordRequest 找回密码请求参数
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest 重置密码请求参数
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6,max=32"`
+}
+
 // UpdatePasswordRequest 更新密码请求参数
 type UpdatePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
@@ -31,13 +76,32 @@ type CreateUserRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=32"`
 	Password string `json:"password" binding:"required,min=6,max=32"`
 	Email    string `json:"email" binding:"required,email"`
-	Role     string `json:"role" binding:"required,oneof=user admin"`
+	Nickname string `json:"nickname" binding:"omitempty,min=2,max=32"`
+	IsActive *bool  `json:"isActive"`
 }
 
-// UpdateUserRequest 更新用户请求参数
+// UpdateUserRequest 更新用户请求参数，未传字段保持不变
 type UpdateUserRequest struct {
-	Nickname string `json:"nickname" binding:"omitempty,min=2,max=32"`
-	Email    string `json:"email" binding:"omitempty,email"`
-	Role     string `json:"role" binding:"omitempty,oneof=user admin"`
-	Status   int    `json:"status" binding:"omitempty,oneof=0 1"`
+	Nickname *string `json:"nickname" binding:"omitempty,min=2,max=32"`
+	Email    *string `json:"email" binding:"omitempty,email"`
+	IsActive *bool   `json:"isActive"`
+	Timezone *string `json:"timezone" binding:"omitempty,max=50"`
+	Locale   *string `json:"locale" binding:"omitempty,max=10"`
+}
+
+// SetSuperuserRequest 授予或撤销超级管理员请求参数
+type SetSuperuserRequest struct {
+	IsSuperuser *bool `json:"isSuperuser" binding:"required"`
+}
+
+// ListUsersRequest 用户列表查询参数
+type ListUsersRequest struct {
+	Page        int    `form:"page,default=1" binding:"min=1"`
+	PageSize    int    `form:"pageSize,default=20" binding:"min=1,max=100"`
+	Keyword     string `form:"keyword" binding:"omitempty,max=64"`
+	IsActive    *bool  `form:"isActive"`
+	IsSuperuser *bool  `form:"isSuperuser"`
+	Deleted     string `form:"deleted" binding:"omitempty,oneof=include only"`
+	Sort        string `form:"sort" binding:"omitempty,oneof=id username email createdAt updatedAt lastLogin"`
+	Order       string `form:"order,default=asc" binding:"omitempty,oneof=asc desc"`
 }
@@ -1,6 +1,8 @@
 package user
 
 import (
+	"context"
+	"errors"
 	"goWebExample/api/rest/handlers/user/request"
 	"goWebExample/internal/pkg/middleware"
 	"net/http"
@@ -12,7 +14,7 @@ import (
 	"goWebExample/internal/infra/di/container"
 	"goWebExample/internal/pkg/handlers"
 	"goWebExample/internal/pkg/module"
-	userRepo "goWebExample/internal/repository/user"
+	"goWebExample/internal/pkg/rbac"
 	"goWebExample/internal/service"
 	"goWebExample/internal/service/user"
 )
@@ -23,18 +25,11 @@ func init() {
 		"user",
 		// 服务创建函数
 		func(logger *zap.Logger, container *container.ServiceContainer) (string, interface{}) {
-			if container != nil && container.DBConnector != nil {
-				userRepository := userRepo.NewUserRepository(container.DBConnector)
-				jwtManager := container.GetJWTManager()
-				if jwtManager == nil {
-					logger.Error("无法初始化用户服务：JWT管理器未初始化")
-					return "", nil
-				}
-				userSvc := user.NewUserService(userRepository, logger, jwtManager)
-				return user.ServiceName, userSvc
+			userSvc := user.NewUserServiceFromContainer(logger, container)
+			if userSvc == nil {
+				return "", nil
 			}
-			logger.Error("无法初始化用户服务：数据库连接器未初始化")
-			return "", nil
+			return user.ServiceName, userSvc
 		},
 		// 处理器创建函数
 		func(logger *zap.Logger) handlers.Handler {
@@ -95,12 +90,16 @@ func (h *UserHandler) GetUserDetail(c *gin.Context) {
 
 // CreateUser godoc
 // @Summary      创建用户
-// @Description  创建新用户
+// @Description  创建新用户，用户名与邮箱需唯一
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  response.Response
+// @Param        request body request.CreateUserRequest true "创建用户请求参数"
+// @Success      200  {object}  response.Response{data=user.UserDTO}
+// @Failure      400  {object}  response.Response
 // @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      409  {object}  response.Response
 // @Failure      500  {object}  response.Response
 // @Security     Bearer
 // @Router       /users [post]
@@ -113,18 +112,41 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, response.SuccessWithMessage("创建用户功能待实现", nil))
+	var req request.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	created, err := srv.CreateUser(user.CreateUserInput{
+		Username: req.Username,
+		Password: req.Password,
+		Email:    req.Email,
+		Nickname: req.Nickname,
+		IsActive: req.IsActive,
+	})
+	if err != nil {
+		h.writeUserError(c, "创建用户失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessWithMessage("创建用户成功", created))
 }
 
 // UpdateUser godoc
 // @Summary      更新用户
-// @Description  更新用户信息
+// @Description  更新用户信息，未传字段保持不变；修改邮箱后需重新验证
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Param        userId path string true "用户ID"
-// @Success      200  {object}  response.Response
+// @Param        request body request.UpdateUserRequest true "更新用户请求参数"
+// @Success      200  {object}  response.Response{data=user.UserDTO}
+// @Failure      400  {object}  response.Response
 // @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
 // @Failure      500  {object}  response.Response
 // @Security     Bearer
 // @Router       /users/{userId} [put]
@@ -137,21 +159,39 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	userId := c.Param("userId")
-	c.JSON(http.StatusOK, response.SuccessWithMessage("更新用户功能待实现", gin.H{
-		"userId": userId,
-	}))
+	var req request.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	updated, err := srv.UpdateUser(c.Param("userId"), user.UpdateUserInput{
+		Nickname: req.Nickname,
+		Email:    req.Email,
+		IsActive: req.IsActive,
+		Timezone: req.Timezone,
+		Locale:   req.Locale,
+	})
+	if err != nil {
+		h.writeUserError(c, "更新用户失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessWithMessage("更新用户成功", updated))
 }
 
 // DeleteUser godoc
 // @Summary      删除用户
-// @Description  删除指定用户
+// @Description  软删除指定用户，可通过恢复接口撤销
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Param        userId path string true "用户ID"
 // @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
 // @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
 // @Failure      500  {object}  response.Response
 // @Security     Bearer
 // @Router       /users/{userId} [delete]
@@ -165,19 +205,67 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	}
 
 	userId := c.Param("userId")
-	c.JSON(http.StatusOK, response.SuccessWithMessage("删除用户功能待实现", gin.H{
+	if err := srv.DeleteUser(userId); err != nil {
+		h.writeUserError(c, "删除用户失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessWithMessage("删除用户成功", gin.H{
 		"userId": userId,
 	}))
 }
 
+// RestoreUser godoc
+// @Summary      恢复用户
+// @Description  恢复已软删除的用户
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        userId path string true "用户ID"
+// @Success      200  {object}  response.Response{data=user.UserDTO}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /users/{userId}/restore [post]
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+
+	restored, err := srv.RestoreUser(c.Param("userId"))
+	if err != nil {
+		h.writeUserError(c, "恢复用户失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessWithMessage("恢复用户成功", restored))
+}
+
 // ListUsers godoc
 // @Summary      获取用户列表
-// @Description  获取所有用户列表
+// @Description  分页获取用户列表，支持关键字、状态筛选与排序
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  response.Response
+// @Param        page        query int    false "页码" default(1)
+// @Param        pageSize    query int    false "每页数量" default(20)
+// @Param        keyword     query string false "按用户名、邮箱、昵称模糊搜索"
+// @Param        isActive    query bool   false "是否激活"
+// @Param        isSuperuser query bool   false "是否超级管理员"
+// @Param        deleted     query string false "已删除用户：include 包含，only 仅已删除" Enums(include, only)
+// @Param        sort        query string false "排序字段" Enums(id, username, email, createdAt, updatedAt, lastLogin)
+// @Param        order       query string false "排序方向" Enums(asc, desc)
+// @Success      200  {object}  response.ResponseWithPagination{data=[]user.UserDTO}
+// @Failure      400  {object}  response.Response
 // @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
 // @Failure      500  {object}  response.Response
 // @Security     Bearer
 // @Router       /users [get]
@@ -190,18 +278,409 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, response.SuccessWithMessage("获取用户列表功能待实现", nil))
+	var req request.ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	query := user.ListUsersInput{
+		Page:        req.Page,
+		PageSize:    req.PageSize,
+		Keyword:     req.Keyword,
+		IsActive:    req.IsActive,
+		IsSuperuser: req.IsSuperuser,
+		Deleted:     req.Deleted,
+		SortBy:      req.Sort,
+		SortDesc:    req.Order == "desc",
+	}
+	users, total, err := srv.ListUsers(query)
+	if err != nil {
+		h.writeUserError(c, "获取用户列表失败", err)
+		return
+	}
+
+	response.WithPagination(c, users, req.Page, req.PageSize, total)
+}
+
+// RegisterHandler godoc
+// @Summary      用户注册
+// @Description  自助注册新用户，注册成功后向邮箱发送验证链接
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body request.RegisterRequest true "注册请求参数"
+// @Success      200  {object}  response.Response{data=user.UserDTO}
+// @Failure      400  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/register [post]
+func (h *UserHandler) RegisterHandler(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+
+	var req request.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	registered, err := srv.Register(c.Request.Context(), user.RegisterInput{
+		Username: req.Username,
+		Password: req.Password,
+		Email:    req.Email,
+		IP:       c.ClientIP(),
+	})
+	if err != nil {
+		h.writeUserError(c, "注册失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessWithMessage("注册成功，请查收验证邮件", registered))
+}
+
+// VerifyEmailHandler godoc
+// @Summary      验证邮箱
+// @Description  使用邮件中的 token 完成邮箱验证，token 只能使用一次
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        token query string false "验证token（GET 请求）"
+// @Param        request body request.VerifyEmailRequest false "验证请求参数（POST 请求）"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/verify-email [get]
+// @Router       /users/verify-email [post]
+func (h *UserHandler) VerifyEmailHandler(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+
+	var req request.VerifyEmailRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	if err := srv.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		h.writeUserError(c, "邮箱验证失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, response.SuccessWithMessage("邮箱验证成功", nil))
+}
+
+// ResendVerificationHandler godoc
+// @Summary      重新发送验证邮件
+// @Description  向未验证的邮箱重新发送验证链接，邮箱不存在时同样返回成功
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body request.ResendVerificationRequest true "重新发送请求参数"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/verify-email/resend [post]
+func (h *UserHandler) ResendVerificationHandler(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+
+	var req request.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	if err := srv.ResendVerificationEmail(c.Request.Context(), req.Email); err != nil {
+		h.writeUserError(c, "发送验证邮件失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, response.SuccessWithMessage("如果该邮箱已注册且未验证，验证邮件将很快送达", nil))
+}
+
+// ForgotPasswordHandler godoc
+// @Summary      找回密码
+// @Description  向邮箱发送限时有效的密码重置链接，邮箱不存在时同样返回成功
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body request.ForgotPasswordRequest true "找回密码请求参数"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/forgot-password [post]
+func (h *UserHandler) ForgotPasswordHandler(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+
+	var req request.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	if err := srv.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		h.writeUserError(c, "发送重置邮件失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, response.SuccessWithMessage("如果该邮箱已注册，重置邮件将很快送达", nil))
+}
+
+// ResetPasswordHandler godoc
+// @Summary      重置密码
+// @Description  使用邮件中的 token 设置新密码，成功后该用户所有已登录会话失效
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body request.ResetPasswordRequest true "重置密码请求参数"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/reset-password [post]
+func (h *UserHandler) ResetPasswordHandler(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+
+	var req request.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	if err := srv.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		h.writeUserError(c, "重置密码失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, response.SuccessWithMessage("密码已重置，请使用新密码登录", nil))
+}
+
+// ChangePasswordHandler godoc
+// @Summary      修改密码
+// @Description  验证原密码后设置新密码，此前签发的所有 token 失效，响应中返回新的 token
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body request.UpdatePasswordRequest true "修改密码请求参数"
+// @Success      200  {object}  response.Response{data=user.AuthResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /users/change-password [post]
+func (h *UserHandler) ChangePasswordHandler(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "请先登录"))
+		return
+	}
+
+	var req request.UpdatePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	reqCtx := c.Request.Context()
+	if middleware.GetAuthMethod(c) == middleware.AuthMethodSession {
+		reqCtx = user.WithSession(reqCtx, c.ClientIP(), c.Request.UserAgent())
+	}
+	resp, err := srv.ChangePassword(reqCtx, claims.UserID, req.OldPassword, req.NewPassword)
+	if err != nil {
+		h.writeUserError(c, "修改密码失败", err)
+		return
+	}
+	h.writeSessionCookie(c, srv, resp)
+	c.JSON(http.StatusOK, response.SuccessWithMessage("密码已修改", resp))
+}
+
+// EnrollTwoFactorHandler godoc
+// @Summary      获取双重认证密钥
+// @Description  生成新的 TOTP 密钥与 otpauth 链接，需调用确认接口校验验证码后才会启用
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  response.Response{data=user.TwoFactorEnrollment}
+// @Failure      401  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Failure      503  {object}  response.Response
+// @Security     Bearer
+// @Router       /users/2fa/enroll [post]
+func (h *UserHandler) EnrollTwoFactorHandler(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "请先登录"))
+		return
+	}
+
+	enrollment, err := srv.EnrollTwoFactor(claims.UserID)
+	if err != nil {
+		h.writeUserError(c, "获取双重认证密钥失败", err)
+		return
+	}
+	response.SuccessWithData(c, enrollment)
+}
+
+// ConfirmTwoFactorHandler godoc
+// @Summary      启用双重认证
+// @Description  校验验证器应用中的验证码后启用双重认证，返回的恢复码只显示这一次
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body request.TwoFactorCodeRequest true "验证码"
+// @Success      200  {object}  response.Response{data=[]string}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /users/2fa/confirm [post]
+func (h *UserHandler) ConfirmTwoFactorHandler(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "请先登录"))
+		return
+	}
+
+	var req request.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	codes, err := srv.ConfirmTwoFactor(c.Request.Context(), claims.UserID, req.Code)
+	if err != nil {
+		h.writeUserError(c, "启用双重认证失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, response.SuccessWithMessage("已启用双重认证，请妥善保存恢复码", codes))
+}
+
+// DisableTwoFactorHandler godoc
+// @Summary      关闭双重认证
+// @Description  校验密码与验证码（或恢复码）后关闭双重认证
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body request.DisableTwoFactorRequest true "关闭双重认证请求参数"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /users/2fa/disable [post]
+func (h *UserHandler) DisableTwoFactorHandler(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "请先登录"))
+		return
+	}
+
+	var req request.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	if err := srv.DisableTwoFactor(c.Request.Context(), claims.UserID, req.Password, req.Code); err != nil {
+		h.writeUserError(c, "关闭双重认证失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, response.SuccessWithMessage("已关闭双重认证", nil))
+}
+
+// writeUserError 将用户管理、注册相关错误映射为 HTTP 状态码并写入响应
+func (h *UserHandler) writeUserError(c *gin.Context, action string, err error) {
+	var status int
+	switch {
+	case errors.Is(err, user.ErrInvalidUserID),
+		errors.Is(err, user.ErrInvalidVerificationToken),
+		errors.Is(err, user.ErrVerificationTokenUsed),
+		errors.Is(err, user.ErrInvalidResetToken),
+		errors.Is(err, user.ErrResetTokenUsed),
+		errors.Is(err, user.ErrIncorrectPassword),
+		errors.Is(err, user.ErrTwoFactorNotEnrolled),
+		errors.Is(err, user.ErrTwoFactorNotEnabled),
+		errors.Is(err, user.ErrInvalidTwoFactorCode):
+		status = http.StatusBadRequest
+	case errors.Is(err, user.ErrTwoFactorUnavailable):
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, user.ErrRegistrationDisabled):
+		status = http.StatusForbidden
+	case errors.Is(err, user.ErrUserNotFound),
+		errors.Is(err, user.ErrSessionNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, user.ErrUsernameExists),
+		errors.Is(err, user.ErrEmailExists),
+		errors.Is(err, user.ErrUserConflict),
+		errors.Is(err, user.ErrTwoFactorAlreadyEnabled):
+		status = http.StatusConflict
+	default:
+		h.logger.Error(action, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, action))
+		return
+	}
+	c.JSON(status, response.Fail(status, err.Error()))
 }
 
 // LoginHandler godoc
 // @Summary      用户登录
-// @Description  用户登录并返回JWT token
+// @Description  用户登录并返回JWT token；启用双重认证时返回 mfaRequired 与 mfaToken，需调用 /users/login/2fa 完成登录
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Param        request body request.LoginRequest true "登录请求参数"
 // @Success      200  {object}  response.Response{data=user.AuthResponse}
 // @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      423  {object}  response.Response
 // @Failure      500  {object}  response.Response
 // @Router       /users/login [post]
 func (h *UserHandler) LoginHandler(ctx *gin.Context) {
@@ -219,14 +698,282 @@ func (h *UserHandler) LoginHandler(ctx *gin.Context) {
 	}
 	// 获取客户端IP
 	clientIP := ctx.ClientIP()
-	users, err := srv.Login(req.Username, req.Password, clientIP)
+	reqCtx, ok := h.loginContext(ctx, srv, req.Session)
+	if !ok {
+		return
+	}
+	users, err := srv.Login(reqCtx, req.Username, req.Password, clientIP)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, err.Error()))
+		status := loginErrorStatus(err)
+		ctx.JSON(status, response.Fail(status, err.Error()))
 		return
 	}
+	h.writeSessionCookie(ctx, srv, users)
 	response.SuccessWithData(ctx, users)
 }
 
+// TwoFactorLoginHandler godoc
+// @Summary      双重认证登录
+// @Description  登录第二步：提交 mfaToken 与验证码（或恢复码），验证通过后返回JWT token
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body request.TwoFactorLoginRequest true "双重认证登录请求参数"
+// @Success      200  {object}  response.Response{data=user.AuthResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      423  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/login/2fa [post]
+func (h *UserHandler) TwoFactorLoginHandler(ctx *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		ctx.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+	var req request.TwoFactorLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	reqCtx, ok := h.loginContext(ctx, srv, req.Session)
+	if !ok {
+		return
+	}
+	resp, err := srv.VerifyTwoFactorLogin(reqCtx, req.MFAToken, req.Code, ctx.ClientIP())
+	if err != nil {
+		status := loginErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			h.logger.Error("双重认证登录失败", zap.Error(err))
+			ctx.JSON(status, response.Fail(status, "登录失败"))
+			return
+		}
+		ctx.JSON(status, response.Fail(status, err.Error()))
+		return
+	}
+	h.writeSessionCookie(ctx, srv, resp)
+	response.SuccessWithData(ctx, resp)
+}
+
+// RefreshTokenHandler godoc
+// @Summary      刷新token
+// @Description  使用 refresh token 换取新的 access token 与 refresh token，旧 refresh token 随即失效
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body request.RefreshTokenRequest true "刷新token请求参数"
+// @Success      200  {object}  response.Response{data=user.AuthResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/token/refresh [post]
+func (h *UserHandler) RefreshTokenHandler(ctx *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		ctx.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+	var req request.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, "参数错误"))
+		return
+	}
+
+	resp, err := srv.RefreshToken(ctx.Request.Context(), req.RefreshToken)
+	if err != nil {
+		status := loginErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			h.logger.Error("刷新token失败", zap.Error(err))
+			ctx.JSON(status, response.Fail(status, "刷新token失败"))
+			return
+		}
+		ctx.JSON(status, response.Fail(status, err.Error()))
+		return
+	}
+	response.SuccessWithData(ctx, resp)
+}
+
+// LogoutHandler godoc
+// @Summary      退出登录
+// @Description  吊销当前 access token 及同一次登录签发的所有 refresh token；使用会话登录时删除会话并清除 Cookie
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /users/logout [post]
+func (h *UserHandler) LogoutHandler(ctx *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		ctx.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+
+	claims, ok := middleware.GetClaims(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "请先登录"))
+		return
+	}
+
+	if err := srv.Logout(ctx.Request.Context(), claims); err != nil {
+		h.logger.Error("退出登录失败", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "退出登录失败"))
+		return
+	}
+	if srv.SessionEnabled() {
+		middleware.ClearSessionCookie(ctx, srv.SessionConfig())
+	}
+	ctx.JSON(http.StatusOK, response.SuccessWithMessage("已退出登录", nil))
+}
+
+// ListSessionsHandler godoc
+// @Summary      获取登录会话列表
+// @Description  列出当前用户通过会话 Cookie 登录的全部有效会话，current 标记当前会话
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  response.Response{data=[]user.SessionDTO}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /users/sessions [get]
+func (h *UserHandler) ListSessionsHandler(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "请先登录"))
+		return
+	}
+
+	sessions, err := srv.ListSessions(c.Request.Context(), claims)
+	if err != nil {
+		h.writeUserError(c, "获取会话列表失败", err)
+		return
+	}
+	response.SuccessWithData(c, sessions)
+}
+
+// RevokeSessionHandler godoc
+// @Summary      吊销登录会话
+// @Description  吊销当前用户的指定会话，对应设备需要重新登录
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        sessionId path string true "会话ID"
+// @Success      200  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /users/sessions/{sessionId} [delete]
+func (h *UserHandler) RevokeSessionHandler(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "请先登录"))
+		return
+	}
+
+	sessionID := c.Param("sessionId")
+	if err := srv.RevokeSession(c.Request.Context(), claims, sessionID); err != nil {
+		h.writeUserError(c, "吊销会话失败", err)
+		return
+	}
+	if sessionID == claims.SessionID && middleware.GetAuthMethod(c) == middleware.AuthMethodSession {
+		middleware.ClearSessionCookie(c, srv.SessionConfig())
+	}
+	c.JSON(http.StatusOK, response.SuccessWithMessage("会话已吊销", nil))
+}
+
+// LogoutOtherDevicesHandler godoc
+// @Summary      退出其他设备
+// @Description  使当前设备以外的所有会话与 token 失效；使用 Bearer token 时响应中返回当前设备的新 token
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  response.Response{data=user.AuthResponse}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     Bearer
+// @Router       /users/sessions/logout-others [post]
+func (h *UserHandler) LogoutOtherDevicesHandler(c *gin.Context) {
+	srv, ok := service.GetRegistry().Get(user.ServiceName).(*user.UserService)
+	if !ok || srv == nil {
+		h.logger.Error("user service not initialized")
+		c.JSON(http.StatusInternalServerError, response.Fail(http.StatusInternalServerError, "用户服务未初始化"))
+		return
+	}
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "请先登录"))
+		return
+	}
+
+	viaSession := middleware.GetAuthMethod(c) == middleware.AuthMethodSession
+	resp, err := srv.LogoutOtherDevices(c.Request.Context(), claims, viaSession)
+	if err != nil {
+		h.writeUserError(c, "退出其他设备失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, response.SuccessWithMessage("已退出其他设备", resp))
+}
+
+// loginContext 构造登录请求上下文，要求会话模式但未启用时写入错误响应
+func (h *UserHandler) loginContext(c *gin.Context, srv *user.UserService, session bool) (context.Context, bool) {
+	if !session {
+		return c.Request.Context(), true
+	}
+	if !srv.SessionEnabled() {
+		c.JSON(http.StatusBadRequest, response.Fail(http.StatusBadRequest, user.ErrSessionUnavailable.Error()))
+		return nil, false
+	}
+	return user.WithSession(c.Request.Context(), c.ClientIP(), c.Request.UserAgent()), true
+}
+
+// writeSessionCookie 会话模式登录成功时写入会话 Cookie
+func (h *UserHandler) writeSessionCookie(c *gin.Context, srv *user.UserService, resp *user.AuthResponse) {
+	if resp == nil || resp.SessionToken == "" {
+		return
+	}
+	middleware.SetSessionCookie(c, srv.SessionConfig(), resp.SessionToken, int(resp.SessionExpiresIn))
+}
+
+// loginErrorStatus 将登录错误映射为 HTTP 状态码
+func loginErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, user.ErrInvalidCredentials),
+		errors.Is(err, user.ErrInvalidTwoFactorCode),
+		errors.Is(err, user.ErrInvalidMFAToken),
+		errors.Is(err, user.ErrInvalidRefreshToken),
+		errors.Is(err, user.ErrRefreshTokenReused):
+		return http.StatusUnauthorized
+	case errors.Is(err, user.ErrAccountLocked):
+		return http.StatusLocked
+	case errors.Is(err, user.ErrAccountDisabled),
+		errors.Is(err, user.ErrEmailNotVerified):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // RegisterRoutes 注册用户相关路由
 func (h *UserHandler) RegisterRoutes(apiGroup *gin.RouterGroup) {
 	if h == nil {
@@ -244,15 +991,32 @@ func (h *UserHandler) RegisterRoutes(apiGroup *gin.RouterGroup) {
 	{
 		// 公开路由
 		userGroup.POST("/login", h.LoginHandler)
+		userGroup.POST("/login/2fa", h.TwoFactorLoginHandler)
+		userGroup.POST("/token/refresh", h.RefreshTokenHandler)
+		userGroup.POST("/register", h.RegisterHandler)
+		userGroup.GET("/verify-email", h.VerifyEmailHandler)
+		userGroup.POST("/verify-email", h.VerifyEmailHandler)
+		userGroup.POST("/verify-email/resend", h.ResendVerificationHandler)
+		userGroup.POST("/forgot-password", h.ForgotPasswordHandler)
+		userGroup.POST("/reset-password", h.ResetPasswordHandler)
 
 		// 需要认证的路由
 		auth := userGroup.Use(middleware.JWTAuthMiddleware(srv.GetJWTManager(), h.logger))
 		{
+			auth.POST("/logout", h.LogoutHandler)
+			auth.POST("/change-password", h.ChangePasswordHandler)
+			auth.POST("/2fa/enroll", h.EnrollTwoFactorHandler)
+			auth.POST("/2fa/confirm", h.ConfirmTwoFactorHandler)
+			auth.POST("/2fa/disable", h.DisableTwoFactorHandler)
+			auth.GET("/sessions", h.ListSessionsHandler)
+			auth.DELETE("/sessions/:sessionId", h.RevokeSessionHandler)
+			auth.POST("/sessions/logout-others", h.LogoutOtherDevicesHandler)
 			auth.GET("/profile/:userId", h.GetUserDetail)
-			auth.POST("", h.CreateUser)
-			//auth.PUT("/:userId", h.UpdateUser)
-			//auth.DELETE("/:userId", h.DeleteUser)
-			auth.GET("", h.ListUsers)
+			auth.POST("", middleware.RequirePermission(rbac.PermUsersWrite), h.CreateUser)
+			auth.PUT("/:userId", middleware.RequirePermission(rbac.PermUsersWrite), h.UpdateUser)
+			auth.DELETE("/:userId", middleware.RequirePermission(rbac.PermUsersWrite), h.DeleteUser)
+			auth.POST("/:userId/restore", middleware.RequirePermission(rbac.PermUsersWrite), h.RestoreUser)
+			auth.GET("", middleware.RequirePermission(rbac.PermUsersRead), h.ListUsers)
 		}
 	}
 }
@@ -17,6 +17,16 @@ const (
 	CodeServerError      = 500  // 服务器内部错误
 	CodeValidationError  = 1001 // 数据验证错误
 	CodeDBError          = 1002 // 数据库错误
+
+	// OpenAPI 认证错误码
+	CodeAPIKeyInvalid    = 2001 // API密钥不存在、签名错误或缺少签名参数
+	CodeAPIKeyDisabled   = 2002 // API密钥已禁用
+	CodeAPIKeyExpired    = 2003 // API密钥已过期
+	CodeTimestampExpired = 2004 // 签名时间戳超出允许的偏差
+	CodeAPIKeyForbidden  = 2005 // API密钥无权访问该接口
+	CodeRequestReplayed  = 2006 // 签名请求的 nonce 已被使用
+	CodeRateLimited      = 2007 // 超出API密钥每分钟请求数上限
+	CodeQuotaExceeded    = 2008 // 超出API密钥每日或每月配额
 )
 
 // Response 通用API响应结构
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"goWebExample/internal/configs"
+	"goWebExample/internal/infra/db/mysql"
+	"goWebExample/internal/pkg/encryption"
+	"goWebExample/internal/repository/apikey"
+	"goWebExample/internal/repository/user"
+	"goWebExample/pkg/envelope"
+	zaplog "goWebExample/pkg/zap"
+)
+
+// 定义命令行参数
+var (
+	configPath = flag.String("conf", "configs/config.dev.yaml", "配置文件路径")
+	dryRun     = flag.Bool("dry-run", false, "只统计需要重新加密的记录，不写入数据库")
+)
+
+// errNoLegacyKey 未配置旧的双重认证加密密钥，无法解密旧格式密文
+var errNoLegacyKey = errors.New("未配置twoFactor.encryptionKey，无法解密旧格式密文")
+
+// stats 重新加密统计
+type stats struct {
+	encrypted int // 明文或旧格式密文加密为信封密文
+	rewrapped int // 信封密文换用主密钥重新包装
+	skipped   int // 无法处理或重新加密期间已被修改而跳过
+}
+
+// add 累加一条记录的统计
+func (s *stats) add(row stats) {
+	s.encrypted += row.encrypted
+	s.rewrapped += row.rewrapped
+	s.skipped += row.skipped
+}
+
+// main 将敏感字段迁移到当前主密钥：
+//   - 明文 API 秘钥加密存储；
+//   - 旧的单密钥双重认证密文（twoFactor.encryptionKey）改为信封加密；
+//   - 由非首个主密钥包装的密文换用首个主密钥重新包装。
+//
+// 轮换主密钥时先把新密钥放在 masterKeys 第一位并部署，执行本命令后再移除旧密钥。
+func main() {
+	// 解析命令行参数
+	flag.Parse()
+
+	// 初始化日志
+	logger := initLogger()
+	defer logger.Sync()
+
+	// 加载配置
+	logger.Info("加载配置文件", zap.String("path", *configPath))
+	config := configs.ReadConfig(*configPath)
+
+	keyring, err := encryption.NewKeyring(config.Encryption)
+	if err != nil {
+		logger.Fatal("加载加密主密钥失败", zap.Error(err))
+	}
+	if keyring == nil {
+		logger.Fatal("未配置加密主密钥（encryption.masterKeys 或 encryption.keyFile）")
+	}
+	var legacy *envelope.LegacyKey
+	if key := config.User.TwoFactor.EncryptionKey; key != "" {
+		if legacy, err = envelope.ParseLegacyKey(key); err != nil {
+			logger.Fatal("双重认证加密密钥无效", zap.Error(err))
+		}
+	}
+
+	// 连接数据库
+	db := connectDatabase(logger, &config.Database)
+	if db == nil {
+		logger.Fatal("连接数据库失败")
+		return
+	}
+	defer func() {
+		if err := db.Disconnect(context.Background()); err != nil {
+			logger.Error("关闭数据库连接失败", zap.Error(err))
+		}
+	}()
+
+	logger.Info("开始重新加密", zap.String("primaryKeyId", keyring.PrimaryKeyID()), zap.Bool("dryRun", *dryRun))
+
+	apiKeyStats, err := rekeyAPIKeys(logger, db.GetDB(), keyring)
+	if err != nil {
+		logger.Fatal("重新加密API秘钥失败", zap.Error(err))
+	}
+	logger.Info("API秘钥处理完成",
+		zap.Int("encrypted", apiKeyStats.encrypted),
+		zap.Int("rewrapped", apiKeyStats.rewrapped),
+		zap.Int("skipped", apiKeyStats.skipped))
+
+	userStats, err := rekeyTwoFactorSecrets(logger, db.GetDB(), keyring, legacy)
+	if err != nil {
+		logger.Fatal("重新加密双重认证秘钥失败", zap.Error(err))
+	}
+	logger.Info("双重认证秘钥处理完成",
+		zap.Int("encrypted", userStats.encrypted),
+		zap.Int("rewrapped", userStats.rewrapped),
+		zap.Int("skipped", userStats.skipped))
+
+	if userStats.skipped > 0 || apiKeyStats.skipped > 0 {
+		logger.Warn("部分记录未能重新加密，移除旧主密钥前请先处理")
+	}
+}
+
+// rekeyAPIKeys 重新加密 api_keys 中的秘钥，包含已软删除的记录
+func rekeyAPIKeys(logger *zap.Logger, db *gorm.DB, keyring *envelope.Keyring) (stats, error) {
+	var result stats
+	var keys []apikey.APIKey
+	if err := db.Unscoped().Select("id", "api_key", "api_secret", "previous_secret").Find(&keys).Error; err != nil {
+		return result, fmt.Errorf("查询API密钥失败: %w", err)
+	}
+
+	// 加密前的秘钥以明文保存
+	plaintext := func(stored string, _ []byte) ([]byte, error) {
+		return []byte(stored), nil
+	}
+
+	for _, key := range keys {
+		aad := apikey.SecretAAD(key.APIKey)
+		updates := map[string]interface{}{}
+		var row stats
+		for column, stored := range map[string]string{"api_secret": key.APISecret, "previous_secret": key.PreviousSecret} {
+			value, changed, err := rekeyValue(keyring, stored, aad, plaintext, &row)
+			if err != nil {
+				logger.Warn("API秘钥无法重新加密，跳过", zap.Uint64("id", key.ID), zap.String("column", column), zap.Error(err))
+				continue
+			}
+			if changed {
+				updates[column] = value
+			}
+		}
+		if len(updates) == 0 || *dryRun {
+			result.add(row)
+			continue
+		}
+
+		// 条件更新：读取之后秘钥被轮换或修改时不覆盖新值，计为跳过
+		tx := db.Model(&apikey.APIKey{}).Unscoped().
+			Where("id = ? AND api_secret = ? AND previous_secret = ?", key.ID, key.APISecret, key.PreviousSecret).
+			UpdateColumns(updates)
+		if tx.Error != nil {
+			return result, fmt.Errorf("更新API密钥 %d 失败: %w", key.ID, tx.Error)
+		}
+		if tx.RowsAffected == 0 {
+			logger.Warn("API秘钥在重新加密期间已被修改，跳过", zap.Uint64("id", key.ID))
+			result.skipped += row.skipped + 1
+			continue
+		}
+		result.add(row)
+	}
+	return result, nil
+}
+
+// rekeyTwoFactorSecrets 重新加密 t_users 中的双重认证秘钥，旧格式密文需要配置 twoFactor.encryptionKey 才能迁移
+func rekeyTwoFactorSecrets(logger *zap.Logger, db *gorm.DB, keyring *envelope.Keyring, legacy *envelope.LegacyKey) (stats, error) {
+	var result stats
+	var users []user.Users
+	if err := db.Unscoped().Select("id", "two_factor_secret").
+		Where("two_factor_secret IS NOT NULL AND two_factor_secret <> ''").Find(&users).Error; err != nil {
+		return result, fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	decryptLegacy := func(stored string, aad []byte) ([]byte, error) {
+		if legacy == nil {
+			return nil, errNoLegacyKey
+		}
+		return legacy.Decrypt(stored, aad)
+	}
+
+	for _, u := range users {
+		var row stats
+		value, changed, err := rekeyValue(keyring, *u.TwoFactorSecret, user.TwoFactorSecretAAD(u.ID), decryptLegacy, &row)
+		if err != nil {
+			logger.Warn("双重认证秘钥无法重新加密，跳过", zap.Uint64("userId", u.ID), zap.Error(err))
+			result.add(row)
+			continue
+		}
+		if !changed || *dryRun {
+			result.add(row)
+			continue
+		}
+
+		// 条件更新：读取之后用户重新注册或关闭了双重认证时不覆盖新值，计为跳过
+		tx := db.Model(&user.Users{}).Unscoped().
+			Where("id = ? AND two_factor_secret = ?", u.ID, *u.TwoFactorSecret).
+			UpdateColumn("two_factor_secret", value)
+		if tx.Error != nil {
+			return result, fmt.Errorf("更新用户 %d 失败: %w", u.ID, tx.Error)
+		}
+		if tx.RowsAffected == 0 {
+			logger.Warn("双重认证秘钥在重新加密期间已被修改，跳过", zap.Uint64("userId", u.ID))
+			result.skipped++
+			continue
+		}
+		result.add(row)
+	}
+	return result, nil
+}
+
+// rekeyValue 将单个字段迁移到首个主密钥，decode 用于读取非信封格式的旧值；返回新值及是否需要更新
+func rekeyValue(keyring *envelope.Keyring, stored string, aad []byte,
+	decode func(stored string, aad []byte) ([]byte, error), result *stats) (string, bool, error) {
+	if stored == "" {
+		return stored, false, nil
+	}
+
+	if envelope.IsEncrypted(stored) {
+		if !keyring.NeedsRewrap(stored) {
+			return stored, false, nil
+		}
+		value, err := keyring.Rewrap(stored)
+		if err != nil {
+			result.skipped++
+			return "", false, err
+		}
+		result.rewrapped++
+		return value, true, nil
+	}
+
+	plaintext, err := decode(stored, aad)
+	if err != nil {
+		result.skipped++
+		return "", false, err
+	}
+	value, err := keyring.Encrypt(plaintext, aad)
+	if err != nil {
+		result.skipped++
+		return "", false, err
+	}
+	result.encrypted++
+	return value, true, nil
+}
+
+// initLogger 初始化日志
+func initLogger() *zap.Logger {
+	config := &configs.AllConfig{
+		Log: configs.Log{
+			Level:         "info",
+			EnableConsole: true,
+			EnableFile:    false,
+			Prefix:        "rekey",
+		},
+	}
+	return zaplog.NewZap(config)
+}
+
+// connectDatabase 连接数据库
+func connectDatabase(logger *zap.Logger, dbConfig *configs.Database) *mysql.DBConnector {
+	logger.Info("连接数据库",
+		zap.String("host", dbConfig.Host),
+		zap.Int("port", dbConfig.Port),
+		zap.String("database", dbConfig.DBName))
+
+	db := mysql.NewDBConnector(dbConfig, logger)
+	if db == nil {
+		logger.Error("创建数据库连接器失败")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.Connect(ctx); err != nil {
+		logger.Error("连接数据库失败", zap.Error(err))
+		return nil
+	}
+	if ok, err := db.HealthCheck(ctx); !ok {
+		logger.Error("数据库健康检查失败", zap.Error(err))
+		return nil
+	}
+
+	logger.Info("数据库连接成功")
+	return db
+}
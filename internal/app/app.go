@@ -13,9 +13,11 @@ import (
 	"goWebExample/internal/pkg/handlers"
 	"goWebExample/internal/pkg/middleware"
 	"goWebExample/internal/pkg/module"
+	"goWebExample/internal/pkg/rbac"
 	"goWebExample/internal/pkg/server"
 	"goWebExample/internal/pkg/tracer"
 	"goWebExample/internal/service"
+	"goWebExample/internal/service/scheduler"
 
 	"go.opentelemetry.io/otel/sdk/trace"
 )
@@ -65,16 +67,30 @@ func NewApp(
 	// 初始化所有模块
 	module.GetRegistry().InitAll(logger, container)
 
-	// 初始化全局路由组
-	server.InitGroups(engine, logger, container)
+	if err := middleware.ValidateCSRFConfig(config.CSRF); err != nil {
+		log.Fatalf("CSRF配置无效: %v", err)
+	}
 
 	// 加载所有中间件，传入container以便获取Redis连接器
+	// 注意：必须在创建路由组之前加载，gin 的路由组在创建时会复制当前的全局中间件
 	middleware.LoadMiddleware(config, logger, engine, container)
 
-	// 为OpenAPI路由组应用认证中间件
+	// 初始化全局路由组
+	server.InitGroups(engine, logger, container)
+
+	// 管理后台路由组要求登录并拥有 admin:access 权限，具体路由可再通过 RequirePermission 声明细粒度权限
+	server.GlobalGroups.Admin.Use(
+		middleware.JWTAuthMiddleware(container.GetJWTManager(), logger),
+		middleware.RequirePermission(rbac.PermAdminAccess),
+	)
+
+	// 为OpenAPI路由组应用认证中间件，认证通过后按API密钥限流与计算配额
 	if config.OpenAPI.Enable {
 		logger.Info("为OpenAPI路由组应用认证中间件")
-		server.GlobalGroups.OpenAPI.Use(middleware.OpenAPIAuthMiddleware(&config.OpenAPI, logger))
+		server.GlobalGroups.OpenAPI.Use(
+			middleware.OpenAPIAuthMiddleware(&config.OpenAPI, logger),
+			middleware.OpenAPIQuotaMiddleware(logger),
+		)
 	} else {
 		logger.Warn("OpenAPI未启用，跳过认证中间件")
 	}
@@ -134,6 +150,13 @@ func (app *App) Run() error {
 
 // Shutdown 优雅关闭应用程序
 func (app *App) Shutdown(ctx context.Context) error {
+	// 停止调度服务，取消执行中的任务并等待其退出
+	if schedulerSvc, ok := service.GetRegistry().Get(scheduler.ServiceName).(scheduler.SchedulerService); ok && schedulerSvc != nil {
+		if err := schedulerSvc.Stop(); err != nil {
+			app.logger.Error("停止调度服务失败", zap.Error(err))
+		}
+	}
+
 	// 关闭链路追踪
 	cfg := tracer.DefaultShutdownConfig(app.tp, app.logger)
 	if err := tracer.Shutdown(ctx, cfg); err != nil {
@@ -1,10 +1,13 @@
 package configs
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -20,20 +23,27 @@ func init() {
 
 // AllConfig 应用全局配置
 type AllConfig struct {
-	Model       string        `yaml:"model"`
-	Server      Server        `yaml:"server"`
-	Log         Log           `yaml:"log"`
-	Cors        *Cors         `yaml:"cors"`
-	Trace       *Trace        `yaml:"trace"`
-	Database    Database      `yaml:"database"`
-	Redis       Redis         `yaml:"redis"`
-	Kafka       KafkaConfig   `yaml:"kafka"`
-	Etcd        *Etcd         `yaml:"etcd"`
-	MongoDB     *MongoDB      `yaml:"mongodb"`
-	JWT         JWTConfig     `yaml:"jwt"`
-	Swagger     Swagger       `yaml:"swagger"`
-	RateLimiter *RateLimiter  `yaml:"rateLimiter"`
-	OpenAPI     OpenAPIConfig `yaml:"openapi"`
+	Model       string           `yaml:"model"`
+	Server      Server           `yaml:"server"`
+	Log         Log              `yaml:"log"`
+	Cors        *Cors            `yaml:"cors"`
+	CSRF        *CSRFConfig      `yaml:"csrf"`
+	Trace       *Trace           `yaml:"trace"`
+	Database    Database         `yaml:"database"`
+	Redis       Redis            `yaml:"redis"`
+	Kafka       KafkaConfig      `yaml:"kafka"`
+	Etcd        *Etcd            `yaml:"etcd"`
+	MongoDB     *MongoDB         `yaml:"mongodb"`
+	JWT         JWTConfig        `yaml:"jwt"`
+	Swagger     Swagger          `yaml:"swagger"`
+	RateLimiter *RateLimiter     `yaml:"rateLimiter"`
+	OpenAPI     OpenAPIConfig    `yaml:"openapi"`
+	Metrics     *Metrics         `yaml:"metrics"`
+	User        UserConfig       `yaml:"user"`
+	Mail        MailConfig       `yaml:"mail"`
+	OIDC        OIDCConfig       `yaml:"oidc"`
+	Encryption  EncryptionConfig `yaml:"encryption"`
+	Scheduler   SchedulerConfig  `yaml:"scheduler"`
 }
 
 // Trace 链路追踪配置
@@ -79,6 +89,39 @@ type Cors struct {
 	AllowPrivateNetwork bool     `yaml:"allowPrivateNetwork"`
 }
 
+// CSRFConfig CSRF 防护配置，对携带会话 Cookie 的非安全方法请求校验 CSRF token
+type CSRFConfig struct {
+	Enable      bool     `yaml:"enable"`      // 是否启用 CSRF 防护
+	Secret      string   `yaml:"secret"`      // token 签名密钥，至少 32 字节；为空时随机生成，重启或多实例部署时 token 失效
+	CookieName  string   `yaml:"cookieName"`  // 保存 token 的 Cookie 名称（前端可读），默认 csrf_token
+	HeaderName  string   `yaml:"headerName"`  // 提交 token 的请求头，默认 X-CSRF-Token
+	ExemptPaths []string `yaml:"exemptPaths"` // 额外豁免的路径前缀，/openapi/ 始终豁免
+}
+
+// GetCookieName 获取 CSRF Cookie 名称，如果未配置则返回默认值
+func (c *CSRFConfig) GetCookieName() string {
+	if c.CookieName == "" {
+		return "csrf_token"
+	}
+	return c.CookieName
+}
+
+// GetHeaderName 获取 CSRF 请求头名称，如果未配置则返回默认值
+func (c *CSRFConfig) GetHeaderName() string {
+	if c.HeaderName == "" {
+		return "X-CSRF-Token"
+	}
+	return c.HeaderName
+}
+
+// EncryptionConfig 敏感字段（API 秘钥、双重认证密钥等）加密配置，使用信封加密，未配置主密钥时不加密
+//
+// 轮换主密钥时把新密钥放在第一位、保留旧密钥，执行 cmd/rekey 将已有密文迁移到新密钥后再移除旧密钥。
+type EncryptionConfig struct {
+	MasterKeys []string `yaml:"masterKeys"` // AES-256 主密钥（base64），第一个用于加密，其余只用于解密
+	KeyFile    string   `yaml:"keyFile"`    // 主密钥文件，每行一个 base64 密钥，顺序含义同 masterKeys；配置后忽略 masterKeys
+}
+
 // Database 数据库配置
 type Database struct {
 	SSLMode         string `yaml:"ssl_mode"`
@@ -162,19 +205,32 @@ func (config *AllConfig) IsDev() bool {
 	return !strings.Contains(ConfigPath, "prod")
 }
 
-// ReadConfig 读取配置文件
-func ReadConfig(configPath string) *AllConfig {
-	viper.SetConfigFile(configPath)
+// envPattern 配置文件中的环境变量引用，只支持 ${NAME} 形式，避免误替换值中的 $ 字符
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
 
-	if err := viper.ReadInConfig(); err != nil {
+// expandEnv 将配置内容中的 ${NAME} 替换为环境变量的值，未设置的环境变量替换为空字符串
+func expandEnv(content []byte) []byte {
+	return envPattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		return []byte(os.Getenv(string(envPattern.FindSubmatch(match)[1])))
+	})
+}
+
+// ReadConfig 读取配置文件，配置中的 ${NAME} 会替换为同名环境变量的值
+func ReadConfig(configPath string) *AllConfig {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
 		log.Printf("警告: 无法读取配置文件: %s", err)
 
-		// 尝试创建默认配置文件
 		if os.IsNotExist(err) {
 			log.Fatalf("配置文件不存在，服务停止")
 			return nil
 		}
 
+		log.Fatalf("读取配置文件失败: %s", err)
+	}
+
+	viper.SetConfigType(strings.TrimPrefix(filepath.Ext(configPath), "."))
+	if err := viper.ReadConfig(bytes.NewReader(expandEnv(content))); err != nil {
 		log.Fatalf("配置文件格式不正确: %s", err)
 	}
 
@@ -221,9 +277,227 @@ type MongoDB struct {
 }
 
 type JWTConfig struct {
-	SecretKey string        `yaml:"secretKey"`
-	Issuer    string        `yaml:"issuer"`
-	Duration  time.Duration `yaml:"duration"`
+	SecretKey       string        `yaml:"secretKey"`
+	Issuer          string        `yaml:"issuer"`
+	Duration        time.Duration `yaml:"duration"`        // access token 有效期，默认 15m
+	RefreshDuration time.Duration `yaml:"refreshDuration"` // refresh token 有效期，默认 168h
+	SigningKeyID    string        `yaml:"signingKeyId"`    // 签名密钥ID，只有一个密钥时可省略
+	Keys            []JWTKey      `yaml:"keys"`            // 非对称密钥，配置后使用非对称算法签名并发布 JWKS
+}
+
+// JWTKey JWT 非对称密钥配置
+type JWTKey struct {
+	ID             string `yaml:"id"`             // 密钥ID（kid）
+	Algorithm      string `yaml:"algorithm"`      // 签名算法，为空时根据密钥类型推断：RSA→RS256，P-256→ES256，Ed25519→EdDSA
+	PrivateKeyFile string `yaml:"privateKeyFile"` // PEM 私钥文件，签名密钥必须配置
+	PublicKeyFile  string `yaml:"publicKeyFile"`  // PEM 公钥文件，仅用于验签（如轮换下线中的旧密钥）时可只配置公钥
+}
+
+// UserConfig 用户模块配置
+type UserConfig struct {
+	Password      PasswordConfig      `yaml:"password"`      // 密码哈希配置
+	Lockout       LockoutConfig       `yaml:"lockout"`       // 登录失败锁定策略
+	Registration  RegistrationConfig  `yaml:"registration"`  // 自助注册与邮箱验证
+	PasswordReset PasswordResetConfig `yaml:"passwordReset"` // 找回密码
+	TwoFactor     TwoFactorConfig     `yaml:"twoFactor"`     // 双重认证
+	Session       SessionConfig       `yaml:"session"`       // 浏览器会话（Cookie）认证
+}
+
+// PasswordConfig 密码哈希配置，未配置的参数使用默认值
+type PasswordConfig struct {
+	Algorithm   string `yaml:"algorithm"`   // 新密码使用的哈希算法：argon2id（默认）或 bcrypt
+	Memory      uint32 `yaml:"memory"`      // argon2id 内存开销（KiB），默认 65536
+	Iterations  uint32 `yaml:"iterations"`  // argon2id 迭代次数，默认 3
+	Parallelism uint8  `yaml:"parallelism"` // argon2id 并行度，默认 2
+	SaltLength  uint32 `yaml:"saltLength"`  // argon2id 盐长度（字节），默认 16
+	KeyLength   uint32 `yaml:"keyLength"`   // argon2id 哈希长度（字节），默认 32
+	BcryptCost  int    `yaml:"bcryptCost"`  // bcrypt 计算成本，默认 10
+}
+
+// LockoutConfig 登录失败锁定策略配置
+type LockoutConfig struct {
+	Enable      bool          `yaml:"enable"`      // 是否启用登录失败锁定
+	MaxAttempts int           `yaml:"maxAttempts"` // 连续失败多少次后锁定，默认 5
+	Duration    time.Duration `yaml:"duration"`    // 锁定时长，默认 15m
+	Exponential bool          `yaml:"exponential"` // 是否指数递增锁定时长，锁定期满后每次失败锁定时长翻倍
+	MaxDuration time.Duration `yaml:"maxDuration"` // 指数递增时的最长锁定时长，默认 24h
+	Window      time.Duration `yaml:"window"`      // 观察窗口，距上次失败超过该时长时重新计数，默认 24h
+}
+
+// GetMaxAttempts 获取锁定前允许的最大失败次数，如果未配置则返回默认值
+func (l *LockoutConfig) GetMaxAttempts() int {
+	if l.MaxAttempts <= 0 {
+		return 5
+	}
+	return l.MaxAttempts
+}
+
+// GetDuration 获取锁定时长，如果未配置则返回默认值
+func (l *LockoutConfig) GetDuration() time.Duration {
+	if l.Duration <= 0 {
+		return 15 * time.Minute
+	}
+	return l.Duration
+}
+
+// GetWindow 获取失败次数的观察窗口，如果未配置则返回默认值
+func (l *LockoutConfig) GetWindow() time.Duration {
+	if l.Window <= 0 {
+		return 24 * time.Hour
+	}
+	return l.Window
+}
+
+// GetMaxDuration 获取最长锁定时长，如果未配置则返回默认值
+func (l *LockoutConfig) GetMaxDuration() time.Duration {
+	if l.MaxDuration <= 0 {
+		return 24 * time.Hour
+	}
+	return l.MaxDuration
+}
+
+// RegistrationConfig 自助注册与邮箱验证配置
+type RegistrationConfig struct {
+	Enable                   bool          `yaml:"enable"`                   // 是否开放自助注册
+	RequireEmailVerification bool          `yaml:"requireEmailVerification"` // 是否要求邮箱验证后才能登录
+	VerificationTTL          time.Duration `yaml:"verificationTTL"`          // 验证链接有效期，默认 24h
+	VerifyURL                string        `yaml:"verifyURL"`                // 验证链接地址，token 以查询参数附加在其后
+}
+
+// GetVerificationTTL 获取验证链接有效期，如果未配置则返回默认值
+func (r *RegistrationConfig) GetVerificationTTL() time.Duration {
+	if r.VerificationTTL <= 0 {
+		return 24 * time.Hour
+	}
+	return r.VerificationTTL
+}
+
+// PasswordResetConfig 找回密码配置
+type PasswordResetConfig struct {
+	TokenTTL time.Duration `yaml:"tokenTTL"` // 重置链接有效期，默认 1h
+	ResetURL string        `yaml:"resetURL"` // 重置链接地址，token 以查询参数附加在其后
+}
+
+// GetTokenTTL 获取重置链接有效期，如果未配置则返回默认值
+func (p *PasswordResetConfig) GetTokenTTL() time.Duration {
+	if p.TokenTTL <= 0 {
+		return time.Hour
+	}
+	return p.TokenTTL
+}
+
+// TwoFactorConfig 双重认证（TOTP）配置
+type TwoFactorConfig struct {
+	Issuer            string        `yaml:"issuer"`            // 验证器应用中显示的发行方名称
+	EncryptionKey     string        `yaml:"encryptionKey"`     // 已弃用：旧的 TOTP 密钥加密密钥（base64），只用于解密旧密文，执行 rekey 迁移后移除，v2.0.0 将不再支持
+	ChallengeTTL      time.Duration `yaml:"challengeTTL"`      // 登录第二步的有效期，默认 5m
+	RecoveryCodeCount int           `yaml:"recoveryCodeCount"` // 恢复码数量，默认 10
+	RecoveryCodeKey   string        `yaml:"recoveryCodeKey"`   // 恢复码摘要密钥（base64，至少 32 字节），未配置时恢复码使用密码哈希算法保存
+}
+
+// GetChallengeTTL 获取登录第二步的有效期，如果未配置则返回默认值
+func (t *TwoFactorConfig) GetChallengeTTL() time.Duration {
+	if t.ChallengeTTL <= 0 {
+		return 5 * time.Minute
+	}
+	return t.ChallengeTTL
+}
+
+// GetRecoveryCodeCount 获取恢复码数量，如果未配置则返回默认值
+func (t *TwoFactorConfig) GetRecoveryCodeCount() int {
+	if t.RecoveryCodeCount <= 0 {
+		return 10
+	}
+	return t.RecoveryCodeCount
+}
+
+// SessionConfig 服务端会话配置，启用后浏览器可使用 HttpOnly Cookie 代替 Bearer token，会话保存在 Redis 中
+type SessionConfig struct {
+	Enable          bool          `yaml:"enable"`          // 是否启用会话模式，需要启用 Redis
+	CookieName      string        `yaml:"cookieName"`      // 会话 Cookie 名称，默认 sid
+	CookieDomain    string        `yaml:"cookieDomain"`    // Cookie 域名，为空时仅限当前域名
+	CookiePath      string        `yaml:"cookiePath"`      // Cookie 路径，默认 /
+	Secure          bool          `yaml:"secure"`          // 是否只通过 HTTPS 发送，生产环境必须开启
+	SameSite        string        `yaml:"sameSite"`        // lax（默认）、strict 或 none（需同时开启 secure）
+	IdleTimeout     time.Duration `yaml:"idleTimeout"`     // 空闲超时，超过该时长未访问则会话失效，默认 30m
+	AbsoluteTimeout time.Duration `yaml:"absoluteTimeout"` // 会话最长有效期，默认 168h
+}
+
+// GetCookieName 获取会话 Cookie 名称，如果未配置则返回默认值
+func (s *SessionConfig) GetCookieName() string {
+	if s.CookieName == "" {
+		return "sid"
+	}
+	return s.CookieName
+}
+
+// GetCookiePath 获取会话 Cookie 路径，如果未配置则返回默认值
+func (s *SessionConfig) GetCookiePath() string {
+	if s.CookiePath == "" {
+		return "/"
+	}
+	return s.CookiePath
+}
+
+// GetIdleTimeout 获取会话空闲超时，如果未配置则返回默认值
+func (s *SessionConfig) GetIdleTimeout() time.Duration {
+	if s.IdleTimeout <= 0 {
+		return 30 * time.Minute
+	}
+	return s.IdleTimeout
+}
+
+// GetAbsoluteTimeout 获取会话最长有效期，如果未配置则返回默认值
+func (s *SessionConfig) GetAbsoluteTimeout() time.Duration {
+	if s.AbsoluteTimeout <= 0 {
+		return 7 * 24 * time.Hour
+	}
+	return s.AbsoluteTimeout
+}
+
+// MailConfig 邮件发送配置
+type MailConfig struct {
+	Driver string     `yaml:"driver"` // 发送器：smtp、file 或 log（默认，只写日志）
+	From   string     `yaml:"from"`   // 发件人
+	Dir    string     `yaml:"dir"`    // file 发送器的输出目录，默认 ./logs/mail
+	SMTP   SMTPConfig `yaml:"smtp"`   // smtp 发送器配置
+}
+
+// SMTPConfig SMTP 服务器配置
+type SMTPConfig struct {
+	Host     string        `yaml:"host"`
+	Port     int           `yaml:"port"` // 默认 587
+	Username string        `yaml:"username"`
+	Password string        `yaml:"password"`
+	Security string        `yaml:"security"` // starttls（默认）、tls 或 none
+	Timeout  time.Duration `yaml:"timeout"`  // 连接与发送超时，默认 10s
+}
+
+// OIDCConfig OpenID Connect 第三方登录配置
+type OIDCConfig struct {
+	StateTTL  time.Duration  `yaml:"stateTTL"`  // 授权流程（state 与 PKCE）有效期，默认 10m
+	Providers []OIDCProvider `yaml:"providers"` // 身份提供方列表，为空时不开放第三方登录
+}
+
+// OIDCProvider 单个 OpenID Connect 身份提供方配置
+type OIDCProvider struct {
+	Name         string   `yaml:"name"`         // 提供方标识，用于路由 /auth/oidc/:provider，例如 google
+	DisplayName  string   `yaml:"displayName"`  // 展示名称
+	Issuer       string   `yaml:"issuer"`       // 发行方地址，通过 {issuer}/.well-known/openid-configuration 发现端点
+	ClientID     string   `yaml:"clientId"`     // 客户端ID
+	ClientSecret string   `yaml:"clientSecret"` // 客户端密钥
+	RedirectURL  string   `yaml:"redirectURL"`  // 回调地址，需与提供方登记的一致
+	Scopes       []string `yaml:"scopes"`       // 额外申请的 scope，openid 总会包含，默认 openid email profile
+	LinkByEmail  bool     `yaml:"linkByEmail"`  // 是否按已验证邮箱关联到已有用户
+	AutoCreate   bool     `yaml:"autoCreate"`   // 未关联时是否自动创建用户
+}
+
+// GetStateTTL 获取授权流程有效期，如果未配置则返回默认值
+func (o *OIDCConfig) GetStateTTL() time.Duration {
+	if o.StateTTL <= 0 {
+		return 10 * time.Minute
+	}
+	return o.StateTTL
 }
 
 // Swagger Swagger 配置
@@ -258,7 +532,173 @@ type RateLimiter struct {
 
 // OpenAPIConfig OpenAPI配置
 type OpenAPIConfig struct {
-	Enable bool `yaml:"enable"` // 是否启用OpenAPI
+	Enable             bool          `yaml:"enable"`             // 是否启用OpenAPI
+	TokenTTL           time.Duration `yaml:"tokenTTL"`           // OAuth2 客户端凭证模式签发的 access token 有效期，默认 1h
+	AllowLegacySign    bool          `yaml:"allowLegacySign"`    // 是否接受不含 nonce 的旧版签名 HMAC(apiKey + timestamp)，仅用于调用方迁移期间
+	UsageFlushInterval time.Duration `yaml:"usageFlushInterval"` // API密钥调用量从计数器刷入数据库的间隔，默认 1m
+}
+
+// GetTokenTTL 获取客户端 access token 有效期，如果未配置则返回默认值
+func (o *OpenAPIConfig) GetTokenTTL() time.Duration {
+	if o.TokenTTL <= 0 {
+		return time.Hour
+	}
+	return o.TokenTTL
+}
+
+// GetUsageFlushInterval 获取调用量刷入间隔，如果未配置则返回默认值
+func (o *OpenAPIConfig) GetUsageFlushInterval() time.Duration {
+	if o.UsageFlushInterval <= 0 {
+		return time.Minute
+	}
+	return o.UsageFlushInterval
+}
+
+// SchedulerConfig 调度服务配置
+//
+// 多实例部署时各实例会加载同一批任务，需开启集群模式保证每次调度只在一个实例上执行：
+// lock 模式下每次执行前按任务与调度时间抢占分布式锁；leader 模式下各实例选举一个主实例，只有主实例执行调度任务。
+type SchedulerConfig struct {
+	ClusterMode string        `yaml:"clusterMode"` // 集群模式：none（默认，单实例）、lock、leader
+	LockBackend string        `yaml:"lockBackend"` // 分布式锁后端：redis（默认）或 etcd，需启用对应的连接器
+	LockTTL     time.Duration `yaml:"lockTTL"`     // 锁有效期，leader 模式下主实例按 1/3 有效期续约，默认 30s
+	NodeID      string        `yaml:"nodeId"`      // 实例标识，用于日志、锁的持有者与执行历史，默认使用主机名与进程号
+
+	HistoryRetention time.Duration `yaml:"historyRetention"` // 执行历史保留时长，超过的记录每小时清理一次，默认 720h（30 天）
+
+	// 以下为任务的默认执行策略，单个任务可通过管理接口设置自己的策略
+	TaskTimeout     time.Duration `yaml:"taskTimeout"`     // 单次执行超时时间，超时后取消任务的 context，默认 10m
+	MaxAttempts     int           `yaml:"maxAttempts"`     // 每次调度的最大执行次数（含首次），默认 1 即不重试
+	RetryBackoff    time.Duration `yaml:"retryBackoff"`    // 首次重试前的等待时间，之后按倍数递增，默认 1s
+	MaxRetryBackoff time.Duration `yaml:"maxRetryBackoff"` // 重试等待时间上限，默认 1m
+	RetryMultiplier float64       `yaml:"retryMultiplier"` // 重试等待时间的增长倍数，默认 2
+	RetryJitter     *float64      `yaml:"retryJitter"`     // 重试等待时间的随机抖动比例（0-1），默认 0.2
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"` // 停止时等待执行中任务退出的时间，默认 30s
+	AlertEmails     []string      `yaml:"alertEmails"`     // 任务重试耗尽仍失败时接收告警邮件的地址，为空时只记录日志
+}
+
+// GetClusterMode 获取集群模式，如果未配置则返回 none
+func (s *SchedulerConfig) GetClusterMode() string {
+	if s.ClusterMode == "" {
+		return "none"
+	}
+	return s.ClusterMode
+}
+
+// GetLockBackend 获取分布式锁后端，如果未配置则返回 redis
+func (s *SchedulerConfig) GetLockBackend() string {
+	if s.LockBackend == "" {
+		return "redis"
+	}
+	return s.LockBackend
+}
+
+// GetLockTTL 获取锁有效期，如果未配置则返回默认值
+func (s *SchedulerConfig) GetLockTTL() time.Duration {
+	if s.LockTTL <= 0 {
+		return 30 * time.Second
+	}
+	return s.LockTTL
+}
+
+// GetHistoryRetention 获取执行历史保留时长，如果未配置则返回默认值
+func (s *SchedulerConfig) GetHistoryRetention() time.Duration {
+	if s.HistoryRetention <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return s.HistoryRetention
+}
+
+// GetTaskTimeout 获取单次执行超时时间，如果未配置则返回默认值
+func (s *SchedulerConfig) GetTaskTimeout() time.Duration {
+	if s.TaskTimeout <= 0 {
+		return 10 * time.Minute
+	}
+	return s.TaskTimeout
+}
+
+// GetMaxAttempts 获取每次调度的最大执行次数，如果未配置则返回 1
+func (s *SchedulerConfig) GetMaxAttempts() int {
+	if s.MaxAttempts <= 0 {
+		return 1
+	}
+	return s.MaxAttempts
+}
+
+// GetRetryBackoff 获取首次重试前的等待时间，如果未配置则返回默认值
+func (s *SchedulerConfig) GetRetryBackoff() time.Duration {
+	if s.RetryBackoff <= 0 {
+		return time.Second
+	}
+	return s.RetryBackoff
+}
+
+// GetMaxRetryBackoff 获取重试等待时间上限，如果未配置则返回默认值
+func (s *SchedulerConfig) GetMaxRetryBackoff() time.Duration {
+	if s.MaxRetryBackoff <= 0 {
+		return time.Minute
+	}
+	return s.MaxRetryBackoff
+}
+
+// GetRetryMultiplier 获取重试等待时间的增长倍数，如果未配置则返回 2
+func (s *SchedulerConfig) GetRetryMultiplier() float64 {
+	if s.RetryMultiplier <= 0 {
+		return 2
+	}
+	return s.RetryMultiplier
+}
+
+// GetRetryJitter 获取重试等待时间的随机抖动比例，如果未配置则返回 0.2
+func (s *SchedulerConfig) GetRetryJitter() float64 {
+	if s.RetryJitter == nil {
+		return 0.2
+	}
+	return *s.RetryJitter
+}
+
+// GetShutdownTimeout 获取停止时等待执行中任务退出的时间，如果未配置则返回默认值
+func (s *SchedulerConfig) GetShutdownTimeout() time.Duration {
+	if s.ShutdownTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return s.ShutdownTimeout
+}
+
+// GetNodeID 获取实例标识，如果未配置则使用主机名与进程号
+func (s *SchedulerConfig) GetNodeID() string {
+	if s.NodeID != "" {
+		return s.NodeID
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// Metrics Prometheus 监控指标配置
+type Metrics struct {
+	Enable    bool      `yaml:"enable"`    // 是否启用监控指标
+	Path      string    `yaml:"path"`      // 指标暴露路径
+	Namespace string    `yaml:"namespace"` // 指标命名空间（前缀）
+	Buckets   []float64 `yaml:"buckets"`   // 请求耗时直方图分桶（秒）
+}
+
+// GetPath 获取指标暴露路径，如果未配置则返回默认值
+func (m *Metrics) GetPath() string {
+	if m.Path == "" {
+		return "/metrics"
+	}
+	return m.Path
+}
+
+// GetBuckets 获取请求耗时直方图分桶，如果未配置则返回默认值
+func (m *Metrics) GetBuckets() []float64 {
+	if len(m.Buckets) == 0 {
+		return []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	}
+	return m.Buckets
 }
 
 // GetBatchTimeout 获取批处理超时时间，如果未配置则返回默认值
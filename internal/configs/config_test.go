@@ -0,0 +1,25 @@
+package configs
+
+import "testing"
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("CSRF_SECRET", "from-env")
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"set variable", "secret: ${CSRF_SECRET}", "secret: from-env"},
+		{"unset variable", "secret: ${UNSET_CONFIG_VARIABLE}", "secret: "},
+		{"dollar without braces", "password: pa$$word$CSRF_SECRET", "password: pa$$word$CSRF_SECRET"},
+		{"invalid name", "value: ${1ABC}", "value: ${1ABC}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(expandEnv([]byte(tt.content))); got != tt.want {
+				t.Errorf("expandEnv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
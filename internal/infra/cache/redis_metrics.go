@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// redisPoolCollector Redis 连接池指标收集器，采集时读取 redis.PoolStats
+type redisPoolCollector struct {
+	connector *RedisConnector
+
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	total      *prometheus.Desc
+	idle       *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+// Collector 实现 connector.MetricsExporter 接口
+func (c *RedisConnector) Collector(namespace string) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", name), help, nil, nil)
+	}
+
+	return &redisPoolCollector{
+		connector:  c,
+		hits:       desc("hits_total", "从连接池中获取到空闲连接的次数"),
+		misses:     desc("misses_total", "连接池中没有空闲连接的次数"),
+		timeouts:   desc("timeouts_total", "等待连接超时的次数"),
+		total:      desc("total_connections", "连接池中的连接总数"),
+		idle:       desc("idle_connections", "连接池中的空闲连接数"),
+		staleConns: desc("stale_connections_total", "被移除的过期连接总数"),
+	}
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (r *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.hits
+	ch <- r.misses
+	ch <- r.timeouts
+	ch <- r.total
+	ch <- r.idle
+	ch <- r.staleConns
+}
+
+// Collect 实现 prometheus.Collector 接口，未连接时不输出任何指标
+func (r *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := r.connector.Stats(context.Background())
+	if err != nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(r.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(r.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(r.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(r.total, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(r.idle, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(r.staleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
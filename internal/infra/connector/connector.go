@@ -3,6 +3,7 @@ package connector
 import (
 	"context"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
@@ -16,6 +17,12 @@ type BaseConnector interface {
 	HealthCheck(ctx context.Context) (bool, error)
 }
 
+// MetricsExporter 可选接口，实现该接口的连接器注册到工厂时会自动暴露连接池指标
+type MetricsExporter interface {
+	// Collector 返回连接器的指标收集器，namespace 为指标命名空间
+	Collector(namespace string) prometheus.Collector
+}
+
 // Connector 提供了基础连接器实现
 type Connector struct {
 	name      string
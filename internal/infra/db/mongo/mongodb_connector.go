@@ -19,6 +19,7 @@ type MongoDBConnector struct {
 	connector.Connector
 	config *configs.MongoDB
 	client *mongo.Client
+	stats  poolStats
 }
 
 // NewMongoDBConnector 创建MongoDB连接器
@@ -42,7 +43,8 @@ func (c *MongoDBConnector) Connect(ctx context.Context) error {
 		ApplyURI(c.config.URI).
 		SetMaxPoolSize(uint64(c.config.MaxPoolSize)).
 		SetMinPoolSize(uint64(c.config.MinPoolSize)).
-		SetMaxConnIdleTime(time.Duration(c.config.MaxConnIdleTime) * time.Second)
+		SetMaxConnIdleTime(time.Duration(c.config.MaxConnIdleTime) * time.Second).
+		SetPoolMonitor(c.poolMonitor())
 
 	if c.config.Username != "" && c.config.Password != "" {
 		credential := options.Credential{
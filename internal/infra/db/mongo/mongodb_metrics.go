@@ -0,0 +1,101 @@
+package mongo
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// poolStats 基于驱动连接池事件统计的连接池状态
+type poolStats struct {
+	created    atomic.Int64
+	closed     atomic.Int64
+	checkedOut atomic.Int64
+	checkedIn  atomic.Int64
+	getFailed  atomic.Int64
+	cleared    atomic.Int64
+}
+
+// poolMonitor 返回用于统计连接池状态的事件监听器
+func (c *MongoDBConnector) poolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			switch evt.Type {
+			case event.ConnectionCreated:
+				c.stats.created.Add(1)
+			case event.ConnectionClosed:
+				c.stats.closed.Add(1)
+			case event.GetSucceeded:
+				c.stats.checkedOut.Add(1)
+			case event.ConnectionReturned:
+				c.stats.checkedIn.Add(1)
+			case event.GetFailed:
+				c.stats.getFailed.Add(1)
+			case event.PoolCleared:
+				c.stats.cleared.Add(1)
+			}
+		},
+	}
+}
+
+// mongoPoolCollector MongoDB 连接池指标收集器
+type mongoPoolCollector struct {
+	connector *MongoDBConnector
+
+	open       *prometheus.Desc
+	inUse      *prometheus.Desc
+	created    *prometheus.Desc
+	closed     *prometheus.Desc
+	checkouts  *prometheus.Desc
+	getFailed  *prometheus.Desc
+	poolClears *prometheus.Desc
+}
+
+// Collector 实现 connector.MetricsExporter 接口
+func (c *MongoDBConnector) Collector(namespace string) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "mongodb_pool", name), help, nil, nil)
+	}
+
+	return &mongoPoolCollector{
+		connector:  c,
+		open:       desc("open_connections", "当前打开的连接数"),
+		inUse:      desc("in_use_connections", "使用中的连接数"),
+		created:    desc("connections_created_total", "创建的连接总数"),
+		closed:     desc("connections_closed_total", "关闭的连接总数"),
+		checkouts:  desc("checkouts_total", "成功获取连接的总次数"),
+		getFailed:  desc("checkout_failures_total", "获取连接失败的总次数"),
+		poolClears: desc("clears_total", "连接池被清空的总次数"),
+	}
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (m *mongoPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.open
+	ch <- m.inUse
+	ch <- m.created
+	ch <- m.closed
+	ch <- m.checkouts
+	ch <- m.getFailed
+	ch <- m.poolClears
+}
+
+// Collect 实现 prometheus.Collector 接口，未连接时不输出任何指标
+func (m *mongoPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	if !m.connector.IsConnected() {
+		return
+	}
+
+	stats := &m.connector.stats
+	created, closed := stats.created.Load(), stats.closed.Load()
+	checkedOut, checkedIn := stats.checkedOut.Load(), stats.checkedIn.Load()
+
+	ch <- prometheus.MustNewConstMetric(m.open, prometheus.GaugeValue, float64(created-closed))
+	ch <- prometheus.MustNewConstMetric(m.inUse, prometheus.GaugeValue, float64(checkedOut-checkedIn))
+	ch <- prometheus.MustNewConstMetric(m.created, prometheus.CounterValue, float64(created))
+	ch <- prometheus.MustNewConstMetric(m.closed, prometheus.CounterValue, float64(closed))
+	ch <- prometheus.MustNewConstMetric(m.checkouts, prometheus.CounterValue, float64(checkedOut))
+	ch <- prometheus.MustNewConstMetric(m.getFailed, prometheus.CounterValue, float64(stats.getFailed.Load()))
+	ch <- prometheus.MustNewConstMetric(m.poolClears, prometheus.CounterValue, float64(stats.cleared.Load()))
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"goWebExample/internal/pkg/jwt"
+	"goWebExample/internal/pkg/mail"
+	"goWebExample/internal/pkg/password"
 
 	"go.uber.org/zap"
 
+	"goWebExample/internal/configs"
 	"goWebExample/internal/infra/db/mysql"
 	"goWebExample/internal/infra/di/factory"
 	"goWebExample/internal/infra/discovery"
@@ -14,11 +17,14 @@ import (
 
 // ServiceContainer 包含所有服务依赖
 type ServiceContainer struct {
+	Config          *configs.AllConfig
 	Factory         *factory.Factory
 	DBConnector     *mysql.DBConnector
 	EtcdConnector   *discovery.EtcdConnector
 	ServiceRegistry discovery.ServiceRegistry
 	JWTManager      *jwt.JwtManager
+	PasswordHasher  *password.Hasher
+	Mailer          mail.Sender
 	logger          *zap.Logger
 }
 
@@ -88,6 +94,11 @@ func (c *ServiceContainer) Shutdown(ctx context.Context) {
 	}
 }
 
+// GetConfig 获取应用配置
+func (c *ServiceContainer) GetConfig() *configs.AllConfig {
+	return c.Config
+}
+
 // GetFactory 获取服务工厂
 func (c *ServiceContainer) GetFactory() *factory.Factory {
 	return c.Factory
@@ -112,3 +123,13 @@ func (c *ServiceContainer) GetServiceRegistry() discovery.ServiceRegistry {
 func (c *ServiceContainer) GetJWTManager() *jwt.JwtManager {
 	return c.JWTManager
 }
+
+// GetPasswordHasher 获取密码哈希器
+func (c *ServiceContainer) GetPasswordHasher() *password.Hasher {
+	return c.PasswordHasher
+}
+
+// GetMailer 获取邮件发送器
+func (c *ServiceContainer) GetMailer() mail.Sender {
+	return c.Mailer
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"sync"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
 	"goWebExample/internal/configs"
 	"goWebExample/internal/infra/connector"
+	"goWebExample/internal/pkg/metrics"
 )
 
 // Factory 服务工厂，用于管理所有连接器
@@ -32,10 +34,31 @@ func NewFactory(config *configs.AllConfig, logger *zap.Logger) *Factory {
 // RegisterConnector 注册一个连接器
 func (f *Factory) RegisterConnector(name string, connector connector.BaseConnector) {
 	f.mu.Lock()
-	defer f.mu.Unlock()
 	f.connectors[name] = connector
 	// 添加到关闭顺序列表的开头，这样后注册的会先关闭
 	f.shutdownOrder = append([]string{name}, f.shutdownOrder...)
+	f.mu.Unlock()
+
+	f.registerMetrics(name, connector)
+}
+
+// registerMetrics 为实现了 MetricsExporter 的连接器注册指标收集器，指标带有 connector 标签
+func (f *Factory) registerMetrics(name string, c connector.BaseConnector) {
+	if f.config == nil || f.config.Metrics == nil || !f.config.Metrics.Enable {
+		return
+	}
+
+	exporter, ok := c.(connector.MetricsExporter)
+	if !ok {
+		return
+	}
+
+	collector := exporter.Collector(f.config.Metrics.Namespace)
+	if err := metrics.Register(collector, prometheus.Labels{"connector": name}); err != nil {
+		f.logger.Warn("注册连接器监控指标失败",
+			zap.String("name", name),
+			zap.Error(err))
+	}
 }
 
 // GetConnector 获取一个连接器
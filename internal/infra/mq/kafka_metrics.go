@@ -0,0 +1,137 @@
+package mq
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// kafkaCollector Kafka 生产者/消费者指标收集器
+//
+// kafka-go 的 Writer.Stats / Reader.Stats 每次调用都会重置计数器，
+// 因此收集器在每次采集时累加增量，以单调递增的 counter 形式对外暴露。
+// 其他代码不应再调用 Stats，否则对应时间段内的增量会丢失。
+type kafkaCollector struct {
+	connector *KafkaConnector
+
+	mu     sync.Mutex
+	writer kafkaWriterTotals
+	reader kafkaReaderTotals
+
+	writerWrites   *prometheus.Desc
+	writerMessages *prometheus.Desc
+	writerBytes    *prometheus.Desc
+	writerErrors   *prometheus.Desc
+	writerRetries  *prometheus.Desc
+
+	readerDials      *prometheus.Desc
+	readerFetches    *prometheus.Desc
+	readerMessages   *prometheus.Desc
+	readerBytes      *prometheus.Desc
+	readerRebalances *prometheus.Desc
+	readerTimeouts   *prometheus.Desc
+	readerErrors     *prometheus.Desc
+	readerLag        *prometheus.Desc
+	readerQueueLen   *prometheus.Desc
+}
+
+// kafkaWriterTotals 生产者累计值
+type kafkaWriterTotals struct {
+	writes, messages, bytes, errors, retries int64
+}
+
+// kafkaReaderTotals 消费者累计值
+type kafkaReaderTotals struct {
+	dials, fetches, messages, bytes, rebalances, timeouts, errors int64
+}
+
+// Collector 实现 connector.MetricsExporter 接口
+func (c *KafkaConnector) Collector(namespace string) prometheus.Collector {
+	desc := func(subsystem, name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, name), help, nil, nil)
+	}
+
+	return &kafkaCollector{
+		connector: c,
+
+		writerWrites:   desc("kafka_writer", "writes_total", "生产者写请求总数"),
+		writerMessages: desc("kafka_writer", "messages_total", "生产者发送的消息总数"),
+		writerBytes:    desc("kafka_writer", "bytes_total", "生产者发送的消息字节总数"),
+		writerErrors:   desc("kafka_writer", "errors_total", "生产者写入错误总数"),
+		writerRetries:  desc("kafka_writer", "retries_total", "生产者重试总数"),
+
+		readerDials:      desc("kafka_reader", "dials_total", "消费者建立连接总数"),
+		readerFetches:    desc("kafka_reader", "fetches_total", "消费者拉取请求总数"),
+		readerMessages:   desc("kafka_reader", "messages_total", "消费者读取的消息总数"),
+		readerBytes:      desc("kafka_reader", "bytes_total", "消费者读取的消息字节总数"),
+		readerRebalances: desc("kafka_reader", "rebalances_total", "消费者组再均衡总数"),
+		readerTimeouts:   desc("kafka_reader", "timeouts_total", "消费者读取超时总数"),
+		readerErrors:     desc("kafka_reader", "errors_total", "消费者读取错误总数"),
+		readerLag:        desc("kafka_reader", "lag", "消费者当前消费延迟（消息数）"),
+		readerQueueLen:   desc("kafka_reader", "queue_length", "消费者内部队列中待处理的消息数"),
+	}
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (k *kafkaCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		k.writerWrites, k.writerMessages, k.writerBytes, k.writerErrors, k.writerRetries,
+		k.readerDials, k.readerFetches, k.readerMessages, k.readerBytes, k.readerRebalances,
+		k.readerTimeouts, k.readerErrors, k.readerLag, k.readerQueueLen,
+	} {
+		ch <- d
+	}
+}
+
+// Collect 实现 prometheus.Collector 接口，未连接时不输出任何指标
+func (k *kafkaCollector) Collect(ch chan<- prometheus.Metric) {
+	if !k.connector.IsConnected() {
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	counter := func(desc *prometheus.Desc, v int64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(v))
+	}
+	gauge := func(desc *prometheus.Desc, v int64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(v))
+	}
+
+	if producer := k.connector.GetProducer(); producer != nil {
+		stats := producer.Stats()
+		k.writer.writes += stats.Writes
+		k.writer.messages += stats.Messages
+		k.writer.bytes += stats.Bytes
+		k.writer.errors += stats.Errors
+		k.writer.retries += stats.Retries
+
+		counter(k.writerWrites, k.writer.writes)
+		counter(k.writerMessages, k.writer.messages)
+		counter(k.writerBytes, k.writer.bytes)
+		counter(k.writerErrors, k.writer.errors)
+		counter(k.writerRetries, k.writer.retries)
+	}
+
+	if consumer := k.connector.GetConsumer(); consumer != nil {
+		stats := consumer.Stats()
+		k.reader.dials += stats.Dials
+		k.reader.fetches += stats.Fetches
+		k.reader.messages += stats.Messages
+		k.reader.bytes += stats.Bytes
+		k.reader.rebalances += stats.Rebalances
+		k.reader.timeouts += stats.Timeouts
+		k.reader.errors += stats.Errors
+
+		counter(k.readerDials, k.reader.dials)
+		counter(k.readerFetches, k.reader.fetches)
+		counter(k.readerMessages, k.reader.messages)
+		counter(k.readerBytes, k.reader.bytes)
+		counter(k.readerRebalances, k.reader.rebalances)
+		counter(k.readerTimeouts, k.reader.timeouts)
+		counter(k.readerErrors, k.reader.errors)
+		gauge(k.readerLag, stats.Lag)
+		gauge(k.readerQueueLen, stats.QueueLength)
+	}
+}
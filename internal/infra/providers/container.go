@@ -1,9 +1,13 @@
 package providers
 
 import (
+	"fmt"
+
 	"go.uber.org/zap"
 	"goWebExample/internal/infra/mq"
 	"goWebExample/internal/pkg/jwt"
+	"goWebExample/internal/pkg/mail"
+	"goWebExample/internal/pkg/password"
 
 	"goWebExample/internal/configs"
 	"goWebExample/internal/infra/cache"
@@ -20,6 +24,7 @@ type ServiceContainer struct {
 	EtcdConnector   *discovery.EtcdConnector
 	ServiceRegistry discovery.ServiceRegistry
 	JWTManager      *jwt.JwtManager
+	PasswordHasher  *password.Hasher
 }
 
 // ProvideServiceFactory 创建服务工厂，统一管理所有连接器
@@ -28,6 +33,7 @@ func ProvideServiceFactory(config *configs.AllConfig, logger *zap.Logger) (*cont
 	newFactory := factory.NewFactory(config, logger)
 
 	serviceContainer := container.NewServiceContainer(logger)
+	serviceContainer.Config = config
 	serviceContainer.Factory = newFactory
 
 	// 创建数据库连接器
@@ -36,13 +42,66 @@ func ProvideServiceFactory(config *configs.AllConfig, logger *zap.Logger) (*cont
 	serviceContainer.DBConnector = dbConnector
 
 	// 创建 JWT 管理器
-	jwtManager := jwt.NewJWTManager(jwt.Config{
-		SecretKey: config.JWT.SecretKey,
-		Issuer:    config.JWT.Issuer,
-		Duration:  config.JWT.Duration,
+	jwtKeys := make([]jwt.KeyConfig, 0, len(config.JWT.Keys))
+	for _, key := range config.JWT.Keys {
+		jwtKeys = append(jwtKeys, jwt.KeyConfig{
+			ID:             key.ID,
+			Algorithm:      key.Algorithm,
+			PrivateKeyFile: key.PrivateKeyFile,
+			PublicKeyFile:  key.PublicKeyFile,
+		})
+	}
+	jwtManager, err := jwt.NewJWTManager(jwt.Config{
+		SecretKey:       config.JWT.SecretKey,
+		Issuer:          config.JWT.Issuer,
+		Duration:        config.JWT.Duration,
+		RefreshDuration: config.JWT.RefreshDuration,
+		SigningKeyID:    config.JWT.SigningKeyID,
+		Keys:            jwtKeys,
 	})
+	if err != nil {
+		return nil, fmt.Errorf("创建JWT管理器失败: %w", err)
+	}
 	serviceContainer.JWTManager = jwtManager
 
+	// 创建密码哈希器
+	passwordConfig := config.User.Password
+	passwordHasher, err := password.NewHasher(password.Config{
+		Algorithm: passwordConfig.Algorithm,
+		Argon2: password.Argon2Params{
+			Memory:      passwordConfig.Memory,
+			Iterations:  passwordConfig.Iterations,
+			Parallelism: passwordConfig.Parallelism,
+			SaltLength:  passwordConfig.SaltLength,
+			KeyLength:   passwordConfig.KeyLength,
+		},
+		BcryptCost: passwordConfig.BcryptCost,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建密码哈希器失败: %w", err)
+	}
+	serviceContainer.PasswordHasher = passwordHasher
+
+	// 创建邮件发送器
+	mailConfig := config.Mail
+	mailer, err := mail.New(mail.Config{
+		Driver: mailConfig.Driver,
+		From:   mailConfig.From,
+		Dir:    mailConfig.Dir,
+		SMTP: mail.SMTPConfig{
+			Host:     mailConfig.SMTP.Host,
+			Port:     mailConfig.SMTP.Port,
+			Username: mailConfig.SMTP.Username,
+			Password: mailConfig.SMTP.Password,
+			Security: mailConfig.SMTP.Security,
+			Timeout:  mailConfig.SMTP.Timeout,
+		},
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("创建邮件发送器失败: %w", err)
+	}
+	serviceContainer.Mailer = mailer
+
 	// 创建ETCD连接器
 	if config.Etcd != nil && config.Etcd.Enable {
 		etcdConnector := discovery.NewEtcdConnector(config.Etcd, logger)
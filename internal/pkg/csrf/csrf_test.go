@@ -0,0 +1,66 @@
+package csrf
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIssueAndVerify(t *testing.T) {
+	s, err := NewSigner([]byte(strings.Repeat("k", MinSecretSize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := s.Issue("session-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Verify(token, "session-a") {
+		t.Error("token should be valid for its own session")
+	}
+
+	// 绑定的会话不同（如登录后会话 Cookie 已变化）时无效
+	if s.Verify(token, "session-b") {
+		t.Error("token should not be valid for another session")
+	}
+	if s.Verify(token, "") {
+		t.Error("token should not be valid without session")
+	}
+
+	other, _ := NewRandomSigner()
+	if other.Verify(token, "session-a") {
+		t.Error("token should not be valid under another secret")
+	}
+
+	another, _ := s.Issue("session-a")
+	if another == token {
+		t.Error("tokens should be randomized")
+	}
+}
+
+func TestVerifyRejectsMalformed(t *testing.T) {
+	s, _ := NewRandomSigner()
+	token, _ := s.Issue("")
+	nonce, mac, _ := strings.Cut(token, ".")
+
+	for _, tc := range []string{
+		"",
+		nonce,
+		nonce + ".",
+		"." + mac,
+		nonce + "." + mac[:len(mac)-2],
+		"AAAA." + mac,
+		nonce + ".!!" + mac,
+	} {
+		if s.Verify(tc, "") {
+			t.Errorf("Verify(%q) = true", tc)
+		}
+	}
+}
+
+func TestNewSignerRejectsShortSecret(t *testing.T) {
+	if _, err := NewSigner([]byte("short")); !errors.Is(err, ErrInvalidSecret) {
+		t.Errorf("error = %v, want ErrInvalidSecret", err)
+	}
+}
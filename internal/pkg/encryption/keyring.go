@@ -0,0 +1,25 @@
+// Package encryption 根据配置创建 pkg/envelope 的加密器
+package encryption
+
+import (
+	"goWebExample/internal/configs"
+	"goWebExample/pkg/envelope"
+)
+
+// NewKeyring 根据配置创建信封加密密钥环，未配置主密钥时返回 nil, nil
+func NewKeyring(config configs.EncryptionConfig) (*envelope.Keyring, error) {
+	var keys [][]byte
+	var err error
+	switch {
+	case config.KeyFile != "":
+		keys, err = envelope.LoadKeyFile(config.KeyFile)
+	case len(config.MasterKeys) > 0:
+		keys, err = envelope.ParseKeys(config.MasterKeys)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return envelope.NewKeyring(keys...)
+}
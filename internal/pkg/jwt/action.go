@@ -0,0 +1,81 @@
+package jwt
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// 一次性操作 token 用途，写入 aud，不同用途的 token 不能混用
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+	PurposeMFAChallenge  = "mfa_challenge"
+	PurposeOIDCState     = "oidc_state"
+)
+
+var (
+	// ErrInvalidActionToken 操作 token 无效（签名错误、用途不符等）
+	ErrInvalidActionToken = errors.New("token无效")
+	// ErrActionTokenExpired 操作 token 已过期
+	ErrActionTokenExpired = errors.New("token已过期")
+)
+
+// ActionClaims 一次性操作 token（邮箱验证、密码重置等）的 Claims
+//
+// 签名只保证 token 未被篡改，一次性使用需要调用方以 jti 为键记录消费状态。
+type ActionClaims struct {
+	jwt.RegisteredClaims
+	Binding string            `json:"bnd,omitempty"` // 绑定值（如邮箱、安全戳），校验时与当前值比对，不一致说明 token 已失效
+	Data    map[string]string `json:"dat,omitempty"` // 附加数据，仅签名不加密，不能存放需要对持有者保密的内容
+}
+
+// IssueActionToken 签发一次性操作 token
+func (m *JwtManager) IssueActionToken(purpose, subject, binding string, ttl time.Duration) (string, *ActionClaims, error) {
+	return m.IssueActionTokenWithData(purpose, subject, binding, nil, ttl)
+}
+
+// IssueActionTokenWithData 签发携带附加数据的一次性操作 token
+func (m *JwtManager) IssueActionTokenWithData(purpose, subject, binding string, data map[string]string, ttl time.Duration) (string, *ActionClaims, error) {
+	now := time.Now()
+	claims := &ActionClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   subject,
+			Issuer:    m.config.Issuer,
+			Audience:  jwt.ClaimStrings{purpose},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+		Binding: binding,
+		Data:    data,
+	}
+
+	signed, err := m.sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+// ParseActionToken 校验一次性操作 token 的签名、有效期与用途
+func (m *JwtManager) ParseActionToken(tokenString, purpose string) (*ActionClaims, error) {
+	claims := &ActionClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, m.keyFunc,
+		jwt.WithAudience(purpose),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrActionTokenExpired
+		}
+		return nil, ErrInvalidActionToken
+	}
+	if !token.Valid || claims.ID == "" || claims.Subject == "" {
+		return nil, ErrInvalidActionToken
+	}
+	return claims, nil
+}
@@ -1,66 +1,220 @@
 package jwt
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// 默认有效期
+const (
+	DefaultDuration        = 15 * time.Minute
+	DefaultRefreshDuration = 7 * 24 * time.Hour
 )
 
 type Config struct {
-	SecretKey string        `mapstructure:"secretKey"`
-	Issuer    string        `mapstructure:"issuer"`
-	Duration  time.Duration `mapstructure:"duration"`
+	SecretKey       string        `mapstructure:"secretKey"`
+	Issuer          string        `mapstructure:"issuer"`
+	Duration        time.Duration `mapstructure:"duration"`        // access token 有效期
+	RefreshDuration time.Duration `mapstructure:"refreshDuration"` // refresh token 有效期
+	SigningKeyID    string        `mapstructure:"signingKeyId"`    // 签名密钥ID，只有一个密钥时可省略
+	Keys            []KeyConfig   `mapstructure:"keys"`            // 非对称密钥，配置后使用非对称算法签名，不再接受 HMAC token
+}
+
+// Denylist access token 吊销列表
+type Denylist interface {
+	// IsAccessTokenRevoked 判断 jti 对应的 access token 是否已被吊销
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// StampValidator 安全戳校验器，用户修改或重置密码后安全戳随之变化，此前签发的 token 全部失效
+type StampValidator interface {
+	// IsSecurityStampValid 判断 token 中的安全戳是否与用户当前安全戳一致
+	IsSecurityStampValid(ctx context.Context, userID, stamp string) (bool, error)
+}
+
+// ErrInvalidSession 会话不存在、已过期或已失效
+var ErrInvalidSession = errors.New("会话已失效")
+
+// SessionResolver 会话解析器，会话模式下浏览器携带 HttpOnly 会话 Cookie 而不是 Bearer token
+type SessionResolver interface {
+	// SessionCookieName 会话 Cookie 名称
+	SessionCookieName() string
+	// ResolveSession 将会话 Cookie 解析为与 access token 等价的 Claims，会话无效时返回 ErrInvalidSession
+	ResolveSession(ctx context.Context, cookieValue string) (*CustomClaims, error)
 }
 
 type JwtManager struct {
-	config Config
+	config          Config
+	keyRing         *KeyRing // 为 nil 时使用 HS256 + SecretKey
+	denylist        Denylist
+	stampValidator  StampValidator
+	sessionResolver SessionResolver
 }
 
-func NewJWTManager(config Config) *JwtManager {
-	return &JwtManager{
+// NewJWTManager 创建 JWT 管理器，配置了非对称密钥时从 PEM 文件加载密钥环
+func NewJWTManager(config Config) (*JwtManager, error) {
+	if config.Duration <= 0 {
+		config.Duration = DefaultDuration
+	}
+	if config.RefreshDuration <= 0 {
+		config.RefreshDuration = DefaultRefreshDuration
+	}
+
+	m := &JwtManager{
 		config: config,
 	}
+	if len(config.Keys) == 0 {
+		return m, nil
+	}
+
+	keyRing, err := LoadKeyRing(config.Keys, config.SigningKeyID)
+	if err != nil {
+		return nil, err
+	}
+	m.keyRing = keyRing
+	return m, nil
 }
 
 // CustomClaims 自定义 Claims
 type CustomClaims struct {
 	jwt.RegisteredClaims
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	Nickname string `json:"nickname,omitempty"`
-	IsAdmin  bool   `json:"is_admin"`
+	UserID      string   `json:"user_id"`
+	Username    string   `json:"username"`
+	Nickname    string   `json:"nickname,omitempty"`
+	IsAdmin     bool     `json:"is_admin"`
+	SessionID   string   `json:"sid,omitempty"`   // 会话ID，即 refresh token 家族ID
+	Roles       []string `json:"roles,omitempty"` // 角色
+	Permissions []string `json:"perms,omitempty"` // 权限码，签发时解析，角色变更在下次刷新 token 后生效
+	Stamp       string   `json:"sst,omitempty"`   // 签发时的用户安全戳
 }
 
-// GenerateToken 生成 token
-func (m *JwtManager) GenerateToken(userID, username, nickname string, isAdmin bool) (string, error) {
+// Subject 签发 token 的主体信息
+type Subject struct {
+	UserID      string
+	Username    string
+	Nickname    string
+	IsAdmin     bool
+	SessionID   string
+	Roles       []string
+	Permissions []string
+	Stamp       string
+}
+
+// SetDenylist 设置 access token 吊销列表，未设置时不做吊销校验
+func (m *JwtManager) SetDenylist(denylist Denylist) {
+	m.denylist = denylist
+}
+
+// SetStampValidator 设置安全戳校验器，未设置时不校验安全戳
+func (m *JwtManager) SetStampValidator(validator StampValidator) {
+	m.stampValidator = validator
+}
+
+// SetSessionResolver 设置会话解析器，未设置时只接受 Bearer token
+func (m *JwtManager) SetSessionResolver(resolver SessionResolver) {
+	m.sessionResolver = resolver
+}
+
+// SessionResolver 获取会话解析器，未启用会话模式时返回 nil
+func (m *JwtManager) SessionResolver() SessionResolver {
+	return m.sessionResolver
+}
+
+// Duration 获取 access token 有效期
+func (m *JwtManager) Duration() time.Duration {
+	return m.config.Duration
+}
+
+// RefreshDuration 获取 refresh token 有效期
+func (m *JwtManager) RefreshDuration() time.Duration {
+	return m.config.RefreshDuration
+}
+
+// IssueAccessToken 签发 access token，每个 token 带有唯一的 jti 用于吊销
+func (m *JwtManager) IssueAccessToken(subject Subject) (string, *CustomClaims, error) {
 	now := time.Now()
-	claims := CustomClaims{
+	claims := &CustomClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   subject.UserID,
 			Issuer:    m.config.Issuer,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(m.config.Duration)),
 			NotBefore: jwt.NewNumericDate(now),
 		},
-		UserID:   userID,
-		Username: username,
-		Nickname: nickname,
-		IsAdmin:  isAdmin,
+		UserID:      subject.UserID,
+		Username:    subject.Username,
+		Nickname:    subject.Nickname,
+		IsAdmin:     subject.IsAdmin,
+		SessionID:   subject.SessionID,
+		Roles:       subject.Roles,
+		Permissions: subject.Permissions,
+		Stamp:       subject.Stamp,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(m.config.SecretKey))
+	signed, err := m.sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
 }
 
-// ParseToken 解析 token
-func (m *JwtManager) ParseToken(tokenString string) (*CustomClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
+// sign 使用当前签名密钥签名，非对称算法会在头部写入 kid
+func (m *JwtManager) sign(claims jwt.Claims) (string, error) {
+	if m.keyRing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(m.config.SecretKey))
+	}
+
+	key := m.keyRing.SigningKey()
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// keyFunc 根据 token 头部选择验签密钥
+//
+// 算法必须与密钥本身绑定的算法一致，防止 alg 混淆攻击（如用公钥作为 HMAC 密钥伪造 token）。
+func (m *JwtManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	if m.keyRing == nil {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(m.config.SecretKey), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, fmt.Errorf("%w: token缺少kid", ErrUnknownKeyID)
+	}
+	key, err := m.keyRing.Lookup(kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("%w: kid=%s alg=%v", ErrKeyAlgMismatch, kid, token.Header["alg"])
+	}
+	return key.Public, nil
+}
+
+// GenerateToken 生成 token
+func (m *JwtManager) GenerateToken(userID, username, nickname string, isAdmin bool) (string, error) {
+	token, _, err := m.IssueAccessToken(Subject{
+		UserID:   userID,
+		Username: username,
+		Nickname: nickname,
+		IsAdmin:  isAdmin,
 	})
+	return token, err
+}
+
+// ParseToken 解析 token
+func (m *JwtManager) ParseToken(tokenString string) (*CustomClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, m.keyFunc)
 
 	if err != nil {
 		// 只检查过期错误，其他错误统一处理
@@ -70,13 +224,33 @@ func (m *JwtManager) ParseToken(tokenString string) (*CustomClaims, error) {
 		return nil, fmt.Errorf("token无效: %w", err)
 	}
 
-	if claims, ok := token.Claims.(*CustomClaims); ok && token.Valid {
+	// 一次性操作 token 带有 aud，不能当作 access token 使用
+	if claims, ok := token.Claims.(*CustomClaims); ok && token.Valid && len(claims.Audience) == 0 {
 		return claims, nil
 	}
 
 	return nil, fmt.Errorf("无效的token")
 }
 
+// IsRevoked 判断 token 是否已被吊销：jti 在吊销列表中，或安全戳与用户当前安全戳不一致
+func (m *JwtManager) IsRevoked(ctx context.Context, claims *CustomClaims) (bool, error) {
+	if m.denylist != nil && claims.ID != "" {
+		revoked, err := m.denylist.IsAccessTokenRevoked(ctx, claims.ID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	if m.stampValidator != nil {
+		valid, err := m.stampValidator.IsSecurityStampValid(ctx, claims.UserID, claims.Stamp)
+		if err != nil {
+			return false, err
+		}
+		return !valid, nil
+	}
+	return false, nil
+}
+
 // ValidateToken 验证 token
 func (m *JwtManager) ValidateToken(tokenString string) bool {
 	_, err := m.ParseToken(tokenString)
@@ -0,0 +1,312 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKeyPair 将私钥与公钥写入临时 PEM 文件
+func writeKeyPair(t *testing.T, dir, name string, private crypto.Signer) (string, string) {
+	t.Helper()
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privatePath := filepath.Join(dir, name+".key")
+	publicPath := filepath.Join(dir, name+".pub")
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return privatePath, publicPath
+}
+
+func TestAsymmetricRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name string
+		key  crypto.Signer
+		alg  string
+	}{
+		{name: "rsa", key: rsaKey, alg: "RS256"},
+		{name: "ecdsa", key: ecKey, alg: "ES256"},
+		{name: "ed25519", key: edKey, alg: "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privatePath, _ := writeKeyPair(t, dir, tt.name, tt.key)
+			m, err := NewJWTManager(Config{
+				Issuer: "test",
+				Keys:   []KeyConfig{{ID: tt.name, PrivateKeyFile: privatePath}},
+			})
+			if err != nil {
+				t.Fatalf("NewJWTManager() error = %v", err)
+			}
+
+			signed, _, err := m.IssueAccessToken(Subject{UserID: "u1", Username: "alice"})
+			if err != nil {
+				t.Fatalf("IssueAccessToken() error = %v", err)
+			}
+
+			token, _, err := jwt.NewParser().ParseUnverified(signed, &CustomClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token.Header["kid"] != tt.name || token.Header["alg"] != tt.alg {
+				t.Errorf("header = %v, want kid=%s alg=%s", token.Header, tt.name, tt.alg)
+			}
+
+			claims, err := m.ParseToken(signed)
+			if err != nil {
+				t.Fatalf("ParseToken() error = %v", err)
+			}
+			if claims.Username != "alice" || claims.ID == "" {
+				t.Errorf("claims = %+v", claims)
+			}
+
+			jwks := m.JWKS()
+			if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != tt.name || jwks.Keys[0].Alg != tt.alg {
+				t.Errorf("JWKS() = %+v", jwks)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	newKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	oldPrivate, oldPublic := writeKeyPair(t, dir, "old", oldKey)
+	newPrivate, _ := writeKeyPair(t, dir, "new", newKey)
+
+	before, err := NewJWTManager(Config{Keys: []KeyConfig{{ID: "old", PrivateKeyFile: oldPrivate}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, _, _ := before.IssueAccessToken(Subject{UserID: "u1"})
+
+	// 轮换后：新密钥签名，旧密钥只保留公钥用于验签
+	after, err := NewJWTManager(Config{
+		SigningKeyID: "new",
+		Keys: []KeyConfig{
+			{ID: "old", PublicKeyFile: oldPublic},
+			{ID: "new", PrivateKeyFile: newPrivate},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := after.ParseToken(oldToken); err != nil {
+		t.Errorf("token signed by previous key should still verify: %v", err)
+	}
+	if len(after.JWKS().Keys) != 2 {
+		t.Errorf("JWKS should publish both keys, got %d", len(after.JWKS().Keys))
+	}
+
+	// 只有公钥的密钥不能作为签名密钥
+	if _, err := NewJWTManager(Config{
+		SigningKeyID: "old",
+		Keys:         []KeyConfig{{ID: "old", PublicKeyFile: oldPublic}},
+	}); !errors.Is(err, ErrSigningKeyNotFound) {
+		t.Errorf("expected ErrSigningKeyNotFound, got %v", err)
+	}
+}
+
+func TestRejectsAlgorithmConfusion(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	privatePath, publicPath := writeKeyPair(t, dir, "rsa", rsaKey)
+
+	m, err := NewJWTManager(Config{Keys: []KeyConfig{{ID: "rsa", PrivateKeyFile: privatePath}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := &CustomClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+		Username:         "mallory",
+	}
+
+	// 使用公开的公钥作为 HMAC 密钥伪造 token
+	publicPEM, _ := os.ReadFile(publicPath)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	forged.Header["kid"] = "rsa"
+	forgedString, _ := forged.SignedString(publicPEM)
+	if _, err := m.ParseToken(forgedString); err == nil {
+		t.Error("HS256 token signed with the public key must be rejected")
+	}
+
+	// 未知 kid
+	unknown := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	unknown.Header["kid"] = "other"
+	unknownString, _ := unknown.SignedString(rsaKey)
+	if _, err := m.ParseToken(unknownString); err == nil {
+		t.Error("token with unknown kid must be rejected")
+	}
+
+	// 缺少 kid
+	noKid, _ := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(rsaKey)
+	if _, err := m.ParseToken(noKid); err == nil {
+		t.Error("token without kid must be rejected")
+	}
+}
+
+func TestHMACDefault(t *testing.T) {
+	m, err := NewJWTManager(Config{SecretKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Duration() != DefaultDuration || m.RefreshDuration() != DefaultRefreshDuration {
+		t.Errorf("unexpected default durations: %v, %v", m.Duration(), m.RefreshDuration())
+	}
+
+	signed, err := m.GenerateToken("u1", "alice", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ParseToken(signed); err != nil {
+		t.Errorf("ParseToken() error = %v", err)
+	}
+	if len(m.JWKS().Keys) != 0 {
+		t.Error("JWKS should be empty in HMAC mode")
+	}
+}
+
+func TestActionToken(t *testing.T) {
+	m, err := NewJWTManager(Config{SecretKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, issued, err := m.IssueActionToken(PurposeVerifyEmail, "42", "alice@example.com", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := m.ParseActionToken(signed, PurposeVerifyEmail)
+	if err != nil {
+		t.Fatalf("ParseActionToken() error = %v", err)
+	}
+	if claims.ID != issued.ID || claims.Subject != "42" || claims.Binding != "alice@example.com" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+
+	if _, err := m.ParseActionToken(signed, "other"); !errors.Is(err, ErrInvalidActionToken) {
+		t.Errorf("purpose mismatch: error = %v, want ErrInvalidActionToken", err)
+	}
+	if _, err := m.ParseToken(signed); err == nil {
+		t.Error("action token must not be accepted as access token")
+	}
+
+	access, err := m.GenerateToken("u1", "alice", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ParseActionToken(access, PurposeVerifyEmail); !errors.Is(err, ErrInvalidActionToken) {
+		t.Errorf("access token as action token: error = %v, want ErrInvalidActionToken", err)
+	}
+
+	expired, _, err := m.IssueActionToken(PurposeVerifyEmail, "42", "", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ParseActionToken(expired, PurposeVerifyEmail); !errors.Is(err, ErrActionTokenExpired) {
+		t.Errorf("expired token: error = %v, want ErrActionTokenExpired", err)
+	}
+}
+
+func TestClientToken(t *testing.T) {
+	m, err := NewJWTManager(Config{SecretKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, issued, err := m.IssueClientToken("client-1", []string{"data:read", "status:read"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := m.ParseClientToken(signed)
+	if err != nil {
+		t.Fatalf("ParseClientToken() error = %v", err)
+	}
+	if claims.ID != issued.ID || claims.ClientID != "client-1" || claims.Scope != "data:read status:read" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+
+	// 客户端 token 与用户 access token、操作 token 互不通用
+	if _, err := m.ParseToken(signed); err == nil {
+		t.Error("client token must not be accepted as user access token")
+	}
+	access, _ := m.GenerateToken("u1", "alice", "", false)
+	if _, err := m.ParseClientToken(access); !errors.Is(err, ErrInvalidClientToken) {
+		t.Errorf("user access token: error = %v, want ErrInvalidClientToken", err)
+	}
+	action, _, _ := m.IssueActionToken(PurposeVerifyEmail, "client-1", "", time.Hour)
+	if _, err := m.ParseClientToken(action); !errors.Is(err, ErrInvalidClientToken) {
+		t.Errorf("action token: error = %v, want ErrInvalidClientToken", err)
+	}
+
+	expired, _, _ := m.IssueClientToken("client-1", nil, -time.Minute)
+	if _, err := m.ParseClientToken(expired); !errors.Is(err, ErrClientTokenExpired) {
+		t.Errorf("expired token: error = %v, want ErrClientTokenExpired", err)
+	}
+}
+
+type stubStampValidator map[string]string
+
+func (v stubStampValidator) IsSecurityStampValid(_ context.Context, userID, stamp string) (bool, error) {
+	return v[userID] == stamp, nil
+}
+
+func TestSecurityStampRevocation(t *testing.T) {
+	m, err := NewJWTManager(Config{SecretKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stamps := stubStampValidator{"u1": "s1"}
+	m.SetStampValidator(stamps)
+
+	_, claims, err := m.IssueAccessToken(Subject{UserID: "u1", Stamp: "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked, err := m.IsRevoked(context.Background(), claims); err != nil || revoked {
+		t.Fatalf("IsRevoked() = %v, %v; want false", revoked, err)
+	}
+
+	// 修改密码后安全戳轮换，旧 token 失效
+	stamps["u1"] = "s2"
+	if revoked, _ := m.IsRevoked(context.Background(), claims); !revoked {
+		t.Error("token with stale security stamp must be revoked")
+	}
+}
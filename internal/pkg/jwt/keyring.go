@@ -0,0 +1,250 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrSigningKeyNotFound = errors.New("签名密钥不存在")
+	ErrUnknownKeyID       = errors.New("未知的密钥ID")
+	ErrKeyAlgMismatch     = errors.New("密钥类型与签名算法不匹配")
+)
+
+// KeyConfig 非对称密钥配置
+type KeyConfig struct {
+	ID             string `mapstructure:"id"`             // 密钥ID，写入 token 的 kid 头
+	Algorithm      string `mapstructure:"algorithm"`      // 签名算法，为空时根据密钥类型推断（RSA→RS256，P-256→ES256，Ed25519→EdDSA）
+	PrivateKeyFile string `mapstructure:"privateKeyFile"` // PEM 私钥文件，签名密钥必须配置
+	PublicKeyFile  string `mapstructure:"publicKeyFile"`  // PEM 公钥文件，仅用于验签的密钥可只配置公钥
+}
+
+// Key 密钥环中的一个密钥
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer    // 仅签名密钥需要
+	Public  crypto.PublicKey // 用于验签及 JWKS 发布
+}
+
+// KeyRing 密钥环：一个签名密钥，多个验签密钥
+//
+// 轮换密钥时先将新密钥加入密钥环并设为签名密钥，旧密钥保留到其签发的 token 全部过期后再移除。
+type KeyRing struct {
+	signing *Key
+	keys    map[string]*Key
+	order   []string
+}
+
+// LoadKeyRing 从 PEM 文件加载密钥环
+func LoadKeyRing(configs []KeyConfig, signingKeyID string) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[string]*Key, len(configs))}
+
+	for _, config := range configs {
+		if config.ID == "" {
+			return nil, errors.New("密钥ID不能为空")
+		}
+		if _, exists := ring.keys[config.ID]; exists {
+			return nil, fmt.Errorf("密钥ID重复: %s", config.ID)
+		}
+
+		key, err := loadKey(config)
+		if err != nil {
+			return nil, fmt.Errorf("加载密钥[%s]失败: %w", config.ID, err)
+		}
+		ring.keys[key.ID] = key
+		ring.order = append(ring.order, key.ID)
+	}
+
+	if signingKeyID == "" && len(ring.order) == 1 {
+		signingKeyID = ring.order[0]
+	}
+	signing, ok := ring.keys[signingKeyID]
+	if !ok || signing.Private == nil {
+		return nil, fmt.Errorf("%w: %q", ErrSigningKeyNotFound, signingKeyID)
+	}
+	ring.signing = signing
+
+	return ring, nil
+}
+
+// SigningKey 获取当前签名密钥
+func (r *KeyRing) SigningKey() *Key {
+	return r.signing
+}
+
+// Lookup 根据 kid 获取验签密钥
+func (r *KeyRing) Lookup(kid string) (*Key, error) {
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeyID, kid)
+	}
+	return key, nil
+}
+
+// Keys 按配置顺序返回所有密钥
+func (r *KeyRing) Keys() []*Key {
+	keys := make([]*Key, 0, len(r.order))
+	for _, id := range r.order {
+		keys = append(keys, r.keys[id])
+	}
+	return keys
+}
+
+// loadKey 加载单个密钥
+func loadKey(config KeyConfig) (*Key, error) {
+	key := &Key{ID: config.ID}
+
+	if config.PrivateKeyFile != "" {
+		private, err := readPrivateKey(config.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key.Private = private
+		key.Public = private.Public()
+	}
+
+	if config.PublicKeyFile != "" {
+		public, err := readPublicKey(config.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if key.Public != nil && !publicKeyEqual(key.Public, public) {
+			return nil, errors.New("公钥与私钥不匹配")
+		}
+		key.Public = public
+	}
+
+	if key.Public == nil {
+		return nil, errors.New("未配置私钥或公钥文件")
+	}
+
+	method, err := signingMethodFor(key.Public, config.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	key.Method = method
+	return key, nil
+}
+
+// signingMethodFor 根据公钥类型确定签名算法，并校验显式配置的算法是否与密钥类型匹配
+func signingMethodFor(public crypto.PublicKey, algorithm string) (jwt.SigningMethod, error) {
+	var allowed []jwt.SigningMethod
+
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		allowed = []jwt.SigningMethod{
+			jwt.SigningMethodRS256, jwt.SigningMethodRS384, jwt.SigningMethodRS512,
+			jwt.SigningMethodPS256, jwt.SigningMethodPS384, jwt.SigningMethodPS512,
+		}
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			allowed = []jwt.SigningMethod{jwt.SigningMethodES256}
+		case elliptic.P384():
+			allowed = []jwt.SigningMethod{jwt.SigningMethodES384}
+		case elliptic.P521():
+			allowed = []jwt.SigningMethod{jwt.SigningMethodES512}
+		default:
+			return nil, fmt.Errorf("不支持的椭圆曲线: %s", pub.Curve.Params().Name)
+		}
+	case ed25519.PublicKey:
+		allowed = []jwt.SigningMethod{jwt.SigningMethodEdDSA}
+	default:
+		return nil, fmt.Errorf("不支持的密钥类型: %T", public)
+	}
+
+	if algorithm == "" {
+		return allowed[0], nil
+	}
+	for _, method := range allowed {
+		if method.Alg() == algorithm {
+			return method, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrKeyAlgMismatch, algorithm)
+}
+
+// readPEM 读取 PEM 文件的第一个块
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取密钥文件失败: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("密钥文件不是有效的PEM格式: %s", path)
+	}
+	return block, nil
+}
+
+// readPrivateKey 读取 PKCS#8、PKCS#1（RSA）或 SEC1（EC）格式的私钥
+func readPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析私钥失败: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("不支持的私钥类型: %T", key)
+	}
+	return signer, nil
+}
+
+// readPublicKey 读取 PKIX、PKCS#1（RSA）格式的公钥或 X.509 证书中的公钥
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("解析证书失败: %w", err)
+		}
+		return cert.PublicKey, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析公钥失败: %w", err)
+	}
+	return key, nil
+}
+
+// publicKeyEqual 比较两个公钥是否相同
+func publicKeyEqual(a, b crypto.PublicKey) bool {
+	type equaler interface {
+		Equal(crypto.PublicKey) bool
+	}
+	if e, ok := a.(equaler); ok {
+		return e.Equal(b)
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileSender 将邮件写入目录中的 .eml 文件，用于开发与测试环境
+type fileSender struct {
+	from string
+	dir  string
+}
+
+// NewFileSender 创建写文件的邮件发送器
+func NewFileSender(from, dir string) (Sender, error) {
+	if dir == "" {
+		dir = "./logs/mail"
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("创建邮件目录失败: %w", err)
+	}
+	return &fileSender{from: from, dir: dir}, nil
+}
+
+func (s *fileSender) Send(_ context.Context, msg *Message) error {
+	to, err := recipients(msg)
+	if err != nil {
+		return err
+	}
+	data, err := build(s.from, msg)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), sanitize(to[0].Address))
+	return os.WriteFile(filepath.Join(s.dir, name), data, 0o640)
+}
+
+// sanitize 将邮箱地址转换为可用作文件名的字符串
+func sanitize(addr string) string {
+	out := make([]rune, 0, len(addr))
+	for _, r := range addr {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '@':
+			out = append(out, r)
+		default:
+			out = append(out, '_')
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// logSender 只将邮件内容写入日志，不实际发送，用于本地开发
+type logSender struct {
+	from   string
+	logger *zap.Logger
+}
+
+// NewLogSender 创建写日志的邮件发送器
+func NewLogSender(from string, logger *zap.Logger) Sender {
+	return &logSender{from: from, logger: logger}
+}
+
+func (s *logSender) Send(_ context.Context, msg *Message) error {
+	if _, err := recipients(msg); err != nil {
+		return err
+	}
+
+	s.logger.Info("发送邮件（仅记录日志）",
+		zap.String("from", s.from),
+		zap.Strings("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.TextBody),
+	)
+	return nil
+}
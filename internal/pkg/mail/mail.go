@@ -0,0 +1,191 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 发送器类型
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+var (
+	// ErrNoRecipients 邮件没有收件人
+	ErrNoRecipients = errors.New("邮件没有收件人")
+	// ErrUnsupportedDriver 不支持的发送器类型
+	ErrUnsupportedDriver = errors.New("不支持的邮件发送器")
+)
+
+// Message 待发送的邮件，TextBody 与 HTMLBody 至少设置一个，都设置时以 multipart/alternative 发送
+type Message struct {
+	To       []string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Sender 邮件发送器
+type Sender interface {
+	// Send 发送邮件
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Config 邮件发送配置
+type Config struct {
+	Driver string     // smtp、file 或 log（默认）
+	From   string     // 发件人，如 "Go Web Example <noreply@example.com>"
+	SMTP   SMTPConfig // smtp 发送器配置
+	Dir    string     // file 发送器的输出目录，默认 ./logs/mail
+}
+
+// New 根据配置创建邮件发送器
+func New(config Config, logger *zap.Logger) (Sender, error) {
+	if config.From == "" {
+		config.From = "noreply@localhost"
+	}
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, fmt.Errorf("发件人地址无效: %w", err)
+	}
+
+	switch strings.ToLower(config.Driver) {
+	case DriverSMTP:
+		return NewSMTPSender(config.From, config.SMTP)
+	case DriverFile:
+		return NewFileSender(config.From, config.Dir)
+	case DriverLog, "":
+		return NewLogSender(config.From, logger), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, config.Driver)
+	}
+}
+
+// recipients 校验并解析收件人地址
+func recipients(msg *Message) ([]*mail.Address, error) {
+	if len(msg.To) == 0 {
+		return nil, ErrNoRecipients
+	}
+
+	addrs := make([]*mail.Address, 0, len(msg.To))
+	for _, to := range msg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return nil, fmt.Errorf("收件人地址无效 %q: %w", to, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// build 生成 RFC 5322 格式的邮件内容
+func build(from string, msg *Message) ([]byte, error) {
+	to, err := recipients(msg)
+	if err != nil {
+		return nil, err
+	}
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("发件人地址无效: %w", err)
+	}
+
+	var buf bytes.Buffer
+	header := textproto.MIMEHeader{}
+	header.Set("From", fromAddr.String())
+	// 使用解析后的地址重新编码，防止邮件头注入
+	toHeader := make([]string, 0, len(to))
+	for _, addr := range to {
+		toHeader = append(toHeader, addr.String())
+	}
+	header.Set("To", strings.Join(toHeader, ", "))
+	// 主题经过编码且去除换行
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", strings.NewReplacer("\r", "", "\n", "").Replace(msg.Subject)))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-ID", messageID(fromAddr.Address))
+	header.Set("MIME-Version", "1.0")
+
+	if msg.TextBody != "" && msg.HTMLBody != "" {
+		var parts bytes.Buffer
+		writer := multipart.NewWriter(&parts)
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain; charset=utf-8", msg.TextBody},
+			{"text/html; charset=utf-8", msg.HTMLBody},
+		} {
+			w, err := writer.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.contentType},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := writeQuotedPrintable(w, part.body); err != nil {
+				return nil, err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+
+		header.Set("Content-Type", "multipart/alternative; boundary="+writer.Boundary())
+		writeHeader(&buf, header)
+		buf.Write(parts.Bytes())
+		return buf.Bytes(), nil
+	}
+
+	contentType, body := "text/plain; charset=utf-8", msg.TextBody
+	if msg.HTMLBody != "" {
+		contentType, body = "text/html; charset=utf-8", msg.HTMLBody
+	}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	writeHeader(&buf, header)
+	if err := writeQuotedPrintable(&buf, body); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeHeader 按固定顺序写入邮件头
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, key := range []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type", "Content-Transfer-Encoding"} {
+		if value := header.Get(key); value != "" {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// messageID 生成唯一的 Message-ID
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b), domain)
+}
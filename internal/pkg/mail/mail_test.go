@@ -0,0 +1,75 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestBuild(t *testing.T) {
+	data, err := build("Example <noreply@example.com>", &Message{
+		To:       []string{"alice@example.com"},
+		Subject:  "验证邮箱\r\nBcc: evil@example.com",
+		TextBody: "hello",
+		HTMLBody: "<p>hello</p>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	if parsed.Header.Get("Bcc") != "" {
+		t.Error("subject newline must not inject headers")
+	}
+	if got := parsed.Header.Get("To"); got != "<alice@example.com>" {
+		t.Errorf("To = %q", got)
+	}
+	if !strings.HasPrefix(parsed.Header.Get("Content-Type"), "multipart/alternative;") {
+		t.Errorf("Content-Type = %q", parsed.Header.Get("Content-Type"))
+	}
+}
+
+func TestRecipients(t *testing.T) {
+	if _, err := recipients(&Message{}); !errors.Is(err, ErrNoRecipients) {
+		t.Errorf("error = %v, want ErrNoRecipients", err)
+	}
+	if _, err := recipients(&Message{To: []string{"a@example.com\r\nBcc: b@example.com"}}); err == nil {
+		t.Error("address with CRLF must be rejected")
+	}
+}
+
+func TestFileSender(t *testing.T) {
+	dir := t.TempDir()
+	sender, err := New(Config{Driver: DriverFile, From: "noreply@example.com", Dir: dir}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sender.Send(context.Background(), &Message{To: []string{"bob@example.com"}, Subject: "hi", TextBody: "body"}); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*bob@example.com.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected one .eml file, got %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(data), "Subject: hi") {
+		t.Errorf("unexpected message:\n%s", data)
+	}
+}
+
+func TestNewRejectsUnknownDriver(t *testing.T) {
+	if _, err := New(Config{Driver: "pigeon"}, zap.NewNop()); !errors.Is(err, ErrUnsupportedDriver) {
+		t.Errorf("error = %v, want ErrUnsupportedDriver", err)
+	}
+}
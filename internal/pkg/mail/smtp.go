@@ -0,0 +1,148 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP 连接加密方式
+const (
+	SecuritySTARTTLS = "starttls" // 明文连接后升级为 TLS（默认，通常为 587 端口）
+	SecurityTLS      = "tls"      // 隐式 TLS（通常为 465 端口）
+	SecurityNone     = "none"     // 不加密，仅用于本地调试（如 MailHog）
+)
+
+// ErrSTARTTLSUnsupported SMTP 服务器不支持 STARTTLS
+var ErrSTARTTLSUnsupported = errors.New("SMTP服务器不支持STARTTLS")
+
+// SMTPConfig SMTP 发送器配置
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Security string        // starttls（默认）、tls 或 none
+	Timeout  time.Duration // 连接与发送超时，默认 10s
+}
+
+// smtpSender 通过 SMTP 服务器发送邮件
+type smtpSender struct {
+	from   string
+	config SMTPConfig
+}
+
+// NewSMTPSender 创建 SMTP 邮件发送器
+func NewSMTPSender(from string, config SMTPConfig) (Sender, error) {
+	if config.Host == "" {
+		return nil, errors.New("SMTP服务器地址未配置")
+	}
+	if config.Port <= 0 {
+		config.Port = 587
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	config.Security = strings.ToLower(config.Security)
+	switch config.Security {
+	case "":
+		config.Security = SecuritySTARTTLS
+	case SecuritySTARTTLS, SecurityTLS, SecurityNone:
+	default:
+		return nil, fmt.Errorf("不支持的SMTP加密方式: %s", config.Security)
+	}
+
+	return &smtpSender{from: from, config: config}, nil
+}
+
+func (s *smtpSender) Send(ctx context.Context, msg *Message) error {
+	to, err := recipients(msg)
+	if err != nil {
+		return err
+	}
+	data, err := build(s.from, msg)
+	if err != nil {
+		return err
+	}
+	fromAddr, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("连接SMTP服务器失败: %w", err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(s.config.Timeout)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	tlsConfig := &tls.Config{ServerName: s.config.Host, MinVersion: tls.VersionTLS12}
+	if s.config.Security == SecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return ErrSTARTTLSUnsupported
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if s.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)); err != nil {
+			return fmt.Errorf("SMTP认证失败: %w", err)
+		}
+	}
+
+	if err := client.Mail(fromAddr.Address); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := client.Rcpt(addr.Address); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// dial 建立到 SMTP 服务器的连接，隐式 TLS 模式下直接进行 TLS 握手
+func (s *smtpSender) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	dialer := &net.Dialer{Timeout: s.config.Timeout}
+
+	if s.config.Security == SecurityTLS {
+		tlsDialer := &tls.Dialer{
+			NetDialer: dialer,
+			Config:    &tls.Config{ServerName: s.config.Host, MinVersion: tls.VersionTLS12},
+		}
+		return tlsDialer.DialContext(ctx, "tcp", addr)
+	}
+	return dialer.DialContext(ctx, "tcp", addr)
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HTTPMetrics HTTP 请求 RED 指标（请求数、错误数、耗时）
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+// NewHTTPMetrics 创建 HTTP 请求指标
+func NewHTTPMetrics(namespace string, buckets []float64) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP 请求总数",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP 请求处理耗时（秒）",
+			Buckets:   buckets,
+		}, []string{"method", "route", "status"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "正在处理中的 HTTP 请求数",
+		}, []string{"method", "route"}),
+	}
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (m *HTTPMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.duration.Describe(ch)
+	m.inFlight.Describe(ch)
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (m *HTTPMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.duration.Collect(ch)
+	m.inFlight.Collect(ch)
+}
+
+// InFlight 获取指定路由的处理中请求计数器
+func (m *HTTPMetrics) InFlight(method, route string) prometheus.Gauge {
+	return m.inFlight.WithLabelValues(method, route)
+}
+
+// Observe 记录一次已完成的请求
+func (m *HTTPMetrics) Observe(method, route, status string, seconds float64) {
+	m.requests.WithLabelValues(method, route, status).Inc()
+	m.duration.WithLabelValues(method, route, status).Observe(seconds)
+}
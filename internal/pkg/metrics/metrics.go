@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"goWebExample/internal/configs"
+)
+
+var (
+	// registry 应用级指标注册表，不使用 prometheus 默认的全局注册表，避免第三方库指标混入
+	registry = prometheus.NewRegistry()
+
+	mu          sync.RWMutex
+	enabled     bool
+	path        = "/metrics"
+	httpMetrics *HTTPMetrics
+)
+
+func init() {
+	// 注册 Go 运行时与进程指标
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Init 根据配置初始化指标子系统，返回 HTTP 请求指标；重复调用返回同一实例
+func Init(config *configs.Metrics) *HTTPMetrics {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if httpMetrics != nil {
+		return httpMetrics
+	}
+
+	httpMetrics = NewHTTPMetrics(config.Namespace, config.GetBuckets())
+	registry.MustRegister(httpMetrics)
+
+	path = config.GetPath()
+	enabled = true
+	return httpMetrics
+}
+
+// Enabled 指标子系统是否已启用
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return enabled
+}
+
+// Path 获取指标暴露路径
+func Path() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return path
+}
+
+// Registry 获取应用级指标注册表
+func Registry() *prometheus.Registry {
+	return registry
+}
+
+// Register 注册一个指标收集器，labels 会作为常量标签附加到该收集器的所有指标上
+func Register(collector prometheus.Collector, labels prometheus.Labels) error {
+	if len(labels) == 0 {
+		return registry.Register(collector)
+	}
+	return prometheus.WrapRegistererWith(labels, registry).Register(collector)
+}
+
+// Handler 返回用于暴露指标的 HTTP 处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		// 响应压缩交给 Gzip 中间件处理，避免重复压缩
+		DisableCompression: true,
+	})
+}
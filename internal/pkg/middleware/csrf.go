@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"goWebExample/api/rest/response"
+	"goWebExample/internal/configs"
+	"goWebExample/internal/pkg/csrf"
+)
+
+// OpenAPIPathPrefix OpenAPI 路由前缀，使用 API Key 签名或 OAuth2 Bearer token 认证，不受 CSRF 校验
+const OpenAPIPathPrefix = "/openapi/"
+
+// csrfKey 上下文中保存 CSRF 配置与签名器的键，供 token 签发接口使用
+const csrfKey = "csrf"
+
+// ErrCSRFDisabled 未启用 CSRF 防护
+var ErrCSRFDisabled = errors.New("未启用CSRF防护")
+
+// ValidateCSRFConfig 校验 CSRF 配置：启用 CSRF 防护时配置的签名密钥必须有效；release 模式下必须配置签名密钥，
+// 否则每个实例使用各自的随机密钥，token 在实例之间与重启后失效
+func ValidateCSRFConfig(config *configs.CSRFConfig) error {
+	if config == nil || !config.Enable {
+		return nil
+	}
+	if config.Secret != "" {
+		if _, err := csrf.NewSigner([]byte(config.Secret)); err != nil {
+			return fmt.Errorf("csrf.secret 无效: %w", err)
+		}
+		return nil
+	}
+	if gin.Mode() == gin.ReleaseMode {
+		return errors.New("release 模式下启用CSRF防护必须配置 csrf.secret")
+	}
+	return nil
+}
+
+// CSRFToken CSRF token 签发结果，前端需在非 GET 请求中通过 HeaderName 指定的请求头提交 Token
+type CSRFToken struct {
+	Token      string `json:"token"`
+	HeaderName string `json:"headerName"`
+}
+
+// csrfState CSRF 中间件的运行时状态
+type csrfState struct {
+	signer  *csrf.Signer
+	config  *configs.CSRFConfig
+	session configs.SessionConfig
+}
+
+// CSRFMiddleware CSRF 防护中间件（签名双提交 Cookie）
+//
+// 对非安全方法的请求：
+//  1. Origin（缺失时使用 Referer）必须与请求同源或在 CORS 的 AllowedOrigins 中（通配符 * 不计入）；
+//  2. 携带会话 Cookie 且未使用 Bearer token 时，请求头中的 token 必须与 CSRF Cookie 一致，且由服务端为当前会话签发。
+//
+// Bearer token 与 OpenAPI 请求不会由浏览器自动携带凭证，因此豁免 token 校验。
+func CSRFMiddleware(config *configs.AllConfig, logger *zap.Logger) gin.HandlerFunc {
+	state := &csrfState{
+		signer:  newCSRFSigner(config.CSRF, logger),
+		config:  config.CSRF,
+		session: config.User.Session,
+	}
+
+	var allowedOrigins []string
+	if config.Cors != nil && config.Cors.Enable {
+		for _, origin := range config.Cors.AllowedOrigins {
+			if origin != "*" {
+				allowedOrigins = append(allowedOrigins, strings.TrimSuffix(origin, "/"))
+			}
+		}
+	}
+	exemptPaths := append([]string{OpenAPIPathPrefix}, config.CSRF.ExemptPaths...)
+
+	return func(c *gin.Context) {
+		c.Set(csrfKey, state)
+
+		if isSafeMethod(c.Request.Method) || hasAnyPrefix(c.Request.URL.Path, exemptPaths) {
+			c.Next()
+			return
+		}
+
+		if origin, ok := requestOrigin(c); ok && !isSameOrigin(origin, c.Request.Host) && !contains(allowedOrigins, origin) {
+			logger.Warn("拒绝跨站请求", zap.String("origin", origin), zap.String("path", c.Request.URL.Path))
+			c.JSON(http.StatusForbidden, response.Fail(http.StatusForbidden, "跨站请求被拒绝"))
+			c.Abort()
+			return
+		}
+
+		// Bearer token 需由脚本显式设置，跨站请求无法携带
+		if c.GetHeader("Authorization") != "" {
+			c.Next()
+			return
+		}
+		// 未携带会话 Cookie 的请求无法以用户身份认证
+		session, err := c.Cookie(state.session.GetCookieName())
+		if err != nil || session == "" {
+			c.Next()
+			return
+		}
+
+		token := c.GetHeader(state.config.GetHeaderName())
+		cookie, _ := c.Cookie(state.config.GetCookieName())
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(cookie)) != 1 || !state.signer.Verify(token, session) {
+			logger.Warn("CSRF token校验失败", zap.String("path", c.Request.URL.Path))
+			c.JSON(http.StatusForbidden, response.Fail(http.StatusForbidden, "CSRF token无效，请刷新后重试"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// IssueCSRFToken 为当前会话签发 CSRF token 并写入 CSRF Cookie，登录或会话变化后需重新获取
+func IssueCSRFToken(c *gin.Context) (*CSRFToken, error) {
+	value, exists := c.Get(csrfKey)
+	state, ok := value.(*csrfState)
+	if !exists || !ok {
+		return nil, ErrCSRFDisabled
+	}
+
+	session, _ := c.Cookie(state.session.GetCookieName())
+	token, err := state.signer.Issue(session)
+	if err != nil {
+		return nil, err
+	}
+
+	// 前端需读取该 Cookie 或响应体中的 token，因此不设置 HttpOnly
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     state.config.GetCookieName(),
+		Value:    token,
+		Path:     state.session.GetCookiePath(),
+		Domain:   state.session.CookieDomain,
+		Secure:   state.session.Secure,
+		SameSite: sameSiteMode(state.session.SameSite),
+	})
+	return &CSRFToken{Token: token, HeaderName: state.config.GetHeaderName()}, nil
+}
+
+// newCSRFSigner 创建 token 签名器，未配置时使用随机密钥；配置的密钥无效时 panic，启动时已由 ValidateCSRFConfig 校验
+func newCSRFSigner(config *configs.CSRFConfig, logger *zap.Logger) *csrf.Signer {
+	if config.Secret != "" {
+		signer, err := csrf.NewSigner([]byte(config.Secret))
+		if err != nil {
+			panic(fmt.Errorf("csrf.secret 无效: %w", err))
+		}
+		return signer
+	}
+	logger.Warn("未配置CSRF签名密钥，使用随机密钥，重启或多实例部署时token将失效")
+
+	signer, err := csrf.NewRandomSigner()
+	if err != nil {
+		panic(err)
+	}
+	return signer
+}
+
+// requestOrigin 获取请求来源，优先使用 Origin，缺失时从 Referer 中提取
+func requestOrigin(c *gin.Context) (string, bool) {
+	if origin := c.GetHeader("Origin"); origin != "" {
+		return origin, true
+	}
+	referer := c.GetHeader("Referer")
+	if referer == "" {
+		return "", false
+	}
+	u, err := url.Parse(referer)
+	if err != nil || u.Host == "" {
+		// 无法解析的 Referer 视为跨站
+		return referer, true
+	}
+	return u.Scheme + "://" + u.Host, true
+}
+
+// isSameOrigin 判断来源是否与请求的 Host 相同，"null" 等无法解析的来源视为跨站
+func isSameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Host, host)
+}
+
+// isSafeMethod 判断是否为不修改状态的安全方法
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// hasAnyPrefix 检查路径是否以任一前缀开头
+func hasAnyPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"goWebExample/internal/pkg/jwt"
 	"net/http"
 	"strings"
@@ -11,38 +12,112 @@ import (
 	"goWebExample/api/rest/response"
 )
 
-// JWTAuthMiddleware JWT认证中间件
+// ClaimsKey 上下文中保存 JWT Claims 的键
+const ClaimsKey = "claims"
+
+// AuthMethodKey 上下文中保存认证方式的键，取值为 AuthMethodBearer 或 AuthMethodSession
+const AuthMethodKey = "authMethod"
+
+// 认证方式
+const (
+	AuthMethodBearer  = "bearer"
+	AuthMethodSession = "session"
+)
+
+// GetClaims 从上下文中获取 JWTAuthMiddleware 解析出的 Claims
+func GetClaims(c *gin.Context) (*jwt.CustomClaims, bool) {
+	value, exists := c.Get(ClaimsKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*jwt.CustomClaims)
+	return claims, ok
+}
+
+// GetAuthMethod 从上下文中获取 JWTAuthMiddleware 使用的认证方式
+func GetAuthMethod(c *gin.Context) string {
+	return c.GetString(AuthMethodKey)
+}
+
+// JWTAuthMiddleware JWT认证中间件，优先使用 Bearer token，未携带时尝试会话 Cookie（启用会话模式时）
 func JWTAuthMiddleware(jwtManager *jwt.JwtManager, logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		logger.Info("JWT认证中间件", zap.String("Method", c.Request.Method), zap.String("Path", c.Request.URL.Path))
+		var (
+			claims *jwt.CustomClaims
+			method string
+			err    error
+		)
+
 		// 从 Header 中获取 token
 		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		if authHeader != "" {
+			// 检查 token 格式
+			parts := strings.SplitN(authHeader, " ", 2)
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				logger.Warn("token格式错误")
+				c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "token格式错误"))
+				c.Abort()
+				return
+			}
+
+			// 解析 token
+			claims, err = jwtManager.ParseToken(parts[1])
+			if err != nil {
+				logger.Warn("token无效", zap.Error(err))
+				c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, err.Error()))
+				c.Abort()
+				return
+			}
+			method = AuthMethodBearer
+		} else if resolver := jwtManager.SessionResolver(); resolver != nil {
+			cookie, cookieErr := c.Cookie(resolver.SessionCookieName())
+			if cookieErr != nil || cookie == "" {
+				logger.Warn("请求未携带token或会话")
+				c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "请先登录"))
+				c.Abort()
+				return
+			}
+
+			claims, err = resolver.ResolveSession(c.Request.Context(), cookie)
+			if err != nil && !errors.Is(err, jwt.ErrInvalidSession) {
+				logger.Error("解析会话失败", zap.Error(err))
+				c.JSON(http.StatusServiceUnavailable, response.Fail(http.StatusServiceUnavailable, "认证服务暂不可用"))
+				c.Abort()
+				return
+			}
+			if err != nil {
+				logger.Warn("会话无效", zap.Error(err))
+				c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "会话已失效，请重新登录"))
+				c.Abort()
+				return
+			}
+			method = AuthMethodSession
+		} else {
 			logger.Warn("请求未携带token")
 			c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "请先登录"))
 			c.Abort()
 			return
 		}
 
-		// 检查 token 格式
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			logger.Warn("token格式错误")
-			c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "token格式错误"))
+		// 检查 token 是否已被吊销（如已退出登录）
+		revoked, err := jwtManager.IsRevoked(c.Request.Context(), claims)
+		if err != nil {
+			logger.Error("校验token吊销状态失败", zap.Error(err))
+			c.JSON(http.StatusServiceUnavailable, response.Fail(http.StatusServiceUnavailable, "认证服务暂不可用"))
 			c.Abort()
 			return
 		}
-
-		// 解析 token
-		claims, err := jwtManager.ParseToken(parts[1])
-		if err != nil {
-			logger.Warn("token无效", zap.Error(err))
-			c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, err.Error()))
+		if revoked {
+			logger.Warn("token已被吊销", zap.String("jti", claims.ID))
+			c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "token已失效，请重新登录"))
 			c.Abort()
 			return
 		}
 
 		// 将用户信息保存到上下文
+		c.Set(ClaimsKey, claims)
+		c.Set(AuthMethodKey, method)
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("isAdmin", claims.IsAdmin)
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"goWebExample/internal/pkg/metrics"
+)
+
+// unmatchedRoute 未匹配到路由时使用的标签值，避免以原始路径作为标签导致基数爆炸
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware Prometheus 指标中间件，按路由模板、方法和状态码统计请求
+func MetricsMiddleware(m *metrics.HTTPMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		// 使用路由模板（如 /api/users/:userId）而不是实际路径作为标签
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+
+		inFlight := m.InFlight(method, route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		c.Next()
+
+		m.Observe(method, route, strconv.Itoa(c.Writer.Status()), time.Since(start).Seconds())
+	}
+}
@@ -1,17 +1,44 @@
 package middleware
 
 import (
+	"bytes"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"io"
 	"net/http"
+	"strings"
 
 	"goWebExample/api/rest/response"
 	"goWebExample/internal/configs"
+	"goWebExample/internal/repository/apikey"
 	"goWebExample/internal/service"
 	apikeysvc "goWebExample/internal/service/apikey"
+	"goWebExample/pkg/apisign"
 )
 
-// OpenAPIAuthMiddleware 创建OpenAPI认证中间件
+// OAuthPathPrefix OAuth2 端点（token、内省、吊销）自行认证客户端，不经过 OpenAPI 认证
+const OAuthPathPrefix = "/openapi/oauth/"
+
+// maxSignedBodySize 签名请求体的最大字节数，请求体需完整读入内存计算摘要
+const maxSignedBodySize = 10 << 20
+
+// 读取签名请求体的错误
+var (
+	errReadBody     = errors.New("读取请求体失败")
+	errBodyTooLarge = errors.New("请求体过大")
+	errInvalidQuery = errors.New("查询字符串格式错误")
+)
+
+// OpenAPI 认证通过后写入上下文的键
+const (
+	ClientIDKey     = "clientID"
+	ClientScopesKey = "clientScopes"
+	// clientKeyKey 认证通过的 *apikey.APIKey，供配额中间件使用
+	clientKeyKey = "clientKey"
+)
+
+// OpenAPIAuthMiddleware 创建OpenAPI认证中间件，支持 OAuth2 Bearer token 与 HMAC 签名两种方式，认证通过后检查密钥的路由权限
 func OpenAPIAuthMiddleware(config *configs.OpenAPIConfig, logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		logger.Info("OpenAPI认证中间件", zap.String("Method", c.Request.Method), zap.String("Path", c.Request.URL.Path))
@@ -24,6 +51,11 @@ func OpenAPIAuthMiddleware(config *configs.OpenAPIConfig, logger *zap.Logger) gi
 			return
 		}
 
+		if strings.HasPrefix(c.Request.URL.Path, OAuthPathPrefix) {
+			c.Next()
+			return
+		}
+
 		// 从服务注册表获取API密钥服务
 		apiKeySvc, ok := service.GetRegistry().Get(apikeysvc.ServiceName).(apikeysvc.ServiceAPIKey)
 		if !ok || apiKeySvc == nil {
@@ -33,18 +65,120 @@ func OpenAPIAuthMiddleware(config *configs.OpenAPIConfig, logger *zap.Logger) gi
 			return
 		}
 
-		// 从请求中获取apikey和sign
-		apiKeyStr := c.GetHeader("X-API-Key")
-		sign := c.GetHeader("X-API-Sign")
-		timestamp := c.GetHeader("X-API-Timestamp")
-		// 验证签名
-		if err := apiKeySvc.VerifySign(apiKeyStr, sign, timestamp); err != nil {
-			c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, err.Error()))
+		// OAuth2 Bearer token
+		var key *apikey.APIKey
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			parts := strings.SplitN(authHeader, " ", 2)
+			if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+				c.Header("WWW-Authenticate", `Bearer error="invalid_request"`)
+				c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "token格式错误"))
+				c.Abort()
+				return
+			}
+
+			claims, tokenKey, err := apiKeySvc.AuthenticateAccessToken(c.Request.Context(), parts[1])
+			if err != nil {
+				if !errors.Is(err, apikeysvc.ErrInvalidAccessToken) {
+					logger.Error("校验access token失败", zap.Error(err))
+				}
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+				c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, apikeysvc.ErrInvalidAccessToken.Error()))
+				c.Abort()
+				return
+			}
+
+			key = tokenKey
+			c.Set(ClientScopesKey, claims.Scopes())
+		} else {
+			req, status, err := signedRequest(c)
+			if err != nil {
+				c.JSON(status, response.Fail(status, err.Error()))
+				c.Abort()
+				return
+			}
+			// 验证签名
+			signKey, err := apiKeySvc.VerifySign(c.Request.Context(), req)
+			if err != nil {
+				status, code := signErrorCode(err)
+				if code == http.StatusInternalServerError {
+					logger.Error("验证签名失败", zap.Error(err))
+					c.JSON(status, response.Fail(code, "验证签名失败"))
+				} else {
+					c.JSON(status, response.Fail(code, err.Error()))
+				}
+				c.Abort()
+				return
+			}
+			key = signKey
+		}
+
+		// 检查密钥是否允许访问该路由
+		if !key.AllowsRoute(c.Request.Method, c.Request.URL.Path) {
+			logger.Warn("API密钥无权访问该接口", zap.String("apiKey", key.APIKey),
+				zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+			c.JSON(http.StatusForbidden, response.Fail(response.CodeAPIKeyForbidden, apikeysvc.ErrRouteNotAllowed.Error()))
 			c.Abort()
 			return
 		}
+
 		// 验证通过，继续处理请求
-		logger.Info("OpenAPI认证成功", zap.String("apiKey", apiKeyStr))
+		c.Set(ClientIDKey, key.APIKey)
+		c.Set(clientKeyKey, key)
+		logger.Info("OpenAPI认证成功", zap.String("clientId", key.APIKey))
 		c.Next()
 	}
 }
+
+// signedRequest 从请求中读取签名参数并构造规范请求，读取后还原请求体供后续处理器使用
+func signedRequest(c *gin.Context) (*apikeysvc.SignedRequest, int, error) {
+	req := &apikeysvc.SignedRequest{
+		APIKey:    c.GetHeader(apisign.HeaderAPIKey),
+		Sign:      c.GetHeader(apisign.HeaderSign),
+		Timestamp: c.GetHeader(apisign.HeaderTimestamp),
+		Nonce:     c.GetHeader(apisign.HeaderNonce),
+	}
+	if req.Nonce == "" {
+		return req, 0, nil
+	}
+
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(c.Request.Body, maxSignedBodySize+1))
+		if err != nil {
+			return nil, http.StatusBadRequest, errReadBody
+		}
+		if len(body) > maxSignedBodySize {
+			return nil, http.StatusRequestEntityTooLarge, errBodyTooLarge
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	canonical, err := apisign.CanonicalHTTPRequest(c.Request, body, req.Timestamp, req.Nonce)
+	if err != nil {
+		return nil, http.StatusBadRequest, errInvalidQuery
+	}
+	req.Canonical = canonical
+	return req, 0, nil
+}
+
+// signErrorCode 将签名认证错误映射为 HTTP 状态码与业务错误码
+func signErrorCode(err error) (int, int) {
+	switch {
+	case errors.Is(err, apikeysvc.ErrKeyDisabled):
+		return http.StatusUnauthorized, response.CodeAPIKeyDisabled
+	case errors.Is(err, apikeysvc.ErrKeyExpired):
+		return http.StatusUnauthorized, response.CodeAPIKeyExpired
+	case errors.Is(err, apikeysvc.ErrTimestampExpired):
+		return http.StatusUnauthorized, response.CodeTimestampExpired
+	case errors.Is(err, apikeysvc.ErrReplayedRequest):
+		return http.StatusUnauthorized, response.CodeRequestReplayed
+	case errors.Is(err, apikeysvc.ErrMissingSignParams),
+		errors.Is(err, apikeysvc.ErrInvalidNonce),
+		errors.Is(err, apikeysvc.ErrInvalidTimestamp),
+		errors.Is(err, apikeysvc.ErrInvalidSignature):
+		return http.StatusUnauthorized, response.CodeAPIKeyInvalid
+	default:
+		return http.StatusInternalServerError, http.StatusInternalServerError
+	}
+}
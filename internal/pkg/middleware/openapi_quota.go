@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"goWebExample/api/rest/response"
+	"goWebExample/internal/repository/apikey"
+	"goWebExample/internal/service"
+	apikeysvc "goWebExample/internal/service/apikey"
+)
+
+// OpenAPIQuotaMiddleware API密钥限流与配额中间件，需注册在 OpenAPIAuthMiddleware 之后
+//
+// 按密钥上配置的每分钟请求数上限、每日与每月配额计数，超出时返回 429 与 Retry-After；
+// 计数器不可用时放行请求，避免 Redis 故障导致全部开放接口不可用。
+func OpenAPIQuotaMiddleware(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get(clientKeyKey)
+		key, ok := value.(*apikey.APIKey)
+		if !exists || !ok {
+			// OAuth2 端点等未经 API Key 认证的请求
+			c.Next()
+			return
+		}
+
+		apiKeySvc, ok := service.GetRegistry().Get(apikeysvc.ServiceName).(*apikeysvc.APIKeyService)
+		if !ok || apiKeySvc == nil {
+			c.Next()
+			return
+		}
+
+		status, err := apiKeySvc.ConsumeQuota(c.Request.Context(), key)
+		if status != nil {
+			setQuotaHeaders(c, status)
+		}
+		if err == nil {
+			c.Next()
+			return
+		}
+
+		var code int
+		switch {
+		case errors.Is(err, apikeysvc.ErrRateLimited):
+			code = response.CodeRateLimited
+		case errors.Is(err, apikeysvc.ErrDailyQuotaExceeded), errors.Is(err, apikeysvc.ErrMonthlyQuotaExceeded):
+			code = response.CodeQuotaExceeded
+		default:
+			logger.Error("API密钥计数失败，放行请求", zap.String("apiKey", key.APIKey), zap.Error(err))
+			c.Next()
+			return
+		}
+
+		logger.Warn("API密钥超出限制", zap.String("apiKey", key.APIKey), zap.Error(err))
+		c.Header("Retry-After", strconv.FormatInt(int64(math.Ceil(status.RetryAfter.Seconds())), 10))
+		c.JSON(http.StatusTooManyRequests, response.Fail(code, err.Error()))
+		c.Abort()
+	}
+}
+
+// setQuotaHeaders 设置每分钟限流与每日配额的剩余量响应头，未配置的限制不返回
+func setQuotaHeaders(c *gin.Context, status *apikeysvc.QuotaStatus) {
+	if limit := status.Limits.RatePerMinute; limit > 0 {
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(limit, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(max(limit-status.Counts.Minute, 0), 10))
+	}
+	if quota := status.Limits.Daily; quota > 0 {
+		c.Header("X-Quota-Daily-Limit", strconv.FormatInt(quota, 10))
+		c.Header("X-Quota-Daily-Remaining", strconv.FormatInt(max(quota-status.Counts.Daily, 0), 10))
+	}
+	if quota := status.Limits.Monthly; quota > 0 {
+		c.Header("X-Quota-Monthly-Limit", strconv.FormatInt(quota, 10))
+		c.Header("X-Quota-Monthly-Remaining", strconv.FormatInt(max(quota-status.Counts.Monthly, 0), 10))
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"goWebExample/api/rest/response"
+	"goWebExample/internal/pkg/rbac"
+)
+
+// RequirePermission 权限校验中间件，需在 JWTAuthMiddleware 之后使用，要求拥有全部所列权限
+//
+// 在 RegisterRoutes 中按路由声明所需权限：
+//
+//	group.POST("/users", middleware.RequirePermission(rbac.PermUsersWrite), h.CreateUser)
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, response.Fail(http.StatusUnauthorized, "请先登录"))
+			c.Abort()
+			return
+		}
+
+		for _, permission := range permissions {
+			if !rbac.HasPermission(claims.Permissions, permission) {
+				c.JSON(http.StatusForbidden, response.Fail(http.StatusForbidden, "权限不足: "+permission))
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
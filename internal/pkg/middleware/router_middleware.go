@@ -18,6 +18,7 @@ import (
 	"goWebExample/internal/configs"
 	"goWebExample/internal/infra/cache"
 	"goWebExample/internal/infra/di/container"
+	"goWebExample/internal/pkg/metrics"
 )
 
 // GetRedisConnector 从容器中获取Redis连接器
@@ -49,6 +50,11 @@ func LoadMiddleware(config *configs.AllConfig, logger *zap.Logger, engine *gin.E
 	engine.RedirectTrailingSlash = true
 	engine.RedirectFixedPath = true
 
+	// 监控指标中间件 - 放在最外层，以便统计包括 panic 恢复后的 500 在内的所有请求
+	if config.Metrics != nil && config.Metrics.Enable {
+		engine.Use(MetricsMiddleware(metrics.Init(config.Metrics)))
+	}
+
 	// 恢复中间件，用于捕获所有panic并恢复
 	engine.Use(gin.Recovery())
 
@@ -60,6 +66,11 @@ func LoadMiddleware(config *configs.AllConfig, logger *zap.Logger, engine *gin.E
 		engine.Use(Cors(*config.Cors, logger))
 	}
 
+	// CSRF中间件 - 在CORS之后，预检请求已由CORS中间件处理
+	if config.CSRF != nil && config.CSRF.Enable {
+		engine.Use(CSRFMiddleware(config, logger))
+	}
+
 	// 添加链路追踪中间件
 	engine.Use(otelgin.Middleware(config.Trace.ServiceName))
 
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"goWebExample/internal/configs"
+)
+
+// SetSessionCookie 写入会话 Cookie，maxAge 为秒数
+func SetSessionCookie(c *gin.Context, config configs.SessionConfig, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     config.GetCookieName(),
+		Value:    value,
+		Path:     config.GetCookiePath(),
+		Domain:   config.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   config.Secure,
+		HttpOnly: true,
+		SameSite: sameSiteMode(config.SameSite),
+	})
+}
+
+// ClearSessionCookie 清除会话 Cookie
+func ClearSessionCookie(c *gin.Context, config configs.SessionConfig) {
+	SetSessionCookie(c, config, "", -1)
+}
+
+// sameSiteMode 解析 SameSite 配置，默认 Lax
+func sameSiteMode(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
@@ -0,0 +1,293 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// stubIdP 基于 httptest 的最小 OIDC 提供方
+type stubIdP struct {
+	t      *testing.T
+	server *httptest.Server
+	issuer string
+
+	mu        sync.Mutex
+	key       *rsa.PrivateKey
+	kid       string
+	codes     map[string]stubGrant // code -> 授权信息
+	jwksCalls int
+}
+
+type stubGrant struct {
+	challenge string
+	nonce     string
+}
+
+const (
+	stubClientID     = "go-web-example"
+	stubClientSecret = "s3cret"
+	stubRedirectURL  = "http://localhost/callback"
+)
+
+func newStubIdP(t *testing.T) *stubIdP {
+	t.Helper()
+
+	idp := &stubIdP{t: t, codes: map[string]stubGrant{}}
+	idp.rotateKey("k1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                 idp.issuer,
+			"authorization_endpoint": idp.issuer + "/authorize",
+			"token_endpoint":         idp.issuer + "/token",
+			"jwks_uri":               idp.issuer + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+		idp.jwksCalls++
+		pub := idp.key.PublicKey
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": idp.kid,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", idp.handleToken)
+
+	idp.server = httptest.NewServer(mux)
+	idp.issuer = idp.server.URL
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize 模拟用户在提供方完成登录，返回授权码
+func (idp *stubIdP) authorize(authURL string) string {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != stubClientID {
+		idp.t.Fatalf("unexpected authorization request: %s", authURL)
+	}
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	code := "code-" + query.Get("state")
+	idp.codes[code] = stubGrant{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	return code
+}
+
+func (idp *stubIdP) handleToken(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != stubClientID || clientSecret != stubClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+		return
+	}
+	_ = r.ParseForm()
+
+	idp.mu.Lock()
+	grant, ok := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "at",
+		"token_type":   "Bearer",
+		"id_token":     idp.sign(idp.claims(grant.nonce)),
+		"expires_in":   3600,
+	})
+}
+
+func (idp *stubIdP) claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            idp.issuer,
+		"sub":            "user-123",
+		"aud":            stubClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          "alice@example.com",
+		"email_verified": true,
+	}
+}
+
+func (idp *stubIdP) sign(claims jwt.Claims) string {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = idp.kid
+	signed, err := token.SignedString(idp.key)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	return signed
+}
+
+func (idp *stubIdP) rotateKey(kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	idp.mu.Lock()
+	idp.key, idp.kid = key, kid
+	idp.mu.Unlock()
+}
+
+func (idp *stubIdP) provider() *Provider {
+	return NewProvider(Config{
+		Issuer:       idp.issuer,
+		ClientID:     stubClientID,
+		ClientSecret: stubClientSecret,
+		RedirectURL:  stubRedirectURL,
+	})
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp := newStubIdP(t)
+	p := idp.provider()
+	ctx := context.Background()
+
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := p.AuthCodeURL(ctx, "state1", "nonce1", challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := idp.authorize(authURL)
+
+	token, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, "nonce1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if claims.Subject != "user-123" || claims.Email != "alice@example.com" || !claims.EmailVerified {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	idp := newStubIdP(t)
+	p := idp.provider()
+	ctx := context.Background()
+
+	_, challenge, _ := NewPKCE()
+	authURL, err := p.AuthCodeURL(ctx, "state1", "nonce1", challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := idp.authorize(authURL)
+
+	if _, err := p.Exchange(ctx, code, "wrong-verifier"); !errors.Is(err, ErrExchangeFailed) {
+		t.Errorf("error = %v, want ErrExchangeFailed", err)
+	}
+}
+
+func TestVerifyIDTokenRejectsInvalidClaims(t *testing.T) {
+	idp := newStubIdP(t)
+	p := idp.provider()
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+		nonce  string
+	}{
+		{name: "nonce", mutate: func(jwt.MapClaims) {}, nonce: "other"},
+		{name: "audience", mutate: func(c jwt.MapClaims) { c["aud"] = "someone-else" }, nonce: "n"},
+		{name: "issuer", mutate: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, nonce: "n"},
+		{name: "expired", mutate: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, nonce: "n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := idp.claims("n")
+			tt.mutate(claims)
+			if _, err := p.VerifyIDToken(ctx, idp.sign(claims), tt.nonce); !errors.Is(err, ErrInvalidIDToken) {
+				t.Errorf("error = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+
+	// HMAC 签名的 token 必须被拒绝
+	hmacToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, idp.claims("n")).SignedString([]byte(stubClientSecret))
+	if _, err := p.VerifyIDToken(ctx, hmacToken, "n"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("HS256 token: error = %v, want ErrInvalidIDToken", err)
+	}
+}
+
+func TestKeyRotationRefetchesJWKS(t *testing.T) {
+	idp := newStubIdP(t)
+	p := idp.provider()
+	ctx := context.Background()
+
+	if _, err := p.VerifyIDToken(ctx, idp.sign(idp.claims("n")), "n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 提供方轮换密钥；刷新间隔内不重新拉取，之后遇到未知 kid 自动刷新
+	idp.rotateKey("k2")
+	if _, err := p.VerifyIDToken(ctx, idp.sign(idp.claims("n")), "n"); err == nil {
+		t.Fatal("unknown kid should fail within refresh interval")
+	}
+	p.keys.mu.Lock()
+	p.keys.fetchedAt = time.Time{}
+	p.keys.mu.Unlock()
+	if _, err := p.VerifyIDToken(ctx, idp.sign(idp.claims("n")), "n"); err != nil {
+		t.Fatalf("VerifyIDToken() after rotation error = %v", err)
+	}
+	if idp.jwksCalls != 2 {
+		t.Errorf("jwks fetched %d times, want 2", idp.jwksCalls)
+	}
+}
+
+func TestDiscoverRejectsIssuerMismatch(t *testing.T) {
+	idp := newStubIdP(t)
+	p := NewProvider(Config{Issuer: idp.issuer + "/other", ClientID: stubClientID})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"issuer":"https://evil.example.com","authorization_endpoint":"a","token_endpoint":"t","jwks_uri":"j"}`))
+	}))
+	defer server.Close()
+	p.config.Issuer = strings.TrimRight(server.URL, "/")
+
+	if _, err := p.Discover(context.Background()); !errors.Is(err, ErrIssuerMismatch) {
+		t.Errorf("error = %v, want ErrIssuerMismatch", err)
+	}
+}
@@ -0,0 +1,241 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 支持的哈希算法
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// 定义常见错误
+var (
+	ErrUnsupportedAlgorithm = errors.New("不支持的密码哈希算法")
+	ErrInvalidHash          = errors.New("密码哈希格式无效")
+	ErrIncompatibleVersion  = errors.New("argon2 版本不兼容")
+)
+
+// Argon2Params argon2id 参数
+type Argon2Params struct {
+	Memory      uint32 // 内存开销（KiB）
+	Iterations  uint32 // 迭代次数
+	Parallelism uint8  // 并行度
+	SaltLength  uint32 // 盐长度（字节）
+	KeyLength   uint32 // 输出哈希长度（字节）
+}
+
+// Config 密码哈希配置，零值字段使用默认值
+type Config struct {
+	Algorithm  string // 新密码使用的算法，argon2id 或 bcrypt，默认 argon2id
+	Argon2     Argon2Params
+	BcryptCost int
+}
+
+// DefaultArgon2Params 默认 argon2id 参数（参考 OWASP 推荐值）
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Hasher 密码哈希器
+//
+// 哈希结果自带算法与参数：argon2id 使用 PHC 字符串格式
+// （$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>），bcrypt 使用其标准格式（$2a$/$2b$/$2y$）。
+// 不符合上述格式的存量值视为明文密码，仅用于兼容旧数据，登录成功后应通过 NeedsRehash 升级。
+type Hasher struct {
+	algorithm  string
+	argon2     Argon2Params
+	bcryptCost int
+}
+
+// NewHasher 创建密码哈希器
+func NewHasher(config Config) (*Hasher, error) {
+	h := &Hasher{
+		algorithm:  strings.ToLower(config.Algorithm),
+		argon2:     config.Argon2,
+		bcryptCost: config.BcryptCost,
+	}
+
+	if h.algorithm == "" {
+		h.algorithm = AlgorithmArgon2id
+	}
+	if h.algorithm != AlgorithmArgon2id && h.algorithm != AlgorithmBcrypt {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, config.Algorithm)
+	}
+
+	if h.argon2.Memory == 0 {
+		h.argon2.Memory = DefaultArgon2Params.Memory
+	}
+	if h.argon2.Iterations == 0 {
+		h.argon2.Iterations = DefaultArgon2Params.Iterations
+	}
+	if h.argon2.Parallelism == 0 {
+		h.argon2.Parallelism = DefaultArgon2Params.Parallelism
+	}
+	if h.argon2.SaltLength == 0 {
+		h.argon2.SaltLength = DefaultArgon2Params.SaltLength
+	}
+	if h.argon2.KeyLength == 0 {
+		h.argon2.KeyLength = DefaultArgon2Params.KeyLength
+	}
+	if h.bcryptCost == 0 {
+		h.bcryptCost = bcrypt.DefaultCost
+	}
+	if h.bcryptCost < bcrypt.MinCost || h.bcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost 超出范围 [%d, %d]: %d", bcrypt.MinCost, bcrypt.MaxCost, h.bcryptCost)
+	}
+
+	return h, nil
+}
+
+// Algorithm 返回新密码使用的算法
+func (h *Hasher) Algorithm() string {
+	return h.algorithm
+}
+
+// Hash 使用当前配置的算法对密码进行哈希
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.algorithm == AlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("bcrypt 哈希失败: %w", err)
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, h.argon2.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("生成盐值失败: %w", err)
+	}
+	return encodeArgon2(h.argon2, salt, deriveArgon2([]byte(password), salt, h.argon2)), nil
+}
+
+// Verify 校验密码是否与哈希匹配，所有比较均为常量时间
+func (h *Hasher) Verify(password, encoded string) (bool, error) {
+	switch {
+	case isArgon2(encoded):
+		params, salt, key, err := decodeArgon2(encoded)
+		if err != nil {
+			return false, err
+		}
+		params.KeyLength = uint32(len(key))
+		other := deriveArgon2([]byte(password), salt, params)
+		return subtle.ConstantTimeCompare(key, other) == 1, nil
+	case isBcrypt(encoded):
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+		}
+		return true, nil
+	default:
+		// 兼容存量明文密码
+		if encoded == "" {
+			return false, nil
+		}
+		return subtle.ConstantTimeCompare([]byte(password), []byte(encoded)) == 1, nil
+	}
+}
+
+// NeedsRehash 判断哈希是否需要按当前配置重新计算（算法或参数变化、存量明文）
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	switch {
+	case isArgon2(encoded):
+		if h.algorithm != AlgorithmArgon2id {
+			return true
+		}
+		params, salt, key, err := decodeArgon2(encoded)
+		if err != nil {
+			return true
+		}
+		return params.Memory != h.argon2.Memory ||
+			params.Iterations != h.argon2.Iterations ||
+			params.Parallelism != h.argon2.Parallelism ||
+			uint32(len(salt)) != h.argon2.SaltLength ||
+			uint32(len(key)) != h.argon2.KeyLength
+	case isBcrypt(encoded):
+		if h.algorithm != AlgorithmBcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost != h.bcryptCost
+	default:
+		return true
+	}
+}
+
+// isArgon2 是否为 argon2id PHC 格式
+func isArgon2(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+// isBcrypt 是否为 bcrypt 格式
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+// deriveArgon2 计算 argon2id 哈希
+func deriveArgon2(password, salt []byte, p Argon2Params) []byte {
+	return argon2.IDKey(password, salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+}
+
+// encodeArgon2 编码为 PHC 字符串格式
+func encodeArgon2(p Argon2Params, salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key))
+}
+
+// decodeArgon2 解析 PHC 字符串格式
+func decodeArgon2(encoded string) (Argon2Params, []byte, []byte, error) {
+	var p Argon2Params
+
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, nil, nil, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+	if version != argon2.Version {
+		return p, nil, nil, ErrIncompatibleVersion
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	return p, salt, key, nil
+}
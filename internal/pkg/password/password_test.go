@@ -0,0 +1,90 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+// fastArgon2 测试用的低开销参数
+var fastArgon2 = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestHashAndVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		prefix string
+	}{
+		{name: "argon2id", config: Config{Algorithm: AlgorithmArgon2id, Argon2: fastArgon2}, prefix: "$argon2id$v=19$m=1024,t=1,p=1$"},
+		{name: "bcrypt", config: Config{Algorithm: AlgorithmBcrypt, BcryptCost: 4}, prefix: "$2a$04$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewHasher(tt.config)
+			if err != nil {
+				t.Fatalf("NewHasher() error = %v", err)
+			}
+
+			hash, err := h.Hash("s3cret")
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if !strings.HasPrefix(hash, tt.prefix) {
+				t.Errorf("Hash() = %q, want prefix %q", hash, tt.prefix)
+			}
+
+			if ok, err := h.Verify("s3cret", hash); err != nil || !ok {
+				t.Errorf("Verify(correct) = %v, %v; want true, nil", ok, err)
+			}
+			if ok, err := h.Verify("wrong", hash); err != nil || ok {
+				t.Errorf("Verify(wrong) = %v, %v; want false, nil", ok, err)
+			}
+			if h.NeedsRehash(hash) {
+				t.Errorf("NeedsRehash() = true for freshly hashed password")
+			}
+		})
+	}
+}
+
+func TestVerifyLegacyPlaintext(t *testing.T) {
+	h, _ := NewHasher(Config{Argon2: fastArgon2})
+
+	if ok, _ := h.Verify("plain", "plain"); !ok {
+		t.Error("Verify() should accept matching legacy plaintext")
+	}
+	if ok, _ := h.Verify("", ""); ok {
+		t.Error("Verify() should reject empty stored value")
+	}
+	if !h.NeedsRehash("plain") {
+		t.Error("NeedsRehash() should be true for legacy plaintext")
+	}
+}
+
+func TestNeedsRehashOnConfigChange(t *testing.T) {
+	bcryptHasher, _ := NewHasher(Config{Algorithm: AlgorithmBcrypt, BcryptCost: 4})
+	argonHasher, _ := NewHasher(Config{Argon2: fastArgon2})
+	strongerArgon, _ := NewHasher(Config{Argon2: Argon2Params{Memory: 2048, Iterations: 1, Parallelism: 1}})
+
+	bcryptHash, _ := bcryptHasher.Hash("pw")
+	argonHash, _ := argonHasher.Hash("pw")
+
+	if !argonHasher.NeedsRehash(bcryptHash) {
+		t.Error("bcrypt hash should be upgraded when argon2id is configured")
+	}
+	if !bcryptHasher.NeedsRehash(argonHash) {
+		t.Error("argon2id hash should be rehashed when bcrypt is configured")
+	}
+	if !strongerArgon.NeedsRehash(argonHash) {
+		t.Error("argon2id hash should be rehashed when parameters change")
+	}
+	// 参数变化后旧哈希依旧可以校验
+	if ok, _ := strongerArgon.Verify("pw", argonHash); !ok {
+		t.Error("Verify() should accept hashes created with older parameters")
+	}
+}
+
+func TestNewHasherUnsupportedAlgorithm(t *testing.T) {
+	if _, err := NewHasher(Config{Algorithm: "md5"}); err == nil {
+		t.Error("NewHasher() should reject unsupported algorithm")
+	}
+}
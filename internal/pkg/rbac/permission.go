@@ -0,0 +1,57 @@
+package rbac
+
+import "strings"
+
+// Wildcard 通配符，单独使用时表示拥有全部权限
+const Wildcard = "*"
+
+// 内置权限码，格式为 资源:操作
+const (
+	PermAdminAccess = "admin:access" // 访问管理后台路由组
+	PermUsersRead   = "users:read"
+	PermUsersWrite  = "users:write"
+	PermUsersUnlock = "users:unlock"
+	PermUsersAdmin  = "users:admin" // 授予或撤销超级管理员
+
+	PermAPIKeysRead  = "apikeys:read"
+	PermAPIKeysWrite = "apikeys:write"
+
+	PermSchedulerRead  = "scheduler:read"
+	PermSchedulerWrite = "scheduler:write"
+)
+
+// Match 判断授予的权限模式是否覆盖所需权限
+//
+// 权限按 ":" 分段逐段比较，"*" 匹配任意一段；位于末尾的 "*" 匹配剩余所有段，
+// 例如 "users:*" 覆盖 "users:write" 与 "users:write:self"，"*" 覆盖所有权限。
+func Match(pattern, required string) bool {
+	if pattern == Wildcard || pattern == required {
+		return true
+	}
+
+	patternParts := strings.Split(pattern, ":")
+	requiredParts := strings.Split(required, ":")
+
+	for i, part := range patternParts {
+		if part == Wildcard && i == len(patternParts)-1 {
+			return len(requiredParts) >= len(patternParts)
+		}
+		if i >= len(requiredParts) {
+			return false
+		}
+		if part != Wildcard && part != requiredParts[i] {
+			return false
+		}
+	}
+	return len(patternParts) == len(requiredParts)
+}
+
+// HasPermission 判断授予的权限集合中是否有覆盖所需权限的项
+func HasPermission(granted []string, required string) bool {
+	for _, pattern := range granted {
+		if Match(pattern, required) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package rbac
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		required string
+		want     bool
+	}{
+		{"*", "users:write", true},
+		{"users:write", "users:write", true},
+		{"users:read", "users:write", false},
+		{"users:*", "users:write", true},
+		{"users:*", "users:write:self", true},
+		{"users:*", "users", false},
+		{"users:*", "apikeys:write", false},
+		{"*:read", "users:read", true},
+		{"*:read", "users:write", false},
+		{"*:read", "users:read:self", false},
+		{"users", "users:read", false},
+		{"users:read:self", "users:read", false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.required); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	granted := []string{"users:read", "apikeys:*"}
+
+	if !HasPermission(granted, "apikeys:rotate") {
+		t.Error("apikeys:* should cover apikeys:rotate")
+	}
+	if HasPermission(granted, "users:write") {
+		t.Error("users:read should not cover users:write")
+	}
+	if HasPermission(nil, "users:read") {
+		t.Error("empty permission set should deny")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"go.uber.org/zap"
 
 	"goWebExample/internal/infra/di/container"
+	"goWebExample/internal/pkg/metrics"
 )
 
 var (
@@ -59,5 +60,19 @@ func InitGroups(engine *gin.Engine, logger *zap.Logger, container *container.Ser
 		}
 	})
 
+	// 注册 JWKS 路由，供其他服务获取验签公钥
+	if jwtManager := container.GetJWTManager(); jwtManager != nil {
+		engine.GET("/.well-known/jwks.json", func(c *gin.Context) {
+			c.Header("Cache-Control", "public, max-age=300")
+			c.JSON(200, jwtManager.JWKS())
+		})
+	}
+
+	// 注册 Prometheus 指标路由
+	if metrics.Enabled() {
+		engine.GET(metrics.Path(), gin.WrapH(metrics.Handler()))
+		logger.Info("监控指标路由已注册", zap.String("path", metrics.Path()))
+	}
+
 	logger.Info("路由组初始化完成")
 }
@@ -0,0 +1,71 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录 B 的 SHA1 测试向量（取后 6 位）
+func TestGenerateRFCVectors(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		got, err := Generate(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Generate(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+
+	previous, _ := Generate(secret, now.Add(-Period))
+	counter, ok, err := Validate(previous, secret, now, DefaultSkew)
+	if err != nil || !ok {
+		t.Fatalf("previous step should be accepted: ok=%v err=%v", ok, err)
+	}
+	if counter != Counter(now)-1 {
+		t.Errorf("counter = %d, want %d", counter, Counter(now)-1)
+	}
+
+	stale, _ := Generate(secret, now.Add(-3*Period))
+	if _, ok, _ := Validate(stale, secret, now, DefaultSkew); ok {
+		t.Error("code outside skew window must be rejected")
+	}
+	if _, ok, _ := Validate("12345", secret, now, DefaultSkew); ok {
+		t.Error("short code must be rejected")
+	}
+	if _, _, err := Validate("123456", "not base32!", now, DefaultSkew); err != ErrInvalidSecret {
+		t.Errorf("error = %v, want ErrInvalidSecret", err)
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Go Web", "alice@example.com", "JBSWY3DPEHPK3PXP")
+	if !strings.HasPrefix(uri, "otpauth://totp/Go%20Web:alice@example.com?") {
+		t.Errorf("unexpected label: %s", uri)
+	}
+	if !strings.Contains(uri, "secret=JBSWY3DPEHPK3PXP") || !strings.Contains(uri, "issuer=Go+Web") {
+		t.Errorf("unexpected query: %s", uri)
+	}
+}
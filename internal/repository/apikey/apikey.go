@@ -1,24 +1,149 @@
 package apikey
 
 import (
-	"gorm.io/gorm"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidRouteRule 路由规则格式错误
+var ErrInvalidRouteRule = errors.New("路由规则格式错误")
+
+// API密钥状态
+const (
+	StatusDisabled = 0
+	StatusEnabled  = 1
 )
 
 // APIKey 表示API密钥数据模型
 type APIKey struct {
-	ID          uint64         `gorm:"primaryKey;autoIncrement;comment:'主键ID'" json:"id,omitempty"`
-	APIKey      string         `gorm:"type:varchar(64);unique;not null;comment:'API密钥'" json:"apiKey,omitempty"`
-	APISecret   string         `gorm:"type:varchar(128);not null;comment:'API密钥对应的秘钥'" json:"-"`
-	Status      int            `gorm:"type:tinyint;default:1;not null;comment:'状态：0-禁用，1-启用'" json:"status,omitempty"`
-	ExpiredAt   time.Time      `gorm:"type:timestamp;not null;comment:'过期时间'" json:"expiredAt,omitempty"`
-	Description string         `gorm:"type:varchar(255);comment:'描述'" json:"description,omitempty"`
-	CreatedAt   time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;not null;comment:'创建时间'" json:"createdAt"`
-	UpdatedAt   time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;not null;comment:'更新时间'" json:"updatedAt"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                      uint64         `gorm:"primaryKey;autoIncrement;comment:'主键ID'" json:"id,omitempty"`
+	APIKey                  string         `gorm:"type:varchar(64);unique;not null;comment:'API密钥'" json:"apiKey,omitempty"`
+	APISecret               string         `gorm:"type:varchar(512);not null;comment:'API密钥对应的秘钥（配置主密钥后加密存储）'" json:"-"`
+	PreviousSecret          string         `gorm:"type:varchar(512);not null;default:'';comment:'轮换前的秘钥，过渡期内仍可使用'" json:"-"`
+	PreviousSecretExpiresAt *time.Time     `gorm:"type:timestamp;null;comment:'旧秘钥失效时间'" json:"previousSecretExpiresAt,omitempty"`
+	Status                  int            `gorm:"type:tinyint;default:1;not null;comment:'状态：0-禁用，1-启用'" json:"status,omitempty"`
+	ExpiredAt               time.Time      `gorm:"type:timestamp;not null;comment:'过期时间'" json:"expiredAt,omitempty"`
+	Description             string         `gorm:"type:varchar(255);comment:'描述'" json:"description,omitempty"`
+	Scopes                  string         `gorm:"type:varchar(512);not null;default:'';comment:'OAuth2 授权范围，空格分隔'" json:"scopes,omitempty"`
+	AllowedRoutes           string         `gorm:"type:varchar(1024);not null;default:'';comment:'允许访问的路由，空格分隔，为空时不限制'" json:"allowedRoutes,omitempty"`
+	RateLimit               int64          `gorm:"type:int unsigned;not null;default:0;comment:'每分钟请求数上限，0 表示不限制'" json:"rateLimit,omitempty"`
+	DailyQuota              int64          `gorm:"type:bigint unsigned;not null;default:0;comment:'每日请求数配额，0 表示不限制'" json:"dailyQuota,omitempty"`
+	MonthlyQuota            int64          `gorm:"type:bigint unsigned;not null;default:0;comment:'每月请求数配额，0 表示不限制'" json:"monthlyQuota,omitempty"`
+	CreatedAt               time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;not null;comment:'创建时间'" json:"createdAt"`
+	UpdatedAt               time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;not null;comment:'更新时间'" json:"updatedAt"`
+	DeletedAt               gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // TableName 指定表名
 func (a *APIKey) TableName() string {
 	return "api_keys"
 }
+
+// ActiveSecrets 获取当前可用于验证的秘钥，轮换过渡期内同时包含旧秘钥
+func (a *APIKey) ActiveSecrets(now time.Time) []string {
+	secrets := []string{a.APISecret}
+	if a.PreviousSecret != "" && a.PreviousSecretExpiresAt != nil && now.Before(*a.PreviousSecretExpiresAt) {
+		secrets = append(secrets, a.PreviousSecret)
+	}
+	return secrets
+}
+
+// Limits 获取密钥的限流与配额设置
+func (a *APIKey) Limits() UsageLimits {
+	return UsageLimits{RatePerMinute: a.RateLimit, Daily: a.DailyQuota, Monthly: a.MonthlyQuota}
+}
+
+// ScopeList 获取授权范围列表
+func (a *APIKey) ScopeList() []string {
+	return strings.Fields(a.Scopes)
+}
+
+// RouteRule 路由访问规则，格式为 "[METHOD[,METHOD...]:]/path/prefix"，省略方法时允许任意方法
+//
+// 路径按段匹配前缀，例如 "/openapi/data" 匹配 "/openapi/data" 与 "/openapi/data/1"，不匹配 "/openapi/database"。
+type RouteRule struct {
+	Methods []string
+	Prefix  string
+}
+
+// ParseRouteRule 解析路由规则
+func ParseRouteRule(rule string) (RouteRule, error) {
+	var r RouteRule
+	methods, prefix, found := strings.Cut(rule, ":")
+	if !found || strings.HasPrefix(rule, "/") {
+		methods, prefix, found = "", rule, false
+	}
+	if !strings.HasPrefix(prefix, "/") || strings.ContainsAny(prefix, " \t") {
+		return r, ErrInvalidRouteRule
+	}
+	r.Prefix = strings.TrimSuffix(prefix, "/")
+
+	if found {
+		for _, m := range strings.Split(methods, ",") {
+			m = strings.ToUpper(strings.TrimSpace(m))
+			if !isHTTPMethod(m) {
+				return r, ErrInvalidRouteRule
+			}
+			r.Methods = append(r.Methods, m)
+		}
+	}
+	return r, nil
+}
+
+// String 格式化为规则字符串
+func (r RouteRule) String() string {
+	prefix := r.Prefix
+	if prefix == "" {
+		prefix = "/"
+	}
+	if len(r.Methods) == 0 {
+		return prefix
+	}
+	return strings.Join(r.Methods, ",") + ":" + prefix
+}
+
+// Allows 判断规则是否允许该请求
+func (r RouteRule) Allows(method, path string) bool {
+	if len(r.Methods) > 0 && !slices.Contains(r.Methods, method) {
+		return false
+	}
+	return r.Prefix == "" || path == r.Prefix || strings.HasPrefix(path, r.Prefix+"/")
+}
+
+// RouteRules 获取路由规则列表，忽略格式错误的规则
+func (a *APIKey) RouteRules() []RouteRule {
+	var rules []RouteRule
+	for _, field := range strings.Fields(a.AllowedRoutes) {
+		if rule, err := ParseRouteRule(field); err == nil {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// AllowsRoute 判断密钥是否允许访问该路由，未配置路由规则时不限制
+func (a *APIKey) AllowsRoute(method, path string) bool {
+	if strings.TrimSpace(a.AllowedRoutes) == "" {
+		return true
+	}
+	for _, rule := range a.RouteRules() {
+		if rule.Allows(method, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHTTPMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
@@ -2,7 +2,9 @@ package apikey
 
 import (
 	"errors"
+	"fmt"
 	"goWebExample/internal/infra/db/mysql"
+	"goWebExample/pkg/envelope"
 	"time"
 
 	"gorm.io/gorm"
@@ -17,12 +19,16 @@ var (
 	ErrAPIKeyDisabled = errors.New("API密钥已禁用")
 	// ErrAPIKeyExpired API密钥已过期错误
 	ErrAPIKeyExpired = errors.New("API密钥已过期")
+	// ErrCipherUnavailable 秘钥已加密存储，但未配置主密钥
+	ErrCipherUnavailable = errors.New("API秘钥已加密存储，但未配置加密主密钥")
 )
 
 // RepositoryAPIKey API密钥数据操作接口
 type RepositoryAPIKey interface {
 	GetDB() *gorm.DB
 	GetByAPIKey(apiKey string) (*APIKey, error)
+	GetByID(id uint64) (*APIKey, error)
+	FindByAPIKey(apiKey string) (*APIKey, error)
 	Create(apiKey *APIKey) error
 	Update(apiKey *APIKey) error
 	Delete(id uint64) error
@@ -32,11 +38,68 @@ type RepositoryAPIKey interface {
 // apiKeyRepositoryImpl API密钥仓库实现
 type apiKeyRepositoryImpl struct {
 	dbConnector *mysql.DBConnector
+	secrets     envelope.Cipher
 }
 
-// NewAPIKeyRepository 创建API密钥仓库
-func NewAPIKeyRepository(dbConnector *mysql.DBConnector) RepositoryAPIKey {
-	return &apiKeyRepositoryImpl{dbConnector: dbConnector}
+// NewAPIKeyRepository 创建API密钥仓库，secrets 不为 nil 时秘钥加密存储，读取时透明解密；
+// 加密前保存的明文秘钥仍可读取，下次保存或执行 cmd/rekey 时加密
+func NewAPIKeyRepository(dbConnector *mysql.DBConnector, secrets envelope.Cipher) RepositoryAPIKey {
+	return &apiKeyRepositoryImpl{dbConnector: dbConnector, secrets: secrets}
+}
+
+// SecretAAD 秘钥密文的附加认证数据，将密文绑定到所属的API密钥
+func SecretAAD(apiKey string) []byte {
+	return []byte("api_keys:" + apiKey + ":secret")
+}
+
+// EncryptSecret 加密秘钥，未配置加密器时返回明文
+func EncryptSecret(secrets envelope.Cipher, apiKey, secret string) (string, error) {
+	if secrets == nil || secret == "" {
+		return secret, nil
+	}
+	encrypted, err := secrets.Encrypt([]byte(secret), SecretAAD(apiKey))
+	if err != nil {
+		return "", fmt.Errorf("加密API秘钥失败: %w", err)
+	}
+	return encrypted, nil
+}
+
+// DecryptSecret 解密秘钥，非密文的值视为加密前保存的明文
+func DecryptSecret(secrets envelope.Cipher, apiKey, stored string) (string, error) {
+	if !envelope.IsEncrypted(stored) {
+		return stored, nil
+	}
+	if secrets == nil {
+		return "", ErrCipherUnavailable
+	}
+	plaintext, err := secrets.Decrypt(stored, SecretAAD(apiKey))
+	if err != nil {
+		return "", fmt.Errorf("解密API秘钥失败: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// decrypt 将从数据库读取的秘钥解密为明文
+func (r *apiKeyRepositoryImpl) decrypt(key *APIKey) error {
+	var err error
+	if key.APISecret, err = DecryptSecret(r.secrets, key.APIKey, key.APISecret); err != nil {
+		return err
+	}
+	key.PreviousSecret, err = DecryptSecret(r.secrets, key.APIKey, key.PreviousSecret)
+	return err
+}
+
+// encrypted 返回秘钥已加密的副本用于保存，不修改调用方持有的明文记录
+func (r *apiKeyRepositoryImpl) encrypted(key *APIKey) (*APIKey, error) {
+	stored := *key
+	var err error
+	if stored.APISecret, err = EncryptSecret(r.secrets, key.APIKey, key.APISecret); err != nil {
+		return nil, err
+	}
+	if stored.PreviousSecret, err = EncryptSecret(r.secrets, key.APIKey, key.PreviousSecret); err != nil {
+		return nil, err
+	}
+	return &stored, nil
 }
 
 // GetDB 获取数据库连接
@@ -58,6 +121,9 @@ func (r *apiKeyRepositoryImpl) GetByAPIKey(apiKey string) (*APIKey, error) {
 		}
 		return nil, err
 	}
+	if err := r.decrypt(&key); err != nil {
+		return nil, err
+	}
 
 	// 检查API密钥状态
 	if key.Status != 1 {
@@ -72,13 +138,61 @@ func (r *apiKeyRepositoryImpl) GetByAPIKey(apiKey string) (*APIKey, error) {
 	return &key, nil
 }
 
+// GetByID 根据ID获取记录，不检查状态与有效期，供管理接口使用
+func (r *apiKeyRepositoryImpl) GetByID(id uint64) (*APIKey, error) {
+	db := r.GetDB()
+	if db == nil {
+		return nil, ErrDBNotConnected
+	}
+
+	var key APIKey
+	if err := db.First(&key, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	if err := r.decrypt(&key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindByAPIKey 根据API密钥获取记录，不检查状态与有效期，由调用方在验证凭证后再检查
+func (r *apiKeyRepositoryImpl) FindByAPIKey(apiKey string) (*APIKey, error) {
+	db := r.GetDB()
+	if db == nil {
+		return nil, ErrDBNotConnected
+	}
+
+	var key APIKey
+	if err := db.Where("api_key = ?", apiKey).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	if err := r.decrypt(&key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
 // Create 创建API密钥
 func (r *apiKeyRepositoryImpl) Create(apiKey *APIKey) error {
 	db := r.GetDB()
 	if db == nil {
 		return ErrDBNotConnected
 	}
-	return db.Create(apiKey).Error
+	stored, err := r.encrypted(apiKey)
+	if err != nil {
+		return err
+	}
+	if err := db.Create(stored).Error; err != nil {
+		return err
+	}
+	apiKey.ID, apiKey.CreatedAt, apiKey.UpdatedAt = stored.ID, stored.CreatedAt, stored.UpdatedAt
+	return nil
 }
 
 // Update 更新API密钥
@@ -87,7 +201,15 @@ func (r *apiKeyRepositoryImpl) Update(apiKey *APIKey) error {
 	if db == nil {
 		return ErrDBNotConnected
 	}
-	return db.Save(apiKey).Error
+	stored, err := r.encrypted(apiKey)
+	if err != nil {
+		return err
+	}
+	if err := db.Save(stored).Error; err != nil {
+		return err
+	}
+	apiKey.UpdatedAt = stored.UpdatedAt
+	return nil
 }
 
 // Delete 删除API密钥
@@ -110,5 +232,10 @@ func (r *apiKeyRepositoryImpl) GetAll() ([]APIKey, error) {
 	if err := db.Find(&keys).Error; err != nil {
 		return nil, err
 	}
+	for i := range keys {
+		if err := r.decrypt(&keys[i]); err != nil {
+			return nil, err
+		}
+	}
 	return keys, nil
 }
@@ -0,0 +1,51 @@
+package apikey
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAllowsRoute(t *testing.T) {
+	key := &APIKey{AllowedRoutes: "GET:/openapi/data post,PUT:/openapi/orders/ /openapi/status"}
+
+	cases := []struct {
+		method, path string
+		want         bool
+	}{
+		{"GET", "/openapi/data", true},
+		{"GET", "/openapi/data/1", true},
+		{"POST", "/openapi/data", false},
+		{"GET", "/openapi/database", false},
+		{"POST", "/openapi/orders", true},
+		{"PUT", "/openapi/orders/1", true},
+		{"DELETE", "/openapi/orders/1", false},
+		{"DELETE", "/openapi/status", true},
+		{"GET", "/openapi/other", false},
+	}
+	for _, tc := range cases {
+		if got := key.AllowsRoute(tc.method, tc.path); got != tc.want {
+			t.Errorf("AllowsRoute(%s %s) = %v, want %v", tc.method, tc.path, got, tc.want)
+		}
+	}
+
+	// 未配置路由规则时不限制
+	if !(&APIKey{}).AllowsRoute("DELETE", "/openapi/anything") {
+		t.Error("key without rules should allow all routes")
+	}
+}
+
+func TestParseRouteRule(t *testing.T) {
+	rule, err := ParseRouteRule("get,Post:/openapi/data/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rule.String(); got != "GET,POST:/openapi/data" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for _, bad := range []string{"", "openapi/data", "FETCH:/openapi", "GET:", "GET:openapi"} {
+		if _, err := ParseRouteRule(bad); !errors.Is(err, ErrInvalidRouteRule) {
+			t.Errorf("ParseRouteRule(%q) error = %v, want ErrInvalidRouteRule", bad, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package apikey
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"goWebExample/internal/infra/db/mysql"
+)
+
+// Usage API密钥每日调用量，由定时任务从计数器刷入
+type Usage struct {
+	APIKey       string    `gorm:"primaryKey;type:varchar(64);comment:'API密钥'" json:"apiKey"`
+	UsageDate    time.Time `gorm:"primaryKey;type:date;comment:'统计日期'" json:"usageDate"`
+	RequestCount int64     `gorm:"type:bigint unsigned;not null;default:0;comment:'请求数'" json:"requestCount"`
+	UpdatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;not null;comment:'更新时间'" json:"updatedAt"`
+}
+
+// TableName 指定表名
+func (u *Usage) TableName() string {
+	return "api_key_usage"
+}
+
+// RepositoryUsage API密钥调用量数据操作接口
+type RepositoryUsage interface {
+	// SaveDailyUsage 保存某日各密钥的累计请求数，只会增大已保存的计数，重复保存同一快照是幂等的
+	SaveDailyUsage(day time.Time, counts map[string]int64) error
+	// ListUsage 获取密钥在 [from, to] 日期范围内的每日调用量，按日期升序
+	ListUsage(apiKey string, from, to time.Time) ([]Usage, error)
+}
+
+// usageRepositoryImpl API密钥调用量仓库实现
+type usageRepositoryImpl struct {
+	dbConnector *mysql.DBConnector
+}
+
+// NewUsageRepository 创建API密钥调用量仓库
+func NewUsageRepository(dbConnector *mysql.DBConnector) RepositoryUsage {
+	return &usageRepositoryImpl{dbConnector: dbConnector}
+}
+
+func (r *usageRepositoryImpl) SaveDailyUsage(day time.Time, counts map[string]int64) error {
+	db := r.dbConnector.GetDB()
+	if db == nil {
+		return ErrDBNotConnected
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]Usage, 0, len(counts))
+	for apiKey, count := range counts {
+		rows = append(rows, Usage{APIKey: apiKey, UsageDate: dayStart(day), RequestCount: count, UpdatedAt: now})
+	}
+	// 计数器丢失（如 Redis 重启）后重新计数时，不能用较小的值覆盖已保存的计数
+	return db.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"request_count": gorm.Expr("GREATEST(request_count, VALUES(request_count))"),
+			"updated_at":    now,
+		}),
+	}).CreateInBatches(rows, 500).Error
+}
+
+func (r *usageRepositoryImpl) ListUsage(apiKey string, from, to time.Time) ([]Usage, error) {
+	db := r.dbConnector.GetDB()
+	if db == nil {
+		return nil, ErrDBNotConnected
+	}
+
+	var usage []Usage
+	err := db.Where("api_key = ? AND usage_date BETWEEN ? AND ?", apiKey, dayStart(from), dayStart(to)).
+		Order("usage_date").
+		Find(&usage).Error
+	return usage, err
+}
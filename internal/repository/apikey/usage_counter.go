@@ -0,0 +1,109 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"goWebExample/internal/infra/cache"
+	"goWebExample/internal/infra/di/factory"
+)
+
+// ErrRedisNotConnected Redis未连接错误
+var ErrRedisNotConnected = errors.New("Redis未连接")
+
+// UsageLimits 限流与配额设置，0 表示不限制
+type UsageLimits struct {
+	RatePerMinute int64
+	Daily         int64
+	Monthly       int64
+}
+
+// LimitKind 超出的限制类型
+type LimitKind int
+
+const (
+	// LimitNone 未超出限制
+	LimitNone LimitKind = iota
+	// LimitRate 超出每分钟请求数上限
+	LimitRate
+	// LimitDaily 超出每日配额
+	LimitDaily
+	// LimitMonthly 超出每月配额
+	LimitMonthly
+)
+
+// UsageCounts 当前周期内的请求数
+type UsageCounts struct {
+	Minute  int64
+	Daily   int64
+	Monthly int64
+}
+
+// ConsumeResult 计数结果，Exceeded 不为 LimitNone 时本次请求被拒绝且未计数
+type ConsumeResult struct {
+	Exceeded LimitKind
+	Counts   UsageCounts
+}
+
+// UsageCounter 调用量计数器：限流窗口按分钟，配额按本地时区的自然日与自然月
+type UsageCounter interface {
+	// Consume 检查限制并在未超出时原子地为密钥计数一次
+	Consume(ctx context.Context, apiKey string, limits UsageLimits, now time.Time) (*ConsumeResult, error)
+	// Current 获取密钥当前的请求数，不计数
+	Current(ctx context.Context, apiKey string, now time.Time) (UsageCounts, error)
+	// DailyCounts 获取某日全部密钥的累计请求数，计数至少保留到次日结束
+	DailyCounts(ctx context.Context, day time.Time) (map[string]int64, error)
+}
+
+// NewUsageCounterFromFactory 创建调用量计数器：工厂中注册了 Redis 连接器时使用 Redis，否则使用内存计数（仅适用于单实例部署）；
+// 第二个返回值为所用计数器的名称，便于记录日志
+func NewUsageCounterFromFactory(f *factory.Factory) (UsageCounter, string) {
+	if f != nil {
+		if redisConnector, ok := f.GetConnector("redis").(*cache.RedisConnector); ok {
+			return NewRedisUsageCounter(redisConnector), "redis"
+		}
+	}
+	return NewMemoryUsageCounter(), "memory"
+}
+
+// 各计数周期的起止时间
+func minuteStart(now time.Time) time.Time {
+	return now.Truncate(time.Minute)
+}
+
+func dayStart(now time.Time) time.Time {
+	y, m, d := now.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+}
+
+func monthStart(now time.Time) time.Time {
+	y, m, _ := now.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, now.Location())
+}
+
+// 计数保留时间：分钟计数保留到窗口结束，日计数多保留一天供刷入 MySQL，月计数保留到次月第一天结束
+func minuteExpiry(now time.Time) time.Time {
+	return minuteStart(now).Add(time.Minute)
+}
+
+func dayExpiry(now time.Time) time.Time {
+	return dayStart(now).AddDate(0, 0, 2)
+}
+
+func monthExpiry(now time.Time) time.Time {
+	return monthStart(now).AddDate(0, 1, 1)
+}
+
+// exceeded 判断计数是否已达到限制
+func exceeded(limits UsageLimits, counts UsageCounts) LimitKind {
+	switch {
+	case limits.RatePerMinute > 0 && counts.Minute >= limits.RatePerMinute:
+		return LimitRate
+	case limits.Daily > 0 && counts.Daily >= limits.Daily:
+		return LimitDaily
+	case limits.Monthly > 0 && counts.Monthly >= limits.Monthly:
+		return LimitMonthly
+	}
+	return LimitNone
+}
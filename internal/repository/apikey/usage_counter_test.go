@@ -0,0 +1,75 @@
+package apikey
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryUsageCounter(t *testing.T) {
+	ctx := context.Background()
+	counter := NewMemoryUsageCounter()
+	now := time.Date(2026, 1, 31, 23, 59, 10, 0, time.Local)
+	limits := UsageLimits{RatePerMinute: 2, Daily: 3, Monthly: 4}
+
+	consume := func(at time.Time) *ConsumeResult {
+		t.Helper()
+		result, err := counter.Consume(ctx, "ak_a", limits, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	consume(now)
+	consume(now.Add(10 * time.Second))
+	// 超出每分钟上限的请求不计数
+	if r := consume(now.Add(20 * time.Second)); r.Exceeded != LimitRate || r.Counts.Daily != 2 {
+		t.Fatalf("third request in the minute = %+v, want rate limited", r)
+	}
+
+	// 下一分钟已是次日（2 月 1 日），限流窗口、每日与每月配额均重置
+	if r := consume(now.Add(time.Minute)); r.Exceeded != LimitNone || r.Counts.Daily != 1 || r.Counts.Monthly != 1 {
+		t.Fatalf("first request of the new day = %+v", r)
+	}
+
+	// 其他密钥互不影响
+	if r, _ := counter.Consume(ctx, "ak_b", limits, now); r.Exceeded != LimitNone {
+		t.Fatalf("other key = %+v", r)
+	}
+
+	counts, _ := counter.DailyCounts(ctx, now)
+	if counts["ak_a"] != 2 || counts["ak_b"] != 1 {
+		t.Errorf("DailyCounts = %v", counts)
+	}
+}
+
+func TestMemoryUsageCounterQuotas(t *testing.T) {
+	ctx := context.Background()
+	counter := NewMemoryUsageCounter()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+
+	daily := UsageLimits{Daily: 2}
+	for i := 0; i < 2; i++ {
+		counter.Consume(ctx, "ak_daily", daily, now.Add(time.Duration(i)*time.Hour))
+	}
+	if r, _ := counter.Consume(ctx, "ak_daily", daily, now.Add(3*time.Hour)); r.Exceeded != LimitDaily {
+		t.Errorf("got %+v, want daily quota exceeded", r)
+	}
+
+	counts, _ := counter.Current(ctx, "ak_daily", now.Add(3*time.Hour))
+	if counts.Daily != 2 || counts.Monthly != 2 {
+		t.Errorf("Current = %+v", counts)
+	}
+
+	monthly := UsageLimits{Monthly: 2}
+	for i := 0; i < 2; i++ {
+		counter.Consume(ctx, "ak_monthly", monthly, now.AddDate(0, 0, i))
+	}
+	if r, _ := counter.Consume(ctx, "ak_monthly", monthly, now.AddDate(0, 0, 5)); r.Exceeded != LimitMonthly {
+		t.Errorf("got %+v, want monthly quota exceeded", r)
+	}
+	if r, _ := counter.Consume(ctx, "ak_monthly", monthly, now.AddDate(0, 1, 0)); r.Exceeded != LimitNone {
+		t.Errorf("got %+v, want quota reset in the next month", r)
+	}
+}
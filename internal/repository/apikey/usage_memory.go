@@ -0,0 +1,92 @@
+package apikey
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// usageBucket 一个计数周期内各密钥的请求数
+type usageBucket struct {
+	expiresAt time.Time
+	counts    map[string]int64
+}
+
+// memoryUsageCounter 基于内存的调用量计数器，未配置 Redis 时使用，多实例部署时各实例分别计数
+type memoryUsageCounter struct {
+	mu      sync.Mutex
+	buckets map[string]*usageBucket
+}
+
+// NewMemoryUsageCounter 创建基于内存的调用量计数器
+func NewMemoryUsageCounter() UsageCounter {
+	return &memoryUsageCounter{buckets: make(map[string]*usageBucket)}
+}
+
+// bucket 获取计数周期，创建新周期时顺带清理已过期的周期
+func (c *memoryUsageCounter) bucket(key string, expiresAt, now time.Time) *usageBucket {
+	if b, ok := c.buckets[key]; ok {
+		return b
+	}
+	for k, b := range c.buckets {
+		if !now.Before(b.expiresAt) {
+			delete(c.buckets, k)
+		}
+	}
+	b := &usageBucket{expiresAt: expiresAt, counts: make(map[string]int64)}
+	c.buckets[key] = b
+	return b
+}
+
+func (c *memoryUsageCounter) Consume(_ context.Context, apiKey string, limits UsageLimits, now time.Time) (*ConsumeResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 分钟计数按窗口单独保存，日、月计数与 Redis 实现的键一致
+	minute := c.bucket(rateKey("", now), minuteExpiry(now), now)
+	daily := c.bucket(dailyUsageKey(now), dayExpiry(now), now)
+	monthly := c.bucket(monthUsageKey(now), monthExpiry(now), now)
+
+	counts := UsageCounts{Minute: minute.counts[apiKey], Daily: daily.counts[apiKey], Monthly: monthly.counts[apiKey]}
+	if kind := exceeded(limits, counts); kind != LimitNone {
+		return &ConsumeResult{Exceeded: kind, Counts: counts}, nil
+	}
+
+	minute.counts[apiKey]++
+	daily.counts[apiKey]++
+	monthly.counts[apiKey]++
+	counts.Minute++
+	counts.Daily++
+	counts.Monthly++
+	return &ConsumeResult{Counts: counts}, nil
+}
+
+func (c *memoryUsageCounter) Current(_ context.Context, apiKey string, now time.Time) (UsageCounts, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var counts UsageCounts
+	if b, ok := c.buckets[rateKey("", now)]; ok {
+		counts.Minute = b.counts[apiKey]
+	}
+	if b, ok := c.buckets[dailyUsageKey(now)]; ok {
+		counts.Daily = b.counts[apiKey]
+	}
+	if b, ok := c.buckets[monthUsageKey(now)]; ok {
+		counts.Monthly = b.counts[apiKey]
+	}
+	return counts, nil
+}
+
+func (c *memoryUsageCounter) DailyCounts(_ context.Context, day time.Time) (map[string]int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[string]int64)
+	if b, ok := c.buckets[dailyUsageKey(day)]; ok {
+		for apiKey, n := range b.counts {
+			counts[apiKey] = n
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,129 @@
+package apikey
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"goWebExample/internal/infra/cache"
+)
+
+// Redis 键前缀，日计数与月计数为哈希，字段为API密钥
+const (
+	rateKeyPrefix       = "openapi:rate:"
+	dailyUsageKeyPrefix = "openapi:usage:day:"
+	monthUsageKeyPrefix = "openapi:usage:month:"
+)
+
+// consumeScript 检查限制并在未超出时计数
+//
+// KEYS: 分钟计数、日计数哈希、月计数哈希
+// ARGV: API密钥、每分钟上限、每日配额、每月配额、三个计数的过期时间（Unix 秒）
+// 返回: {超出的限制类型, 分钟计数, 日计数, 月计数}
+var consumeScript = redis.NewScript(`
+local minute = tonumber(redis.call('GET', KEYS[1]) or '0')
+local daily = tonumber(redis.call('HGET', KEYS[2], ARGV[1]) or '0')
+local monthly = tonumber(redis.call('HGET', KEYS[3], ARGV[1]) or '0')
+local rate, dq, mq = tonumber(ARGV[2]), tonumber(ARGV[3]), tonumber(ARGV[4])
+if rate > 0 and minute >= rate then return {1, minute, daily, monthly} end
+if dq > 0 and daily >= dq then return {2, minute, daily, monthly} end
+if mq > 0 and monthly >= mq then return {3, minute, daily, monthly} end
+minute = redis.call('INCR', KEYS[1])
+redis.call('EXPIREAT', KEYS[1], ARGV[5])
+daily = redis.call('HINCRBY', KEYS[2], ARGV[1], 1)
+redis.call('EXPIREAT', KEYS[2], ARGV[6])
+monthly = redis.call('HINCRBY', KEYS[3], ARGV[1], 1)
+redis.call('EXPIREAT', KEYS[3], ARGV[7])
+return {0, minute, daily, monthly}
+`)
+
+// redisUsageCounter 基于 Redis 的调用量计数器，多实例共享
+type redisUsageCounter struct {
+	connector *cache.RedisConnector
+}
+
+// NewRedisUsageCounter 创建基于 Redis 的调用量计数器
+func NewRedisUsageCounter(connector *cache.RedisConnector) UsageCounter {
+	return &redisUsageCounter{connector: connector}
+}
+
+func (c *redisUsageCounter) client() (*redis.Client, error) {
+	client := c.connector.GetClient()
+	if client == nil {
+		return nil, ErrRedisNotConnected
+	}
+	return client, nil
+}
+
+func rateKey(apiKey string, now time.Time) string {
+	return rateKeyPrefix + apiKey + ":" + strconv.FormatInt(minuteStart(now).Unix()/60, 10)
+}
+
+func dailyUsageKey(now time.Time) string {
+	return dailyUsageKeyPrefix + now.Format("20060102")
+}
+
+func monthUsageKey(now time.Time) string {
+	return monthUsageKeyPrefix + now.Format("200601")
+}
+
+func (c *redisUsageCounter) Consume(ctx context.Context, apiKey string, limits UsageLimits, now time.Time) (*ConsumeResult, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := consumeScript.Run(ctx, client,
+		[]string{rateKey(apiKey, now), dailyUsageKey(now), monthUsageKey(now)},
+		apiKey, limits.RatePerMinute, limits.Daily, limits.Monthly,
+		minuteExpiry(now).Unix(), dayExpiry(now).Unix(), monthExpiry(now).Unix(),
+	).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	return &ConsumeResult{
+		Exceeded: LimitKind(values[0]),
+		Counts:   UsageCounts{Minute: values[1], Daily: values[2], Monthly: values[3]},
+	}, nil
+}
+
+func (c *redisUsageCounter) Current(ctx context.Context, apiKey string, now time.Time) (UsageCounts, error) {
+	var counts UsageCounts
+	client, err := c.client()
+	if err != nil {
+		return counts, err
+	}
+
+	pipe := client.Pipeline()
+	minute := pipe.Get(ctx, rateKey(apiKey, now))
+	daily := pipe.HGet(ctx, dailyUsageKey(now), apiKey)
+	monthly := pipe.HGet(ctx, monthUsageKey(now), apiKey)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return counts, err
+	}
+	counts.Minute, _ = minute.Int64()
+	counts.Daily, _ = daily.Int64()
+	counts.Monthly, _ = monthly.Int64()
+	return counts, nil
+}
+
+func (c *redisUsageCounter) DailyCounts(ctx context.Context, day time.Time) (map[string]int64, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := client.HGetAll(ctx, dailyUsageKey(day)).Result()
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(values))
+	for apiKey, value := range values {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			counts[apiKey] = n
+		}
+	}
+	return counts, nil
+}
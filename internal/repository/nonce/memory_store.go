@@ -0,0 +1,45 @@
+package nonce
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval 每写入多少个 nonce 清理一次过期记录
+const sweepInterval = 1024
+
+// memoryStore 基于内存的 nonce 存储，未配置 Redis 时使用，多实例部署时无法跨实例拒绝重放
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+	writes  int
+}
+
+// NewMemoryStore 创建基于内存的 nonce 存储
+func NewMemoryStore() Store {
+	return &memoryStore{entries: make(map[string]time.Time)}
+}
+
+func (s *memoryStore) Use(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if expiresAt, ok := s.entries[key]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+	s.entries[key] = now.Add(ttl)
+
+	s.writes++
+	if s.writes >= sweepInterval {
+		s.writes = 0
+		for k, expiresAt := range s.entries {
+			if !now.Before(expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+	}
+	return true, nil
+}
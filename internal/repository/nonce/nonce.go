@@ -0,0 +1,30 @@
+package nonce
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"goWebExample/internal/infra/cache"
+	"goWebExample/internal/infra/di/factory"
+)
+
+// ErrRedisNotConnected Redis未连接错误
+var ErrRedisNotConnected = errors.New("Redis未连接")
+
+// Store 一次性随机数（nonce）存储，用于拒绝重放请求
+type Store interface {
+	// Use 原子地记录 nonce 并在 ttl 后过期，返回 false 表示 ttl 内已被使用过
+	Use(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// NewStoreFromFactory 创建 nonce 存储：工厂中注册了 Redis 连接器时使用 Redis，否则使用内存存储（仅适用于单实例部署）；
+// 第二个返回值为所用存储的名称，便于记录日志
+func NewStoreFromFactory(f *factory.Factory) (Store, string) {
+	if f != nil {
+		if redisConnector, ok := f.GetConnector("redis").(*cache.RedisConnector); ok {
+			return NewRedisStore(redisConnector), "redis"
+		}
+	}
+	return NewMemoryStore(), "memory"
+}
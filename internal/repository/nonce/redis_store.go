@@ -0,0 +1,29 @@
+package nonce
+
+import (
+	"context"
+	"time"
+
+	"goWebExample/internal/infra/cache"
+)
+
+// nonceKeyPrefix Redis 键前缀
+const nonceKeyPrefix = "openapi:nonce:"
+
+// redisStore 基于 Redis 的 nonce 存储，多实例共享
+type redisStore struct {
+	connector *cache.RedisConnector
+}
+
+// NewRedisStore 创建基于 Redis 的 nonce 存储
+func NewRedisStore(connector *cache.RedisConnector) Store {
+	return &redisStore{connector: connector}
+}
+
+func (s *redisStore) Use(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	client := s.connector.GetClient()
+	if client == nil {
+		return false, ErrRedisNotConnected
+	}
+	return client.SetNX(ctx, nonceKeyPrefix+key, 1, ttl).Result()
+}
@@ -0,0 +1,54 @@
+package rbac
+
+import (
+	"time"
+)
+
+// Role 角色
+type Role struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement;comment:'主键ID'" json:"id"`
+	Name        string    `gorm:"type:varchar(64);unique;not null;comment:'角色名'" json:"name"`
+	Description string    `gorm:"type:varchar(255);comment:'描述'" json:"description,omitempty"`
+	CreatedAt   time.Time `gorm:"type:datetime;default:CURRENT_TIMESTAMP;not null;comment:'创建时间'" json:"createdAt"`
+	UpdatedAt   time.Time `gorm:"type:datetime;default:CURRENT_TIMESTAMP;not null;autoUpdateTime;comment:'更新时间'" json:"updatedAt"`
+}
+
+// TableName 指定表名
+func (Role) TableName() string {
+	return "roles"
+}
+
+// Permission 权限，Code 格式为 资源:操作，支持 * 通配
+type Permission struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement;comment:'主键ID'" json:"id"`
+	Code        string    `gorm:"type:varchar(128);unique;not null;comment:'权限码'" json:"code"`
+	Description string    `gorm:"type:varchar(255);comment:'描述'" json:"description,omitempty"`
+	CreatedAt   time.Time `gorm:"type:datetime;default:CURRENT_TIMESTAMP;not null;comment:'创建时间'" json:"createdAt"`
+}
+
+// TableName 指定表名
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// RolePermission 角色与权限关联
+type RolePermission struct {
+	RoleID       uint64 `gorm:"primaryKey;comment:'角色ID'"`
+	PermissionID uint64 `gorm:"primaryKey;comment:'权限ID'"`
+}
+
+// TableName 指定表名
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+// UserRole 用户与角色关联
+type UserRole struct {
+	UserID uint64 `gorm:"primaryKey;comment:'用户ID'"`
+	RoleID uint64 `gorm:"primaryKey;comment:'角色ID'"`
+}
+
+// TableName 指定表名
+func (UserRole) TableName() string {
+	return "user_roles"
+}
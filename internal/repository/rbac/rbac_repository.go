@@ -0,0 +1,72 @@
+package rbac
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"goWebExample/internal/infra/db/mysql"
+)
+
+var (
+	// ErrDBNotConnected 数据库未连接错误
+	ErrDBNotConnected = errors.New("数据库未连接")
+)
+
+// RepositoryRBAC 角色权限数据操作接口
+type RepositoryRBAC interface {
+	GetUserRoles(userID uint64) ([]string, error)
+	GetUserPermissions(userID uint64) ([]string, error)
+}
+
+// rbacRepositoryImpl 角色权限仓库实现
+type rbacRepositoryImpl struct {
+	dbConnector *mysql.DBConnector
+}
+
+// NewRBACRepository 创建角色权限仓库
+func NewRBACRepository(dbConnector *mysql.DBConnector) RepositoryRBAC {
+	return &rbacRepositoryImpl{dbConnector: dbConnector}
+}
+
+func (r *rbacRepositoryImpl) getDB() (*gorm.DB, error) {
+	db := r.dbConnector.GetDB()
+	if db == nil {
+		return nil, ErrDBNotConnected
+	}
+	return db, nil
+}
+
+// GetUserRoles 获取用户的角色名列表
+func (r *rbacRepositoryImpl) GetUserRoles(userID uint64) ([]string, error) {
+	db, err := r.getDB()
+	if err != nil {
+		return nil, err
+	}
+
+	var roles []string
+	err = db.Model(&Role{}).
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.name").
+		Pluck("roles.name", &roles).Error
+	return roles, err
+}
+
+// GetUserPermissions 获取用户通过角色获得的全部权限码（去重）
+func (r *rbacRepositoryImpl) GetUserPermissions(userID uint64) ([]string, error) {
+	db, err := r.getDB()
+	if err != nil {
+		return nil, err
+	}
+
+	var permissions []string
+	err = db.Model(&Permission{}).
+		Distinct("permissions.code").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Order("permissions.code").
+		Pluck("permissions.code", &permissions).Error
+	return permissions, err
+}
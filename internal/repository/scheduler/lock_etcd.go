@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"goWebExample/internal/infra/discovery"
+)
+
+// etcdLocker implements the Locker interface with etcd keys bound to a lease
+type etcdLocker struct {
+	connector *discovery.EtcdConnector
+	nodeID    string
+}
+
+// NewEtcdLocker creates a locker backed by etcd
+func NewEtcdLocker(connector *discovery.EtcdConnector, nodeID string) Locker {
+	return &etcdLocker{
+		connector: connector,
+		nodeID:    nodeID,
+	}
+}
+
+// leaseSeconds converts a lock ttl to a lease ttl, etcd leases have a granularity of one second
+func leaseSeconds(ttl time.Duration) int64 {
+	seconds := int64((ttl + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// Acquire takes the lock for ttl. The fencing token is the etcd revision the lock key was created at.
+func (l *etcdLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	client := l.connector.GetClient()
+	if client == nil {
+		return nil, ErrEtcdNotConnected
+	}
+
+	lease, err := client.Grant(ctx, leaseSeconds(ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	lockKey := LockKeyPrefix + key
+	owner := newLockOwner(l.nodeID)
+	resp, err := client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(lockKey), "=", 0)).
+		Then(clientv3.OpPut(lockKey, owner, clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil || !resp.Succeeded {
+		// Revoke the unused lease so that it does not linger until it expires
+		revokeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _ = client.Revoke(revokeCtx, lease.ID)
+
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrLockHeld
+	}
+
+	return &etcdLock{
+		client:  client,
+		leaseID: lease.ID,
+		token:   resp.Header.Revision,
+	}, nil
+}
+
+// etcdLock is a lock held in etcd
+type etcdLock struct {
+	client  *clientv3.Client
+	leaseID clientv3.LeaseID
+	token   int64
+}
+
+// Token returns the fencing token of the lock
+func (l *etcdLock) Token() int64 {
+	return l.token
+}
+
+// Refresh extends the lock. The lease keeps the ttl it was granted with, so ttl is ignored.
+func (l *etcdLock) Refresh(ctx context.Context, _ time.Duration) error {
+	if _, err := l.client.KeepAliveOnce(ctx, l.leaseID); err != nil {
+		if errors.Is(err, rpctypes.ErrLeaseNotFound) {
+			return ErrLockLost
+		}
+		return err
+	}
+	return nil
+}
+
+// Release gives up the lock, revoking the lease deletes the lock key
+func (l *etcdLock) Release(ctx context.Context) error {
+	if _, err := l.client.Revoke(ctx, l.leaseID); err != nil && !errors.Is(err, rpctypes.ErrLeaseNotFound) {
+		return err
+	}
+	return nil
+}
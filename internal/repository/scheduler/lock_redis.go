@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"goWebExample/internal/infra/cache"
+)
+
+// lockFenceKey is the counter the fencing tokens of all Redis locks are taken from
+const lockFenceKey = LockKeyPrefix + "fence"
+
+// acquireScript sets the lock if it is free and returns a new fencing token, or 0 if the lock is held
+//
+// KEYS: lock key, fencing counter
+// ARGV: owner, ttl in milliseconds
+var acquireScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0
+`)
+
+// refreshScript extends the lock if it is still held by the owner, returns 0 otherwise
+//
+// KEYS: lock key
+// ARGV: owner, ttl in milliseconds
+var refreshScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lock if it is still held by the owner
+//
+// KEYS: lock key
+// ARGV: owner
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// redisLocker implements the Locker interface with Redis SET NX PX
+type redisLocker struct {
+	connector *cache.RedisConnector
+	nodeID    string
+}
+
+// NewRedisLocker creates a locker backed by Redis
+func NewRedisLocker(connector *cache.RedisConnector, nodeID string) Locker {
+	return &redisLocker{
+		connector: connector,
+		nodeID:    nodeID,
+	}
+}
+
+// Acquire takes the lock for ttl
+func (l *redisLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	client := l.connector.GetClient()
+	if client == nil {
+		return nil, ErrRedisNotConnected
+	}
+
+	lockKey := LockKeyPrefix + key
+	owner := newLockOwner(l.nodeID)
+	token, err := acquireScript.Run(ctx, client, []string{lockKey, lockFenceKey}, owner, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return nil, err
+	}
+	if token == 0 {
+		return nil, ErrLockHeld
+	}
+
+	return &redisLock{
+		client: client,
+		key:    lockKey,
+		owner:  owner,
+		token:  token,
+	}, nil
+}
+
+// redisLock is a lock held in Redis
+type redisLock struct {
+	client *redis.Client
+	key    string
+	owner  string
+	token  int64
+}
+
+// Token returns the fencing token of the lock
+func (l *redisLock) Token() int64 {
+	return l.token
+}
+
+// Refresh extends the lock
+func (l *redisLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	ok, err := refreshScript.Run(ctx, l.client, []string{l.key}, l.owner, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Release gives up the lock
+func (l *redisLock) Release(ctx context.Context) error {
+	return releaseScript.Run(ctx, l.client, []string{l.key}, l.owner).Err()
+}
@@ -14,7 +14,9 @@ const (
 	TaskStatusRunning TaskStatus = "running"
 	// TaskStatusCompleted indicates the task has completed successfully
 	TaskStatusCompleted TaskStatus = "completed"
-	// TaskStatusFailed indicates the task has failed
+	// TaskStatusRetrying indicates an attempt of the task has failed and it waits to be retried
+	TaskStatusRetrying TaskStatus = "retrying"
+	// TaskStatusFailed indicates the task has failed after all of its attempts
 	TaskStatusFailed TaskStatus = "failed"
 	// TaskStatusDisabled indicates the task is disabled and should not be executed
 	TaskStatusDisabled TaskStatus = "disabled"
@@ -25,6 +27,9 @@ type TaskModel struct {
 	ID          string     `gorm:"primaryKey;type:varchar(64)" json:"id"`
 	Description string     `gorm:"type:varchar(255)" json:"description"`
 	Schedule    string     `gorm:"type:varchar(100)" json:"schedule"`
+	JobType     string     `gorm:"type:varchar(64);index" json:"job_type"`
+	Params      string     `gorm:"type:text" json:"params"`
+	Policy      string     `gorm:"type:text" json:"policy"`
 	Status      TaskStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
 	LastRun     *time.Time `gorm:"type:datetime" json:"last_run"`
 	NextRun     *time.Time `gorm:"type:datetime" json:"next_run"`
@@ -44,6 +49,9 @@ func (m *TaskModel) ToTask() *Task {
 		ID:          m.ID,
 		Description: m.Description,
 		Schedule:    m.Schedule,
+		JobType:     m.JobType,
+		Params:      m.Params,
+		Policy:      m.Policy,
 		Status:      m.Status,
 		LastRun:     m.LastRun,
 		NextRun:     m.NextRun,
@@ -59,6 +67,9 @@ type Task struct {
 	ID          string     `json:"id"`
 	Description string     `json:"description"`
 	Schedule    string     `json:"schedule"`
+	JobType     string     `json:"job_type"`
+	Params      string     `json:"params"`
+	Policy      string     `json:"policy"`
 	Status      TaskStatus `json:"status"`
 	LastRun     *time.Time `json:"last_run"`
 	NextRun     *time.Time `json:"next_run"`
@@ -76,6 +87,9 @@ func (t *Task) ToModel() *TaskModel {
 		ID:          t.ID,
 		Description: t.Description,
 		Schedule:    t.Schedule,
+		JobType:     t.JobType,
+		Params:      t.Params,
+		Policy:      t.Policy,
 		Status:      t.Status,
 		LastRun:     t.LastRun,
 		NextRun:     t.NextRun,
@@ -35,6 +35,12 @@ type TaskCache interface {
 	UpdateLastRun(ctx context.Context, id string, lastRun time.Time, status TaskStatus, lastError string) error
 	// UpdateNextRun updates the next run time of a task in the cache
 	UpdateNextRun(ctx context.Context, id string, nextRun time.Time) error
+	// UpdateSchedule updates the schedule and next run time of a task in the cache
+	UpdateSchedule(ctx context.Context, id string, schedule string, nextRun *time.Time) error
+	// UpdateJob updates the job type and params of a task in the cache
+	UpdateJob(ctx context.Context, id string, jobType string, params string) error
+	// UpdatePolicy updates the retry and timeout policy of a task in the cache
+	UpdatePolicy(ctx context.Context, id string, policy string) error
 }
 
 // taskCache implements the TaskCache interface
@@ -193,9 +199,11 @@ func (c *taskCache) UpdateLastRun(ctx context.Context, id string, lastRun time.T
 		return err
 	}
 
-	// Update the last run information
+	// Update the last run information, a disabled task stays disabled
 	task.LastRun = &lastRun
-	task.Status = status
+	if task.Status != TaskStatusDisabled {
+		task.Status = status
+	}
 	task.LastError = lastError
 	task.UpdatedAt = time.Now()
 
@@ -217,4 +225,54 @@ func (c *taskCache) UpdateNextRun(ctx context.Context, id string, nextRun time.T
 
 	// Store the updated task
 	return c.Set(ctx, task)
-}
\ No newline at end of file
+}
+
+// UpdateSchedule updates the schedule and next run time of a task in the cache
+func (c *taskCache) UpdateSchedule(ctx context.Context, id string, schedule string, nextRun *time.Time) error {
+	// Get the task from cache
+	task, err := c.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Update the schedule
+	task.Schedule = schedule
+	task.NextRun = nextRun
+	task.UpdatedAt = time.Now()
+
+	// Store the updated task
+	return c.Set(ctx, task)
+}
+
+// UpdateJob updates the job type and params of a task in the cache
+func (c *taskCache) UpdateJob(ctx context.Context, id string, jobType string, params string) error {
+	// Get the task from cache
+	task, err := c.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Update the job
+	task.JobType = jobType
+	task.Params = params
+	task.UpdatedAt = time.Now()
+
+	// Store the updated task
+	return c.Set(ctx, task)
+}
+
+// UpdatePolicy updates the retry and timeout policy of a task in the cache
+func (c *taskCache) UpdatePolicy(ctx context.Context, id string, policy string) error {
+	// Get the task from cache
+	task, err := c.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Update the policy
+	task.Policy = policy
+	task.UpdatedAt = time.Now()
+
+	// Store the updated task
+	return c.Set(ctx, task)
+}
@@ -34,8 +34,14 @@ type TaskRepository interface {
 	UpdateStatus(ctx context.Context, id string, status TaskStatus) error
 	// UpdateNextRun updates the next run time of a task
 	UpdateNextRun(ctx context.Context, id string, nextRun time.Time) error
-	// UpdateLastRun updates the last run time and status of a task
+	// UpdateLastRun updates the last run time and status of a task, a disabled task stays disabled
 	UpdateLastRun(ctx context.Context, id string, lastRun time.Time, status TaskStatus, lastError string) error
+	// UpdateSchedule updates the schedule and next run time of a task
+	UpdateSchedule(ctx context.Context, id string, schedule string, nextRun *time.Time) error
+	// UpdateJob updates the job type and params of a task
+	UpdateJob(ctx context.Context, id string, jobType string, params string) error
+	// UpdatePolicy updates the retry and timeout policy of a task
+	UpdatePolicy(ctx context.Context, id string, policy string) error
 }
 
 // taskRepository implements the TaskRepository interface
@@ -166,11 +172,67 @@ func (r *taskRepository) UpdateNextRun(ctx context.Context, id string, nextRun t
 	return nil
 }
 
-// UpdateLastRun updates the last run time and status of a task
+// UpdateSchedule updates the schedule and next run time of a task
+func (r *taskRepository) UpdateSchedule(ctx context.Context, id string, schedule string, nextRun *time.Time) error {
+	updates := map[string]interface{}{
+		"schedule": schedule,
+		"next_run": nextRun,
+	}
+
+	result := r.db.WithContext(ctx).Model(&TaskModel{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update task schedule: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+
+	r.logger.Info("Task schedule updated", zap.String("id", id), zap.String("schedule", schedule))
+	return nil
+}
+
+// UpdateJob updates the job type and params of a task
+func (r *taskRepository) UpdateJob(ctx context.Context, id string, jobType string, params string) error {
+	updates := map[string]interface{}{
+		"job_type": jobType,
+		"params":   params,
+	}
+
+	result := r.db.WithContext(ctx).Model(&TaskModel{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update task job: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+
+	r.logger.Info("Task job updated", zap.String("id", id), zap.String("jobType", jobType))
+	return nil
+}
+
+// UpdatePolicy updates the retry and timeout policy of a task, an empty policy means the default policy
+func (r *taskRepository) UpdatePolicy(ctx context.Context, id string, policy string) error {
+	result := r.db.WithContext(ctx).Model(&TaskModel{}).Where("id = ?", id).Update("policy", policy)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update task policy: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+
+	r.logger.Info("Task policy updated", zap.String("id", id))
+	return nil
+}
+
+// UpdateLastRun updates the last run time and status of a task. A disabled task keeps its status,
+// so that a run finishing after the task was paused, possibly on another replica, does not enable it again.
 func (r *taskRepository) UpdateLastRun(ctx context.Context, id string, lastRun time.Time, status TaskStatus, lastError string) error {
 	updates := map[string]interface{}{
 		"last_run":   lastRun,
-		"status":     status,
+		"status":     gorm.Expr("CASE WHEN status = ? THEN status ELSE ? END", TaskStatusDisabled, status),
 		"last_error": lastError,
 	}
 
@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"time"
+)
+
+// MaxRunOutputLength is the maximum length of the output stored for a run, longer output is truncated
+const MaxRunOutputLength = 64 * 1024
+
+// TaskRunModel represents a single execution of a scheduled task in the database
+type TaskRunModel struct {
+	ID         string     `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	TaskID     string     `gorm:"type:varchar(64);index:idx_scheduler_task_runs_task_started,priority:1" json:"task_id"`
+	StartedAt  time.Time  `gorm:"type:datetime(3);index:idx_scheduler_task_runs_task_started,priority:2;index:idx_scheduler_task_runs_started_at" json:"started_at"`
+	FinishedAt *time.Time `gorm:"type:datetime(3)" json:"finished_at"`
+	DurationMs int64      `gorm:"default:0" json:"duration_ms"`
+	Status     TaskStatus `gorm:"type:varchar(20)" json:"status"`
+	Error      string     `gorm:"type:text" json:"error"`
+	Output     string     `gorm:"type:mediumtext" json:"output"`
+	Node       string     `gorm:"type:varchar(128)" json:"node"`
+	Trigger    string     `gorm:"type:varchar(20)" json:"trigger"`
+	Attempt    int        `gorm:"default:1" json:"attempt"`
+}
+
+// TableName specifies the table name for the TaskRunModel
+func (TaskRunModel) TableName() string {
+	return "scheduler_task_runs"
+}
+
+// Run triggers
+const (
+	// RunTriggerSchedule indicates the run was fired by the schedule
+	RunTriggerSchedule = "schedule"
+	// RunTriggerManual indicates the run was triggered manually through the admin API
+	RunTriggerManual = "manual"
+)
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TaskRunRepository defines the interface for task run history operations
+type TaskRunRepository interface {
+	// Create records the start of a run
+	Create(ctx context.Context, run *TaskRunModel) error
+	// Finish records the end of a run
+	Finish(ctx context.Context, id string, finishedAt time.Time, duration time.Duration, status TaskStatus, runErr string, output string) error
+	// FindByTask returns a page of runs of a task, most recent first, and the total number of runs
+	FindByTask(ctx context.Context, taskID string, offset, limit int) ([]*TaskRunModel, int64, error)
+	// DeleteBefore deletes runs started before the given time and returns the number of deleted runs
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// taskRunRepository implements the TaskRunRepository interface
+type taskRunRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewTaskRunRepository creates a new task run repository
+func NewTaskRunRepository(db *gorm.DB, logger *zap.Logger) TaskRunRepository {
+	return &taskRunRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create records the start of a run
+func (r *taskRunRepository) Create(ctx context.Context, run *TaskRunModel) error {
+	if err := r.db.WithContext(ctx).Create(run).Error; err != nil {
+		return fmt.Errorf("failed to create task run: %w", err)
+	}
+	return nil
+}
+
+// Finish records the end of a run
+func (r *taskRunRepository) Finish(ctx context.Context, id string, finishedAt time.Time, duration time.Duration, status TaskStatus, runErr string, output string) error {
+	if len(output) > MaxRunOutputLength {
+		output = strings.ToValidUTF8(output[:MaxRunOutputLength], "")
+	}
+
+	updates := map[string]interface{}{
+		"finished_at": finishedAt,
+		"duration_ms": duration.Milliseconds(),
+		"status":      status,
+		"error":       runErr,
+		"output":      output,
+	}
+	if err := r.db.WithContext(ctx).Model(&TaskRunModel{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to finish task run: %w", err)
+	}
+	return nil
+}
+
+// FindByTask returns a page of runs of a task, most recent first
+func (r *taskRunRepository) FindByTask(ctx context.Context, taskID string, offset, limit int) ([]*TaskRunModel, int64, error) {
+	tx := r.db.WithContext(ctx).Model(&TaskRunModel{}).Where("task_id = ?", taskID)
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count task runs: %w", err)
+	}
+
+	var runs []*TaskRunModel
+	if err := tx.Order("started_at DESC").Offset(offset).Limit(limit).Find(&runs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to find task runs: %w", err)
+	}
+	return runs, total, nil
+}
+
+// DeleteBefore deletes runs started before the given time
+func (r *taskRunRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("started_at < ?", before).Delete(&TaskRunModel{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete task runs: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		r.logger.Info("Task runs pruned",
+			zap.Time("before", before),
+			zap.Int64("count", result.RowsAffected))
+	}
+	return result.RowsAffected, nil
+}
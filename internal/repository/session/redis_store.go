@@ -0,0 +1,215 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"goWebExample/internal/infra/cache"
+)
+
+// Redis 键前缀
+const (
+	sessionKeyPrefix     = "auth:session:"
+	userSessionKeyPrefix = "auth:user:sessions:" // 有序集合，成员为会话ID，分值为绝对过期时间
+)
+
+// redisStore 基于 Redis 的会话存储
+type redisStore struct {
+	connector *cache.RedisConnector
+}
+
+// NewRedisStore 创建基于 Redis 的会话存储
+func NewRedisStore(connector *cache.RedisConnector) Store {
+	return &redisStore{connector: connector}
+}
+
+func (s *redisStore) client() (*redis.Client, error) {
+	client := s.connector.GetClient()
+	if client == nil {
+		return nil, ErrRedisNotConnected
+	}
+	return client, nil
+}
+
+func userSessionKey(userID uint64) string {
+	return userSessionKeyPrefix + strconv.FormatUint(userID, 10)
+}
+
+// sessionTTL 空闲超时不能超过会话的绝对过期时间
+func sessionTTL(session *Session, ttl time.Duration) time.Duration {
+	if remaining := time.Until(session.ExpiresAt); remaining < ttl {
+		return remaining
+	}
+	return ttl
+}
+
+func (s *redisStore) Create(ctx context.Context, session *Session, ttl time.Duration) error {
+	client, err := s.client()
+	if err != nil {
+		return err
+	}
+
+	ttl = sessionTTL(session, ttl)
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	userKey := userSessionKey(session.UserID)
+	pipe := client.TxPipeline()
+	pipe.Set(ctx, sessionKeyPrefix+session.ID, data, ttl)
+	pipe.ZAdd(ctx, userKey, &redis.Z{Score: float64(session.ExpiresAt.Unix()), Member: session.ID})
+	// 新会话的绝对过期时间最晚，索引随之过期
+	pipe.ExpireAt(ctx, userKey, session.ExpiresAt)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *redisStore) Touch(ctx context.Context, session *Session, ttl time.Duration) error {
+	client, err := s.client()
+	if err != nil {
+		return err
+	}
+
+	ttl = sessionTTL(session, ttl)
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	// 只更新仍存在的会话，避免与删除并发时把已删除的会话写回
+	return client.SetXX(ctx, sessionKeyPrefix+session.ID, data, ttl).Err()
+}
+
+func (s *redisStore) Get(ctx context.Context, id string) (*Session, error) {
+	client, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := client.Get(ctx, sessionKeyPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *redisStore) Delete(ctx context.Context, id string) error {
+	session, err := s.Get(ctx, id)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	client, err := s.client()
+	if err != nil {
+		return err
+	}
+	pipe := client.TxPipeline()
+	pipe.Del(ctx, sessionKeyPrefix+id)
+	pipe.ZRem(ctx, userSessionKey(session.UserID), id)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *redisStore) ListByUser(ctx context.Context, userID uint64) ([]*Session, error) {
+	client, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+
+	userKey := userSessionKey(userID)
+	// 先清理已过绝对过期时间的成员
+	if err := client.ZRemRangeByScore(ctx, userKey, "-inf", strconv.FormatInt(time.Now().Unix(), 10)).Err(); err != nil {
+		return nil, err
+	}
+	ids, err := client.ZRange(ctx, userKey, 0, -1).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = sessionKeyPrefix + id
+	}
+	values, err := client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*Session, 0, len(values))
+	var stale []interface{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			// 因空闲超时已过期的会话
+			stale = append(stale, ids[i])
+			continue
+		}
+		var session Session
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+	if len(stale) > 0 {
+		if err := client.ZRem(ctx, userKey, stale...).Err(); err != nil {
+			return nil, err
+		}
+	}
+	return sessions, nil
+}
+
+func (s *redisStore) DeleteByUser(ctx context.Context, userID uint64, exceptID string) (int, error) {
+	client, err := s.client()
+	if err != nil {
+		return 0, err
+	}
+
+	userKey := userSessionKey(userID)
+	ids, err := client.ZRange(ctx, userKey, 0, -1).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var keys []string
+	var members []interface{}
+	for _, id := range ids {
+		if id == exceptID {
+			continue
+		}
+		keys = append(keys, sessionKeyPrefix+id)
+		members = append(members, id)
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	pipe := client.TxPipeline()
+	deleted := pipe.Del(ctx, keys...)
+	pipe.ZRem(ctx, userKey, members...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int(deleted.Val()), nil
+}
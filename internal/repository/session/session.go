@@ -0,0 +1,51 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRedisNotConnected Redis未连接错误
+	ErrRedisNotConnected = errors.New("Redis未连接")
+	// ErrSessionNotFound 会话不存在或已过期
+	ErrSessionNotFound = errors.New("会话不存在")
+)
+
+// Session 服务端会话，ID 为会话 Cookie 值的 SHA-256 摘要，Cookie 值本身不落库
+//
+// 会话中保存用户信息快照，避免每个请求都查询角色权限，快照在会话续期时刷新；
+// 安全戳变化（修改密码、退出其他设备、禁用或删除用户）后会话随之失效。
+type Session struct {
+	ID          string    `json:"id"`
+	UserID      uint64    `json:"userId"`
+	UserUUID    string    `json:"userUuid"`
+	Username    string    `json:"username"`
+	Nickname    string    `json:"nickname,omitempty"`
+	IsAdmin     bool      `json:"isAdmin"`
+	Roles       []string  `json:"roles,omitempty"`
+	Permissions []string  `json:"perms,omitempty"`
+	Stamp       string    `json:"stamp,omitempty"`
+	IP          string    `json:"ip,omitempty"`
+	UserAgent   string    `json:"userAgent,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	LastSeenAt  time.Time `json:"lastSeenAt"`
+	ExpiresAt   time.Time `json:"expiresAt"` // 绝对过期时间，空闲超时由存储的 TTL 控制
+}
+
+// Store 会话存储接口
+type Store interface {
+	// Create 保存新会话，ttl 为空闲超时，不会超过会话的绝对过期时间
+	Create(ctx context.Context, s *Session, ttl time.Duration) error
+	// Touch 更新会话最后访问时间并续期，会话已过期时不会重新创建
+	Touch(ctx context.Context, s *Session, ttl time.Duration) error
+	// Get 获取会话，不存在或已过期时返回 ErrSessionNotFound
+	Get(ctx context.Context, id string) (*Session, error)
+	// Delete 删除会话
+	Delete(ctx context.Context, id string) error
+	// ListByUser 列出用户的全部有效会话
+	ListByUser(ctx context.Context, userID uint64) ([]*Session, error)
+	// DeleteByUser 删除用户除 exceptID 外的全部会话，返回删除数量
+	DeleteByUser(ctx context.Context, userID uint64, exceptID string) (int, error)
+}
@@ -0,0 +1,213 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"goWebExample/internal/infra/db/mysql"
+)
+
+// RefreshTokenModel refresh token 表模型
+type RefreshTokenModel struct {
+	TokenHash string     `gorm:"column:token_hash;type:char(64);primaryKey;comment:'refresh token SHA-256 摘要'"`
+	FamilyID  string     `gorm:"column:family_id;type:char(36);index;not null;comment:'token 家族ID'"`
+	UserID    uint64     `gorm:"column:user_id;index;not null;comment:'用户ID'"`
+	Stamp     string     `gorm:"column:security_stamp;type:varchar(100);not null;default:'';comment:'签发时的用户安全戳'"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;type:datetime;index;not null;comment:'过期时间'"`
+	UsedAt    *time.Time `gorm:"column:used_at;type:datetime;comment:'轮换使用时间'"`
+	RevokedAt *time.Time `gorm:"column:revoked_at;type:datetime;comment:'吊销时间'"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:datetime;not null;comment:'创建时间'"`
+}
+
+// TableName 指定表名
+func (RefreshTokenModel) TableName() string {
+	return "user_refresh_tokens"
+}
+
+// RevokedTokenModel access token 吊销列表表模型
+type RevokedTokenModel struct {
+	JTI       string    `gorm:"column:jti;type:varchar(64);primaryKey;comment:'access token jti'"`
+	ExpiresAt time.Time `gorm:"column:expires_at;type:datetime;index;not null;comment:'token 过期时间'"`
+	CreatedAt time.Time `gorm:"column:created_at;type:datetime;not null;comment:'吊销时间'"`
+}
+
+// TableName 指定表名
+func (RevokedTokenModel) TableName() string {
+	return "user_revoked_tokens"
+}
+
+// UsedActionTokenModel 已使用的一次性操作 token 表模型
+type UsedActionTokenModel struct {
+	JTI       string    `gorm:"column:jti;type:varchar(64);primaryKey;comment:'操作 token jti'"`
+	ExpiresAt time.Time `gorm:"column:expires_at;type:datetime;index;not null;comment:'token 过期时间'"`
+	CreatedAt time.Time `gorm:"column:created_at;type:datetime;not null;comment:'使用时间'"`
+}
+
+// TableName 指定表名
+func (UsedActionTokenModel) TableName() string {
+	return "user_used_action_tokens"
+}
+
+// mysqlStore 基于 MySQL 的存储实现
+type mysqlStore struct {
+	dbConnector *mysql.DBConnector
+}
+
+// NewMySQLStore 创建基于 MySQL 的存储
+func NewMySQLStore(dbConnector *mysql.DBConnector) Store {
+	return &mysqlStore{dbConnector: dbConnector}
+}
+
+func (s *mysqlStore) getDB(ctx context.Context) (*gorm.DB, error) {
+	db := s.dbConnector.GetDB()
+	if db == nil {
+		return nil, ErrDBNotConnected
+	}
+	return db.WithContext(ctx), nil
+}
+
+func (s *mysqlStore) SaveRefreshToken(ctx context.Context, token *RefreshToken) error {
+	db, err := s.getDB(ctx)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(&RefreshTokenModel{
+		TokenHash: token.TokenHash,
+		FamilyID:  token.FamilyID,
+		UserID:    token.UserID,
+		Stamp:     token.Stamp,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+	}).Error
+}
+
+func (s *mysqlStore) GetRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	db, err := s.getDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var model RefreshTokenModel
+	err = db.Where("token_hash = ? AND expires_at > ?", tokenHash, time.Now()).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &RefreshToken{
+		TokenHash: model.TokenHash,
+		FamilyID:  model.FamilyID,
+		UserID:    model.UserID,
+		Stamp:     model.Stamp,
+		ExpiresAt: model.ExpiresAt,
+		CreatedAt: model.CreatedAt,
+	}, nil
+}
+
+func (s *mysqlStore) MarkRefreshTokenUsed(ctx context.Context, tokenHash string) (bool, error) {
+	db, err := s.getDB(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	tx := db.Model(&RefreshTokenModel{}).
+		Where("token_hash = ? AND used_at IS NULL", tokenHash).
+		Update("used_at", time.Now())
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	return tx.RowsAffected == 1, nil
+}
+
+func (s *mysqlStore) RevokeFamily(ctx context.Context, familyID string, _ time.Duration) error {
+	db, err := s.getDB(ctx)
+	if err != nil {
+		return err
+	}
+
+	return db.Model(&RefreshTokenModel{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (s *mysqlStore) IsFamilyRevoked(ctx context.Context, familyID string) (bool, error) {
+	db, err := s.getDB(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	err = db.Model(&RefreshTokenModel{}).
+		Where("family_id = ? AND revoked_at IS NOT NULL", familyID).
+		Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+func (s *mysqlStore) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	db, err := s.getDB(ctx)
+	if err != nil {
+		return err
+	}
+
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&RevokedTokenModel{
+		JTI:       jti,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}).Error
+}
+
+func (s *mysqlStore) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	db, err := s.getDB(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	err = db.Model(&RevokedTokenModel{}).
+		Where("jti = ? AND expires_at > ?", jti, time.Now()).
+		Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+func (s *mysqlStore) ConsumeActionToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	db, err := s.getDB(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	// 主键冲突时不插入，RowsAffected 为 0 即表示已被使用
+	tx := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&UsedActionTokenModel{
+		JTI:       jti,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	})
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	return tx.RowsAffected == 1, nil
+}
+
+func (s *mysqlStore) PruneExpired(ctx context.Context, before time.Time) (int64, error) {
+	db, err := s.getDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// 已过期的 token 无法再通过校验，删除其记录不影响轮换重放检测与吊销
+	var deleted int64
+	for _, model := range []interface{}{&RefreshTokenModel{}, &RevokedTokenModel{}, &UsedActionTokenModel{}} {
+		tx := db.Where("expires_at < ?", before).Delete(model)
+		if tx.Error != nil {
+			return deleted, tx.Error
+		}
+		deleted += tx.RowsAffected
+	}
+	return deleted, nil
+}
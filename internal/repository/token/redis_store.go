@@ -0,0 +1,150 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"goWebExample/internal/infra/cache"
+)
+
+// Redis 键前缀
+const (
+	refreshTokenKeyPrefix  = "auth:refresh:"
+	refreshUsedKeyPrefix   = "auth:refresh:used:"
+	familyRevokedKeyPrefix = "auth:family:revoked:"
+	revokedTokenKeyPrefix  = "auth:revoked:"
+	actionUsedKeyPrefix    = "auth:action:used:"
+)
+
+// redisStore 基于 Redis 的存储实现，所有键都带有过期时间，无需额外清理
+type redisStore struct {
+	connector *cache.RedisConnector
+}
+
+// NewRedisStore 创建基于 Redis 的存储
+func NewRedisStore(connector *cache.RedisConnector) Store {
+	return &redisStore{connector: connector}
+}
+
+func (s *redisStore) client() (*redis.Client, error) {
+	client := s.connector.GetClient()
+	if client == nil {
+		return nil, ErrRedisNotConnected
+	}
+	return client, nil
+}
+
+func (s *redisStore) SaveRefreshToken(ctx context.Context, token *RefreshToken) error {
+	client, err := s.client()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return client.Set(ctx, refreshTokenKeyPrefix+token.TokenHash, data, time.Until(token.ExpiresAt)).Err()
+}
+
+func (s *redisStore) GetRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	client, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := client.Get(ctx, refreshTokenKeyPrefix+tokenHash).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var token RefreshToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *redisStore) MarkRefreshTokenUsed(ctx context.Context, tokenHash string) (bool, error) {
+	client, err := s.client()
+	if err != nil {
+		return false, err
+	}
+
+	// 已使用标记需要比 token 本身保留更久，以便在 token 过期前都能识别出重放
+	ttl, err := client.TTL(ctx, refreshTokenKeyPrefix+tokenHash).Result()
+	if err != nil {
+		return false, err
+	}
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	return client.SetNX(ctx, refreshUsedKeyPrefix+tokenHash, 1, ttl).Result()
+}
+
+func (s *redisStore) RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error {
+	client, err := s.client()
+	if err != nil {
+		return err
+	}
+	return client.Set(ctx, familyRevokedKeyPrefix+familyID, 1, ttl).Err()
+}
+
+func (s *redisStore) IsFamilyRevoked(ctx context.Context, familyID string) (bool, error) {
+	client, err := s.client()
+	if err != nil {
+		return false, err
+	}
+
+	n, err := client.Exists(ctx, familyRevokedKeyPrefix+familyID).Result()
+	return n > 0, err
+}
+
+func (s *redisStore) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	client, err := s.client()
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		// 已过期的 token 无需加入吊销列表
+		return nil
+	}
+	return client.Set(ctx, revokedTokenKeyPrefix+jti, 1, ttl).Err()
+}
+
+func (s *redisStore) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	client, err := s.client()
+	if err != nil {
+		return false, err
+	}
+
+	n, err := client.Exists(ctx, revokedTokenKeyPrefix+jti).Result()
+	return n > 0, err
+}
+
+func (s *redisStore) ConsumeActionToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	client, err := s.client()
+	if err != nil {
+		return false, err
+	}
+
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	return client.SetNX(ctx, actionUsedKeyPrefix+jti, 1, ttl).Result()
+}
+
+// PruneExpired Redis 中的键到期自动删除，无需清理
+func (s *redisStore) PruneExpired(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,63 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"goWebExample/internal/infra/cache"
+	"goWebExample/internal/infra/db/mysql"
+	"goWebExample/internal/infra/di/factory"
+)
+
+var (
+	// ErrDBNotConnected 数据库未连接错误
+	ErrDBNotConnected = errors.New("数据库未连接")
+	// ErrRedisNotConnected Redis未连接错误
+	ErrRedisNotConnected = errors.New("Redis未连接")
+	// ErrRefreshTokenNotFound refresh token 不存在或已过期
+	ErrRefreshTokenNotFound = errors.New("refresh token不存在")
+)
+
+// RefreshToken 服务端保存的 refresh token 记录，token 本身只保存 SHA-256 摘要
+type RefreshToken struct {
+	TokenHash string    `json:"tokenHash"`
+	FamilyID  string    `json:"familyId"` // 同一次登录轮换出的 token 属于同一家族
+	UserID    uint64    `json:"userId"`
+	Stamp     string    `json:"stamp,omitempty"` // 签发时的用户安全戳，与当前安全戳不一致时 token 失效
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Store refresh token 与 access token 吊销列表的存储接口
+type Store interface {
+	// SaveRefreshToken 保存 refresh token
+	SaveRefreshToken(ctx context.Context, token *RefreshToken) error
+	// GetRefreshToken 根据摘要获取 refresh token，不存在或已过期时返回 ErrRefreshTokenNotFound
+	GetRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	// MarkRefreshTokenUsed 原子地将 refresh token 标记为已使用，返回 false 表示此前已被使用过
+	MarkRefreshTokenUsed(ctx context.Context, tokenHash string) (bool, error)
+	// RevokeFamily 吊销整个 refresh token 家族，ttl 为家族内 token 的最长剩余有效期
+	RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error
+	// IsFamilyRevoked 判断 refresh token 家族是否已被吊销
+	IsFamilyRevoked(ctx context.Context, familyID string) (bool, error)
+	// RevokeAccessToken 将 access token 的 jti 加入吊销列表直至其过期
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+	// IsAccessTokenRevoked 判断 access token 是否已被吊销
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	// ConsumeActionToken 原子地将一次性操作 token 标记为已使用，返回 false 表示此前已被使用过
+	ConsumeActionToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error)
+	// PruneExpired 删除 before 之前过期的 refresh token、吊销记录与已使用的操作 token，返回删除的记录数
+	PruneExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// NewStoreFromFactory 创建 token 存储：工厂中注册了 Redis 连接器时使用 Redis，否则使用 MySQL；
+// 第二个返回值为所用存储的名称，便于记录日志
+func NewStoreFromFactory(f *factory.Factory, dbConnector *mysql.DBConnector) (Store, string) {
+	if f != nil {
+		if redisConnector, ok := f.GetConnector("redis").(*cache.RedisConnector); ok {
+			return NewRedisStore(redisConnector), "redis"
+		}
+	}
+	return NewMySQLStore(dbConnector), "mysql"
+}
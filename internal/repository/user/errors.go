@@ -1,8 +1,39 @@
 package user
 
-import "errors"
+import (
+	"errors"
+	"strings"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+)
 
 // 定义错误
 var (
 	ErrDBNotConnected = errors.New("数据库未连接")
+	ErrUserNotFound   = errors.New("用户不存在")
+	ErrDuplicateUser  = errors.New("用户已存在")
+	ErrUsernameExists = errors.New("用户名已存在")
+	ErrEmailExists    = errors.New("邮箱已被使用")
+	ErrIdentityExists = errors.New("第三方身份已关联其他用户")
 )
+
+// mysqlDuplicateEntry MySQL 唯一键冲突错误码
+const mysqlDuplicateEntry = 1062
+
+// translateError 将唯一键冲突转换为对应的业务错误
+func translateError(err error) error {
+	var mysqlErr *mysqlDriver.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != mysqlDuplicateEntry {
+		return err
+	}
+
+	// 错误信息形如：Duplicate entry 'alice' for key 't_users.username'
+	switch {
+	case strings.Contains(mysqlErr.Message, "username"):
+		return ErrUsernameExists
+	case strings.Contains(mysqlErr.Message, "email"):
+		return ErrEmailExists
+	default:
+		return ErrDuplicateUser
+	}
+}
@@ -0,0 +1,96 @@
+package user
+
+import (
+	"errors"
+	"time"
+
+	"goWebExample/internal/infra/db/mysql"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentity 用户在第三方身份提供方（OIDC）的身份，(provider, subject) 唯一
+type UserIdentity struct {
+	ID          uint64     `gorm:"primaryKey;autoIncrement;comment:'主键ID'" json:"id"`
+	UserID      uint64     `gorm:"not null;index;comment:'用户ID'" json:"userId"`
+	Provider    string     `gorm:"type:varchar(64);not null;uniqueIndex:uk_user_identities_provider_subject;comment:'身份提供方'" json:"provider"`
+	Subject     string     `gorm:"type:varchar(255);not null;uniqueIndex:uk_user_identities_provider_subject;comment:'提供方用户标识（sub）'" json:"subject"`
+	Email       string     `gorm:"type:varchar(255);comment:'提供方返回的邮箱'" json:"email,omitempty"`
+	CreatedAt   time.Time  `gorm:"type:datetime;default:CURRENT_TIMESTAMP;not null;comment:'关联时间'" json:"createdAt"`
+	LastLoginAt *time.Time `gorm:"type:datetime;comment:'最后通过该身份登录时间'" json:"lastLoginAt,omitempty"`
+}
+
+// TableName 指定表名
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// RepositoryIdentity 第三方身份数据操作接口
+type RepositoryIdentity interface {
+	GetByProviderSubject(provider, subject string) (*UserIdentity, error)
+	ListByUserID(userID uint64) ([]UserIdentity, error)
+	Create(identity *UserIdentity) error
+	TouchLogin(id uint64, email string) error
+}
+
+type identityRepositoryImpl struct {
+	dbConnector *mysql.DBConnector
+}
+
+// NewIdentityRepository 创建第三方身份仓库
+func NewIdentityRepository(dbConnector *mysql.DBConnector) RepositoryIdentity {
+	return &identityRepositoryImpl{dbConnector: dbConnector}
+}
+
+func (r *identityRepositoryImpl) GetByProviderSubject(provider, subject string) (*UserIdentity, error) {
+	db := r.dbConnector.GetDB()
+	if db == nil {
+		return nil, ErrDBNotConnected
+	}
+
+	var identity UserIdentity
+	if err := db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *identityRepositoryImpl) ListByUserID(userID uint64) ([]UserIdentity, error) {
+	db := r.dbConnector.GetDB()
+	if db == nil {
+		return nil, ErrDBNotConnected
+	}
+
+	var identities []UserIdentity
+	if err := db.Where("user_id = ?", userID).Order("id").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// Create 创建身份关联，同一身份已关联时返回 ErrIdentityExists
+func (r *identityRepositoryImpl) Create(identity *UserIdentity) error {
+	db := r.dbConnector.GetDB()
+	if db == nil {
+		return ErrDBNotConnected
+	}
+
+	err := db.Create(identity).Error
+	if errors.Is(translateError(err), ErrDuplicateUser) {
+		return ErrIdentityExists
+	}
+	return err
+}
+
+// TouchLogin 记录通过该身份登录的时间，并同步提供方返回的最新邮箱
+func (r *identityRepositoryImpl) TouchLogin(id uint64, email string) error {
+	db := r.dbConnector.GetDB()
+	if db == nil {
+		return ErrDBNotConnected
+	}
+
+	return db.Model(&UserIdentity{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"email":         email,
+		"last_login_at": gorm.Expr("NOW()"),
+	}).Error
+}
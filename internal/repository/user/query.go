@@ -0,0 +1,70 @@
+package user
+
+// 软删除筛选
+const (
+	DeletedExclude = ""        // 不含已删除用户（默认）
+	DeletedInclude = "include" // 包含已删除用户
+	DeletedOnly    = "only"    // 仅已删除用户
+)
+
+// 分页默认值
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// sortableColumns 允许排序的字段与对应列名，避免将用户输入直接拼入 SQL
+var sortableColumns = map[string]string{
+	"id":        "id",
+	"username":  "username",
+	"email":     "email",
+	"createdAt": "created_at",
+	"updatedAt": "updated_at",
+	"lastLogin": "last_login",
+}
+
+// ListQuery 用户列表查询条件
+type ListQuery struct {
+	Page        int
+	PageSize    int
+	Keyword     string // 按用户名、邮箱、昵称模糊匹配
+	IsActive    *bool
+	IsSuperuser *bool
+	Deleted     string
+	SortBy      string // 见 sortableColumns，默认 id
+	SortDesc    bool
+}
+
+// Normalize 规范化分页参数
+func (q *ListQuery) Normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = DefaultPageSize
+	}
+	if q.PageSize > MaxPageSize {
+		q.PageSize = MaxPageSize
+	}
+}
+
+func (q *ListQuery) offset() int {
+	q.Normalize()
+	return (q.Page - 1) * q.PageSize
+}
+
+func (q *ListQuery) limit() int {
+	q.Normalize()
+	return q.PageSize
+}
+
+func (q *ListQuery) orderClause() string {
+	column, ok := sortableColumns[q.SortBy]
+	if !ok {
+		column = "id"
+	}
+	if q.SortDesc {
+		return column + " DESC"
+	}
+	return column + " ASC"
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"gorm.io/gorm"
+	"strconv"
 	"time"
 )
 
@@ -34,9 +35,10 @@ type Users struct {
 	SecurityStamp       *string        `gorm:"type:varchar(100);comment:'安全验证戳'" json:"securityStamp,omitempty"`
 	PasswordSalt        string         `gorm:"type:varchar(100);not null;comment:'密码盐值'" json:"-"`
 	FailedLoginAttempts *int           `gorm:"type:int;default:0;comment:'连续登录失败次数'" json:"failedLoginAttempts,omitempty"`
+	LastFailedLoginAt   *time.Time     `gorm:"type:datetime;comment:'最近一次登录失败时间'" json:"-"`
 	LockoutEnd          *time.Time     `gorm:"type:datetime;comment:'账户锁定截止时间'" json:"-"`
-	TwoFactorSecret     *string        `gorm:"type:varchar(100);comment:'双重认证秘钥'" json:"twoFactorSecret,omitempty"`
-	RecoveryCodes       *string        `gorm:"type:json;comment:'恢复代码'" json:"recoveryCodes,omitempty"`
+	TwoFactorSecret     *string        `gorm:"type:varchar(512);comment:'双重认证秘钥（加密存储）'" json:"-"`
+	RecoveryCodes       *string        `gorm:"type:json;comment:'恢复代码（哈希）'" json:"-"`
 	AddressCountry      *string        `gorm:"type:varchar(100);comment:'国家'" json:"addressCountry,omitempty"`
 	AddressState        *string        `gorm:"type:varchar(100);comment:'省/州'" json:"addressState,omitempty"`
 	AddressCity         *string        `gorm:"type:varchar(100);comment:'城市'" json:"addressCity,omitempty"`
@@ -48,3 +50,8 @@ type Users struct {
 func (u *Users) TableName() string {
 	return "t_users"
 }
+
+// TwoFactorSecretAAD 加密双重认证秘钥时的附加认证数据，使密文只能用于所属用户
+func TwoFactorSecretAAD(userID uint64) []byte {
+	return []byte("user:" + strconv.FormatUint(userID, 10) + ":totp")
+}
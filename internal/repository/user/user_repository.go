@@ -1,6 +1,8 @@
 package user
 
 import (
+	"time"
+
 	"goWebExample/internal/infra/db/mysql"
 
 	"gorm.io/gorm"
@@ -11,10 +13,21 @@ type RepositoryUser interface {
 	GetDB() *gorm.DB
 	Create(user *Users) error
 	GetByID(id uint64) (*Users, error)
+	GetByUUID(uuid string) (*Users, error)
 	GetAll() ([]Users, error)
+	List(query ListQuery) ([]Users, int64, error)
+	Update(id uint64, updates map[string]interface{}) error
 	Delete(id uint) error
+	Restore(id uint64) error
 	GetUserByUsername(username string) (*Users, error)
+	GetUserByEmail(email string) (*Users, error)
 	UpdateLoginInfo(userID uint64, ip string) error
+	UpdatePasswordHash(userID uint64, passwordHash string) error
+	UpdatePassword(userID uint64, passwordHash, securityStamp string) error
+	ReplaceRecoveryCodes(userID uint64, old, new string) (bool, error)
+	IncrementFailedLoginAttempts(userID uint64, window time.Duration) (int, error)
+	LockUntil(userID uint64, until time.Time) error
+	ResetLoginFailures(userID uint64) error
 }
 
 type userRepositoryImpl struct {
@@ -34,7 +47,7 @@ func (r *userRepositoryImpl) Create(user *Users) error {
 	if db == nil {
 		return ErrDBNotConnected
 	}
-	return db.Create(user).Error
+	return translateError(db.Create(user).Error)
 }
 
 func (r *userRepositoryImpl) GetByID(id uint64) (*Users, error) {
@@ -50,6 +63,19 @@ func (r *userRepositoryImpl) GetByID(id uint64) (*Users, error) {
 	return &user, nil
 }
 
+func (r *userRepositoryImpl) GetByUUID(uuid string) (*Users, error) {
+	db := r.GetDB()
+	if db == nil {
+		return nil, ErrDBNotConnected
+	}
+
+	var user Users
+	if err := db.Where("uuid = ?", uuid).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userRepositoryImpl) GetAll() ([]Users, error) {
 	db := r.GetDB()
 	if db == nil {
@@ -63,13 +89,105 @@ func (r *userRepositoryImpl) GetAll() ([]Users, error) {
 	return users, nil
 }
 
+// List 按条件分页查询用户，返回当前页数据与总数
+func (r *userRepositoryImpl) List(query ListQuery) ([]Users, int64, error) {
+	db := r.GetDB()
+	if db == nil {
+		return nil, 0, ErrDBNotConnected
+	}
+
+	tx := db.Model(&Users{})
+	switch query.Deleted {
+	case DeletedOnly:
+		tx = tx.Unscoped().Where("deleted_at IS NOT NULL")
+	case DeletedInclude:
+		tx = tx.Unscoped()
+	}
+
+	if query.Keyword != "" {
+		like := "%" + query.Keyword + "%"
+		tx = tx.Where("username LIKE ? OR email LIKE ? OR nickname LIKE ?", like, like, like)
+	}
+	if query.IsActive != nil {
+		tx = tx.Where("is_active = ?", *query.IsActive)
+	}
+	if query.IsSuperuser != nil {
+		tx = tx.Where("is_superuser = ?", *query.IsSuperuser)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []Users
+	err := tx.Order(query.orderClause()).
+		Offset(query.offset()).
+		Limit(query.limit()).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// Update 按字段更新用户，updates 的键为列名
+func (r *userRepositoryImpl) Update(id uint64, updates map[string]interface{}) error {
+	db := r.GetDB()
+	if db == nil {
+		return ErrDBNotConnected
+	}
+
+	tx := db.Model(&Users{}).Where("id = ?", id).Updates(updates)
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		// 内容未变化时 RowsAffected 也为 0，需要确认用户是否存在
+		var count int64
+		if err := db.Model(&Users{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrUserNotFound
+		}
+	}
+	return nil
+}
+
 func (r *userRepositoryImpl) Delete(id uint) error {
 	db := r.GetDB()
 	if db == nil {
 		return ErrDBNotConnected
 	}
 
-	return db.Delete(&Users{}, id).Error
+	tx := db.Delete(&Users{}, id)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// Restore 恢复已软删除的用户，用户名或邮箱已被占用时返回冲突错误
+func (r *userRepositoryImpl) Restore(id uint64) error {
+	db := r.GetDB()
+	if db == nil {
+		return ErrDBNotConnected
+	}
+
+	tx := db.Unscoped().Model(&Users{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
 }
 
 func (r *userRepositoryImpl) GetUserByUsername(username string) (*Users, error) {
@@ -85,6 +203,19 @@ func (r *userRepositoryImpl) GetUserByUsername(username string) (*Users, error)
 	return &user, nil
 }
 
+func (r *userRepositoryImpl) GetUserByEmail(email string) (*Users, error) {
+	db := r.GetDB()
+	if db == nil {
+		return nil, ErrDBNotConnected
+	}
+
+	var user Users
+	if err := db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userRepositoryImpl) UpdateLoginInfo(userID uint64, ip string) error {
 	db := r.GetDB()
 	if db == nil {
@@ -97,3 +228,104 @@ func (r *userRepositoryImpl) UpdateLoginInfo(userID uint64, ip string) error {
 	})
 	return tx.Error
 }
+
+// UpdatePasswordHash 更新密码哈希，盐值已编码在哈希中，因此同时清空独立的盐值字段
+func (r *userRepositoryImpl) UpdatePasswordHash(userID uint64, passwordHash string) error {
+	db := r.GetDB()
+	if db == nil {
+		return ErrDBNotConnected
+	}
+
+	tx := db.Model(&Users{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"password_hash": passwordHash,
+		"password_salt": "",
+	})
+	return tx.Error
+}
+
+// UpdatePassword 更新密码并轮换安全戳，同时清除登录失败记录
+func (r *userRepositoryImpl) UpdatePassword(userID uint64, passwordHash, securityStamp string) error {
+	db := r.GetDB()
+	if db == nil {
+		return ErrDBNotConnected
+	}
+
+	tx := db.Model(&Users{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"password_hash":         passwordHash,
+		"password_salt":         "",
+		"security_stamp":        securityStamp,
+		"failed_login_attempts": 0,
+		"lockout_end":           nil,
+	})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes 仅当恢复码仍为 old 时替换为 new，返回 false 表示已被并发修改（如同一恢复码被同时使用）
+func (r *userRepositoryImpl) ReplaceRecoveryCodes(userID uint64, old, new string) (bool, error) {
+	db := r.GetDB()
+	if db == nil {
+		return false, ErrDBNotConnected
+	}
+
+	tx := db.Model(&Users{}).
+		Where("id = ? AND recovery_codes = CAST(? AS JSON)", userID, old).
+		Update("recovery_codes", new)
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	return tx.RowsAffected == 1, nil
+}
+
+// IncrementFailedLoginAttempts 原子地增加连续登录失败次数并记录失败时间，返回增加后的次数；
+// 上一次失败早于观察窗口 window 时重新从 1 开始计数
+func (r *userRepositoryImpl) IncrementFailedLoginAttempts(userID uint64, window time.Duration) (int, error) {
+	db := r.GetDB()
+	if db == nil {
+		return 0, ErrDBNotConnected
+	}
+
+	now := time.Now()
+	var attempts int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// MySQL 按顺序赋值，gorm 按列名排序，计数时读取的是更新前的 last_failed_login_at
+		if err := tx.Model(&Users{}).Where("id = ?", userID).UpdateColumns(map[string]interface{}{
+			"failed_login_attempts": gorm.Expr("CASE WHEN last_failed_login_at IS NULL OR last_failed_login_at < ? THEN 1 ELSE COALESCE(failed_login_attempts, 0) + 1 END",
+				now.Add(-window)),
+			"last_failed_login_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Users{}).Where("id = ?", userID).
+			Select("failed_login_attempts").Scan(&attempts).Error
+	})
+	return attempts, err
+}
+
+// LockUntil 锁定账户至指定时间
+func (r *userRepositoryImpl) LockUntil(userID uint64, until time.Time) error {
+	db := r.GetDB()
+	if db == nil {
+		return ErrDBNotConnected
+	}
+
+	return db.Model(&Users{}).Where("id = ?", userID).UpdateColumn("lockout_end", until).Error
+}
+
+// ResetLoginFailures 清零连续登录失败次数并解除锁定
+func (r *userRepositoryImpl) ResetLoginFailures(userID uint64) error {
+	db := r.GetDB()
+	if db == nil {
+		return ErrDBNotConnected
+	}
+
+	return db.Model(&Users{}).Where("id = ?", userID).UpdateColumns(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"lockout_end":           nil,
+	}).Error
+}
@@ -0,0 +1,277 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"goWebExample/internal/repository/apikey"
+)
+
+// 密钥生成与轮换参数
+const (
+	keyPrefix      = "ak_"
+	keyBytes       = 16
+	secretBytes    = 32
+	maxScopeLength = 512
+	maxRouteLength = 1024
+
+	// DefaultRotationOverlap 轮换秘钥时旧秘钥默认的过渡期
+	DefaultRotationOverlap = 24 * time.Hour
+	// MaxRotationOverlap 轮换秘钥时旧秘钥最长的过渡期
+	MaxRotationOverlap = 7 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidExpiry 过期时间无效
+	ErrInvalidExpiry = errors.New("过期时间无效")
+	// ErrInvalidOverlap 轮换过渡期无效
+	ErrInvalidOverlap = errors.New("轮换过渡期超出允许范围")
+)
+
+// CreateKeyParams 创建API密钥参数
+type CreateKeyParams struct {
+	Description   string
+	Scopes        []string
+	AllowedRoutes []string // 路由规则，见 apikey.RouteRule，为空时不限制
+	Limits        apikey.UsageLimits
+	ExpiredAt     time.Time
+}
+
+// APIKeyWithSecret 包含明文秘钥的API密钥，只在创建与轮换时返回一次
+type APIKeyWithSecret struct {
+	*APIKeyDTO
+	APISecret string `json:"apiSecret"`
+}
+
+// GetByID 根据ID获取API密钥，不检查状态与有效期
+func (s *APIKeyService) GetByID(id uint64) (*APIKeyDTO, error) {
+	key, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toDTO(key), nil
+}
+
+// CreateKey 生成新的API密钥与秘钥，秘钥只在返回值中出现这一次
+func (s *APIKeyService) CreateKey(params CreateKeyParams) (*APIKeyWithSecret, error) {
+	if !params.ExpiredAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+	scopes, err := normalizeScopes(params.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := normalizeRoutes(params.AllowedRoutes)
+	if err != nil {
+		return nil, err
+	}
+	if !validLimits(params.Limits) {
+		return nil, ErrInvalidLimits
+	}
+
+	keyID, err := randomToken(keyBytes, hex.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomToken(secretBytes, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &apikey.APIKey{
+		APIKey:        keyPrefix + keyID,
+		APISecret:     secret,
+		Status:        apikey.StatusEnabled,
+		ExpiredAt:     params.ExpiredAt,
+		Description:   params.Description,
+		Scopes:        scopes,
+		AllowedRoutes: routes,
+		RateLimit:     params.Limits.RatePerMinute,
+		DailyQuota:    params.Limits.Daily,
+		MonthlyQuota:  params.Limits.Monthly,
+	}
+	if err := s.repo.Create(key); err != nil {
+		return nil, err
+	}
+	s.logger.Info("创建API密钥", zap.String("apiKey", key.APIKey), zap.String("scopes", key.Scopes))
+
+	return &APIKeyWithSecret{APIKeyDTO: toDTO(key), APISecret: secret}, nil
+}
+
+// DisableKey 禁用API密钥，已签发的客户端 token 随即失效
+func (s *APIKeyService) DisableKey(id uint64) (*APIKeyDTO, error) {
+	key, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if key.Status == apikey.StatusDisabled {
+		return toDTO(key), nil
+	}
+
+	key.Status = apikey.StatusDisabled
+	if err := s.repo.Update(key); err != nil {
+		return nil, err
+	}
+	s.logger.Info("禁用API密钥", zap.String("apiKey", key.APIKey))
+	return toDTO(key), nil
+}
+
+// UpdatePermissions 修改API密钥的授权范围与路由规则，新的路由规则对后续请求立即生效
+func (s *APIKeyService) UpdatePermissions(id uint64, scopes, allowedRoutes []string) (*APIKeyDTO, error) {
+	scopeStr, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := normalizeRoutes(allowedRoutes)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = scopeStr
+	key.AllowedRoutes = routes
+	if err := s.repo.Update(key); err != nil {
+		return nil, err
+	}
+	s.logger.Info("修改API密钥权限", zap.String("apiKey", key.APIKey),
+		zap.String("scopes", key.Scopes), zap.String("allowedRoutes", key.AllowedRoutes))
+	return toDTO(key), nil
+}
+
+// UpdateLimits 修改API密钥的限流与配额，对后续请求立即生效
+func (s *APIKeyService) UpdateLimits(id uint64, limits apikey.UsageLimits) (*APIKeyDTO, error) {
+	if !validLimits(limits) {
+		return nil, ErrInvalidLimits
+	}
+
+	key, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	key.RateLimit = limits.RatePerMinute
+	key.DailyQuota = limits.Daily
+	key.MonthlyQuota = limits.Monthly
+	if err := s.repo.Update(key); err != nil {
+		return nil, err
+	}
+	s.logger.Info("修改API密钥限流与配额", zap.String("apiKey", key.APIKey), zap.Int64("rateLimit", key.RateLimit),
+		zap.Int64("dailyQuota", key.DailyQuota), zap.Int64("monthlyQuota", key.MonthlyQuota))
+	return toDTO(key), nil
+}
+
+// ExtendKey 续期API密钥：从当前过期时间延长 extension，已过期的密钥从当前时间起计算
+func (s *APIKeyService) ExtendKey(id uint64, extension time.Duration) (*APIKeyDTO, error) {
+	if extension <= 0 {
+		return nil, ErrInvalidExpiry
+	}
+
+	key, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	base := time.Now()
+	if key.ExpiredAt.After(base) {
+		base = key.ExpiredAt
+	}
+	key.ExpiredAt = base.Add(extension)
+	if err := s.repo.Update(key); err != nil {
+		return nil, err
+	}
+	s.logger.Info("续期API密钥", zap.String("apiKey", key.APIKey), zap.Time("expiredAt", key.ExpiredAt))
+	return toDTO(key), nil
+}
+
+// RotateSecret 轮换秘钥：生成新秘钥，旧秘钥在 overlap 过渡期内仍可验证，overlap 为 0 时旧秘钥立即失效
+//
+// 过渡期内再次轮换时，上一个旧秘钥立即失效，同一时刻最多两个秘钥有效。
+func (s *APIKeyService) RotateSecret(id uint64, overlap time.Duration) (*APIKeyWithSecret, error) {
+	if overlap < 0 || overlap > MaxRotationOverlap {
+		return nil, ErrInvalidOverlap
+	}
+
+	key, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if key.Status == apikey.StatusDisabled {
+		return nil, ErrKeyDisabled
+	}
+
+	secret, err := randomToken(secretBytes, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+
+	key.PreviousSecret = ""
+	key.PreviousSecretExpiresAt = nil
+	if overlap > 0 {
+		expiresAt := time.Now().Add(overlap)
+		key.PreviousSecret = key.APISecret
+		key.PreviousSecretExpiresAt = &expiresAt
+	}
+	key.APISecret = secret
+	if err := s.repo.Update(key); err != nil {
+		return nil, err
+	}
+	s.logger.Info("轮换API密钥秘钥", zap.String("apiKey", key.APIKey), zap.Duration("overlap", overlap))
+
+	return &APIKeyWithSecret{APIKeyDTO: toDTO(key), APISecret: secret}, nil
+}
+
+// normalizeScopes 去重排序授权范围并校验长度
+func normalizeScopes(scopes []string) (string, error) {
+	var list []string
+	for _, scope := range scopes {
+		list = append(list, strings.Fields(scope)...)
+	}
+	joined := strings.Join(slices.Compact(slices.Sorted(slices.Values(list))), " ")
+	if len(joined) > maxScopeLength {
+		return "", ErrInvalidScope
+	}
+	return joined, nil
+}
+
+// normalizeRoutes 校验并格式化路由规则
+func normalizeRoutes(routes []string) (string, error) {
+	rules := make([]string, 0, len(routes))
+	for _, route := range routes {
+		rule, err := apikey.ParseRouteRule(strings.TrimSpace(route))
+		if err != nil {
+			return "", err
+		}
+		rules = append(rules, rule.String())
+	}
+	joined := strings.Join(slices.Compact(slices.Sorted(slices.Values(rules))), " ")
+	if len(joined) > maxRouteLength {
+		return "", apikey.ErrInvalidRouteRule
+	}
+	return joined, nil
+}
+
+// validLimits 检查限流与配额设置，0 表示不限制
+func validLimits(limits apikey.UsageLimits) bool {
+	return limits.RatePerMinute >= 0 && limits.Daily >= 0 && limits.Monthly >= 0
+}
+
+// randomToken 生成随机字节并编码
+func randomToken(n int, encode func([]byte) string) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	return encode(buf), nil
+}
@@ -1,11 +1,16 @@
 package apikey
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	jwtpkg "goWebExample/internal/pkg/jwt"
 	"goWebExample/internal/repository/apikey"
+	"goWebExample/internal/repository/nonce"
+	"goWebExample/internal/repository/token"
+	"goWebExample/pkg/apisign"
 	"strconv"
 	"time"
 
@@ -14,15 +19,58 @@ import (
 
 const ServiceName = "apikey"
 
+// signWindow 签名时间戳允许的偏差（秒）
+const signWindow = 300
+
+// 签名认证错误，OpenAPI 认证中间件据此返回不同的错误码
+var (
+	// ErrMissingSignParams 缺少 API Key、签名或时间戳
+	ErrMissingSignParams = errors.New("缺少签名参数")
+	// ErrInvalidTimestamp 时间戳格式错误
+	ErrInvalidTimestamp = errors.New("时间戳解析失败")
+	// ErrTimestampExpired 时间戳超出允许的偏差
+	ErrTimestampExpired = errors.New("时间戳过期")
+	// ErrInvalidSignature API密钥不存在或签名错误，两者不作区分
+	ErrInvalidSignature = errors.New("API密钥或签名无效")
+	// ErrKeyDisabled API密钥已禁用
+	ErrKeyDisabled = errors.New("API密钥已禁用")
+	// ErrKeyExpired API密钥已过期
+	ErrKeyExpired = errors.New("API密钥已过期")
+	// ErrInvalidNonce nonce格式错误
+	ErrInvalidNonce = errors.New("nonce格式错误")
+	// ErrReplayedRequest nonce 已被使用过
+	ErrReplayedRequest = errors.New("重复的请求")
+	// ErrRouteNotAllowed API密钥无权访问该路由
+	ErrRouteNotAllowed = errors.New("API密钥无权访问该接口")
+)
+
+// SignedRequest 待验证的签名请求
+type SignedRequest struct {
+	APIKey    string
+	Sign      string
+	Timestamp string
+	// Nonce 为空时按旧版签名（HMAC(apiKey + timestamp)）验证，需开启 AllowLegacySign
+	Nonce string
+	// Canonical 由 apisign.CanonicalHTTPRequest 构造的规范请求
+	Canonical string
+}
+
 // APIKeyDTO API密钥数据传输对象
 type APIKeyDTO struct {
-	ID          uint64 `json:"id,omitempty"`
-	APIKey      string `json:"apiKey,omitempty"`
-	Status      int    `json:"status,omitempty"`
-	ExpiredAt   string `json:"expiredAt,omitempty"`
-	Description string `json:"description,omitempty"`
-	CreatedAt   string `json:"createdAt"`
-	UpdatedAt   string `json:"updatedAt"`
+	ID            uint64 `json:"id,omitempty"`
+	APIKey        string `json:"apiKey,omitempty"`
+	Status        int    `json:"status,omitempty"`
+	ExpiredAt     string `json:"expiredAt,omitempty"`
+	Description   string `json:"description,omitempty"`
+	Scopes        string `json:"scopes,omitempty"`
+	AllowedRoutes string `json:"allowedRoutes,omitempty"`
+	RateLimit     int64  `json:"rateLimit"`    // 每分钟请求数上限，0 表示不限制
+	DailyQuota    int64  `json:"dailyQuota"`   // 每日配额，0 表示不限制
+	MonthlyQuota  int64  `json:"monthlyQuota"` // 每月配额，0 表示不限制
+	CreatedAt     string `json:"createdAt"`
+	UpdatedAt     string `json:"updatedAt"`
+
+	PreviousSecretExpiresAt string `json:"previousSecretExpiresAt,omitempty"` // 轮换后旧秘钥的失效时间
 }
 
 // ServiceAPIKey 定义API密钥服务接口
@@ -32,13 +80,24 @@ type ServiceAPIKey interface {
 	Update(apiKey *apikey.APIKey) error
 	Delete(id uint64) error
 	GetAll() ([]APIKeyDTO, error)
-	VerifySign(apiKey, sign, timestamp string) error
+	VerifySign(ctx context.Context, req *SignedRequest) (*apikey.APIKey, error)
+	AuthenticateAccessToken(ctx context.Context, tokenString string) (*jwtpkg.ClientClaims, *apikey.APIKey, error)
 }
 
 // APIKeyService 提供API密钥业务服务
 type APIKeyService struct {
 	repo   apikey.RepositoryAPIKey
 	logger *zap.Logger
+
+	jwtMgr   *jwtpkg.JwtManager
+	tokens   token.Store
+	tokenTTL time.Duration
+
+	nonces          nonce.Store
+	allowLegacySign bool
+
+	usage     apikey.UsageCounter
+	usageRepo apikey.RepositoryUsage
 }
 
 // NewAPIKeyService 创建 APIKeyService 实例
@@ -49,6 +108,12 @@ func NewAPIKeyService(repo apikey.RepositoryAPIKey, logger *zap.Logger) *APIKeyS
 	}
 }
 
+// SetSigning 设置 nonce 存储与是否允许旧版签名，未设置 nonce 存储时不拒绝重放请求
+func (s *APIKeyService) SetSigning(nonces nonce.Store, allowLegacySign bool) {
+	s.nonces = nonces
+	s.allowLegacySign = allowLegacySign
+}
+
 // formatTime 格式化时间
 func formatTime(t time.Time) string {
 	return t.Format("2006-01-02 15:04:05")
@@ -60,15 +125,24 @@ func toDTO(a *apikey.APIKey) *APIKeyDTO {
 		return nil
 	}
 
-	return &APIKeyDTO{
-		ID:          a.ID,
-		APIKey:      a.APIKey,
-		Status:      a.Status,
-		ExpiredAt:   formatTime(a.ExpiredAt),
-		Description: a.Description,
-		CreatedAt:   formatTime(a.CreatedAt),
-		UpdatedAt:   formatTime(a.UpdatedAt),
+	dto := &APIKeyDTO{
+		ID:            a.ID,
+		APIKey:        a.APIKey,
+		Status:        a.Status,
+		ExpiredAt:     formatTime(a.ExpiredAt),
+		Description:   a.Description,
+		Scopes:        a.Scopes,
+		AllowedRoutes: a.AllowedRoutes,
+		RateLimit:     a.RateLimit,
+		DailyQuota:    a.DailyQuota,
+		MonthlyQuota:  a.MonthlyQuota,
+		CreatedAt:     formatTime(a.CreatedAt),
+		UpdatedAt:     formatTime(a.UpdatedAt),
+	}
+	if a.PreviousSecretExpiresAt != nil && a.PreviousSecretExpiresAt.After(time.Now()) {
+		dto.PreviousSecretExpiresAt = formatTime(*a.PreviousSecretExpiresAt)
 	}
+	return dto
 }
 
 // GetByAPIKey 根据API密钥获取记录
@@ -124,50 +198,106 @@ func (s *APIKeyService) GetAll() ([]APIKeyDTO, error) {
 	return dtos, nil
 }
 
-// VerifySign 验证签名
-func (s *APIKeyService) VerifySign(apiKey, sign, timestamp string) error {
-	if apiKey == "" || sign == "" || timestamp == "" {
-
-		return errors.New("参数不能为空")
+// VerifySign 验证签名，验证通过后返回API密钥
+//
+// 先验证签名再检查密钥状态与有效期，只有持有秘钥的调用方才能得知密钥已禁用或已过期；
+// 签名与密钥均有效后才记录 nonce，避免未认证的请求占用合法调用方的 nonce。
+func (s *APIKeyService) VerifySign(ctx context.Context, req *SignedRequest) (*apikey.APIKey, error) {
+	if req.APIKey == "" || req.Sign == "" || req.Timestamp == "" {
+		return nil, ErrMissingSignParams
+	}
+	legacy := req.Nonce == ""
+	if legacy && !s.allowLegacySign {
+		return nil, ErrMissingSignParams
+	}
+	if !legacy && !apisign.ValidNonce(req.Nonce) {
+		return nil, ErrInvalidNonce
 	}
 
 	// 验证时间戳是否在有效期内（例如5分钟）
-	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	ts, err := strconv.ParseInt(req.Timestamp, 10, 64)
 	if err != nil {
-		s.logger.Error("时间戳解析失败", zap.String("timestamp", timestamp), zap.Error(err))
-		return errors.New("时间戳解析失败")
+		s.logger.Warn("时间戳解析失败", zap.String("timestamp", req.Timestamp), zap.Error(err))
+		return nil, ErrInvalidTimestamp
 	}
 
 	// 检查时间戳是否在有效期内（5分钟）
-	now := time.Now().Unix()
-	if now-ts > 300 || ts-now > 300 {
-		s.logger.Error("时间戳过期", zap.Int64("timestamp", ts), zap.Int64("now", now))
-		return errors.New("时间戳过期")
+	now := time.Now()
+	if diff := now.Unix() - ts; diff > signWindow || diff < -signWindow {
+		s.logger.Warn("时间戳过期", zap.Int64("timestamp", ts), zap.Int64("now", now.Unix()))
+		return nil, ErrTimestampExpired
 	}
-	s.logger.Info("验证签名", zap.String("apiKey", apiKey), zap.String("timestamp", timestamp))
+	s.logger.Info("验证签名", zap.String("apiKey", req.APIKey), zap.String("timestamp", req.Timestamp), zap.Bool("legacy", legacy))
 
 	// 获取API密钥信息
-	apiKeyInfo, err := s.repo.GetByAPIKey(apiKey)
+	apiKeyInfo, err := s.repo.FindByAPIKey(req.APIKey)
+	if errors.Is(err, apikey.ErrAPIKeyNotFound) {
+		s.logger.Warn("API密钥不存在", zap.String("apiKey", req.APIKey))
+		return nil, ErrInvalidSignature
+	}
 	if err != nil {
-		s.logger.Error("获取API密钥失败", zap.String("apiKey", apiKey), zap.Error(err))
-		return err
+		s.logger.Error("获取API密钥失败", zap.String("apiKey", req.APIKey), zap.Error(err))
+		return nil, err
 	}
 
-	// 生成预期的签名
-	expectedSign := generateSign(apiKey, apiKeyInfo.APISecret, timestamp)
+	if !matchSign(apiKeyInfo, req, now) {
+		s.logger.Warn("签名验证失败", zap.String("apiKey", req.APIKey))
+		return nil, ErrInvalidSignature
+	}
+	if err := checkUsable(apiKeyInfo, now); err != nil {
+		s.logger.Warn("API密钥不可用", zap.String("apiKey", req.APIKey), zap.Error(err))
+		return nil, err
+	}
 
-	// 比较签名
-	if sign != expectedSign {
-		s.logger.Error("签名验证失败",
-			zap.String("provided", sign),
-			zap.String("expected", expectedSign))
-		return errors.New("签名验证失败")
+	if !legacy && s.nonces != nil {
+		// 时间戳偏差两侧各 signWindow 秒内的请求均可能通过校验，nonce 需保留整个窗口
+		fresh, err := s.nonces.Use(ctx, req.APIKey+":"+req.Nonce, 2*signWindow*time.Second)
+		if err != nil {
+			s.logger.Error("记录nonce失败", zap.String("apiKey", req.APIKey), zap.Error(err))
+			return nil, err
+		}
+		if !fresh {
+			s.logger.Warn("拒绝重放请求", zap.String("apiKey", req.APIKey), zap.String("nonce", req.Nonce))
+			return nil, ErrReplayedRequest
+		}
 	}
+	return apiKeyInfo, nil
+}
 
+// matchSign 使用常量时间比较签名，轮换过渡期内新旧秘钥生成的签名均有效
+func matchSign(key *apikey.APIKey, req *SignedRequest, now time.Time) bool {
+	provided, err := hex.DecodeString(req.Sign)
+	if err != nil {
+		return false
+	}
+	matched := false
+	for _, secret := range key.ActiveSecrets(now) {
+		if req.Nonce != "" {
+			if apisign.Verify(key.APIKey, secret, req.Canonical, req.Sign) {
+				matched = true
+			}
+			continue
+		}
+		expected, _ := hex.DecodeString(generateSign(key.APIKey, secret, req.Timestamp))
+		if hmac.Equal(provided, expected) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// checkUsable 检查密钥状态与有效期
+func checkUsable(key *apikey.APIKey, now time.Time) error {
+	if key.Status != apikey.StatusEnabled {
+		return ErrKeyDisabled
+	}
+	if !key.ExpiredAt.After(now) {
+		return ErrKeyExpired
+	}
 	return nil
 }
 
-// generateSign 生成签名
+// generateSign 生成旧版签名，仅在允许旧版签名时使用
 // 签名算法: HMAC-SHA256(apiKey + timestamp, apiSecret)，输出为十六进制字符串
 func generateSign(apiKey, apiSecret, timestamp string) string {
 	// 组合原始字符串
@@ -1,23 +1,54 @@
 package apikey
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"goWebExample/internal/configs"
 	"goWebExample/internal/infra/di/container"
+	"goWebExample/internal/pkg/encryption"
 	"goWebExample/internal/pkg/handlers"
 	"goWebExample/internal/pkg/module"
 	"goWebExample/internal/repository/apikey"
+	"goWebExample/internal/repository/nonce"
+	"goWebExample/internal/repository/token"
+	"goWebExample/internal/service"
+	"goWebExample/internal/service/scheduler"
+	"goWebExample/pkg/envelope"
 
+	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// 调用量刷入任务
+const (
+	usageFlushTaskID  = "apikey-usage-flush"
+	usageFlushJobType = "apikey.usage_flush"
+	usageFlushTimeout = 30 * time.Second
+)
+
 func init() {
+	// 注册调用量刷入任务处理函数，调度服务重启后据此恢复持久化的任务
+	scheduler.RegisterJob(usageFlushJobType, flushUsageJob)
+
 	// 注册模块
 	module.GetRegistry().Register(module.NewBaseModule(
 		"apikey",
 		// 服务创建函数
 		func(logger *zap.Logger, container *container.ServiceContainer) (string, interface{}) {
 			if container != nil && container.DBConnector != nil {
-				apiKeyRepo := apikey.NewAPIKeyRepository(container.DBConnector)
+				secrets, err := newSecretCipher(logger, container)
+				if err != nil {
+					logger.Error("无法初始化API密钥服务", zap.Error(err))
+					return "", nil
+				}
+				apiKeyRepo := apikey.NewAPIKeyRepository(container.DBConnector, secrets)
 				apiKeySvc := NewAPIKeyService(apiKeyRepo, logger)
+				setupOAuth(logger, apiKeySvc, container)
+				setupSigning(logger, apiKeySvc, container)
+				setupUsage(logger, apiKeySvc, container)
 				return ServiceName, apiKeySvc
 			}
 			logger.Error("无法初始化API密钥服务：数据库连接器未初始化")
@@ -29,3 +60,93 @@ func init() {
 		},
 	))
 }
+
+// newSecretCipher 创建API秘钥加密器；release 模式下必须配置加密主密钥，其他模式下未配置时秘钥以明文保存
+func newSecretCipher(logger *zap.Logger, c *container.ServiceContainer) (envelope.Cipher, error) {
+	var config configs.EncryptionConfig
+	if allConfig := c.GetConfig(); allConfig != nil {
+		config = allConfig.Encryption
+	}
+	keyring, err := encryption.NewKeyring(config)
+	if err != nil {
+		return nil, fmt.Errorf("加密主密钥无效: %w", err)
+	}
+	if keyring == nil {
+		if gin.Mode() == gin.ReleaseMode {
+			return nil, errors.New("release 模式下必须配置加密主密钥（encryption.masterKeys 或 encryption.keyFile），API秘钥不能以明文保存")
+		}
+		logger.Warn("未配置加密主密钥，API秘钥将以明文保存")
+		return nil, nil
+	}
+	logger.Info("API秘钥加密存储已启用", zap.String("keyID", keyring.PrimaryKeyID()))
+	return keyring, nil
+}
+
+// setupOAuth 初始化 OAuth2 客户端凭证模式，JWT 管理器未初始化时不可用
+func setupOAuth(logger *zap.Logger, apiKeySvc *APIKeyService, c *container.ServiceContainer) {
+	jwtManager := c.GetJWTManager()
+	if jwtManager == nil {
+		logger.Warn("JWT管理器未初始化，OAuth2客户端凭证模式不可用")
+		return
+	}
+
+	var config configs.OpenAPIConfig
+	if allConfig := c.GetConfig(); allConfig != nil {
+		config = allConfig.OpenAPI
+	}
+	store, _ := token.NewStoreFromFactory(c.GetFactory(), c.DBConnector)
+	apiKeySvc.SetOAuth(jwtManager, store, config.GetTokenTTL())
+}
+
+// setupSigning 初始化签名请求的重放防护
+func setupSigning(logger *zap.Logger, apiKeySvc *APIKeyService, c *container.ServiceContainer) {
+	var config configs.OpenAPIConfig
+	if allConfig := c.GetConfig(); allConfig != nil {
+		config = allConfig.OpenAPI
+	}
+	store, name := nonce.NewStoreFromFactory(c.GetFactory())
+	if name != "redis" {
+		logger.Warn("未配置Redis，nonce保存在内存中，多实例部署时无法跨实例拒绝重放请求")
+	}
+	if config.AllowLegacySign {
+		logger.Warn("已允许旧版签名（不含nonce与请求内容），请在调用方迁移后关闭")
+	}
+	apiKeySvc.SetSigning(store, config.AllowLegacySign)
+}
+
+// setupUsage 初始化限流与配额计数，并通过调度服务定时将调用量刷入数据库
+func setupUsage(logger *zap.Logger, apiKeySvc *APIKeyService, c *container.ServiceContainer) {
+	var config configs.OpenAPIConfig
+	if allConfig := c.GetConfig(); allConfig != nil {
+		config = allConfig.OpenAPI
+	}
+	counter, name := apikey.NewUsageCounterFromFactory(c.GetFactory())
+	if name != "redis" {
+		logger.Warn("未配置Redis，API密钥调用量在内存中计数，多实例部署时限流与配额按实例分别计算")
+	}
+	apiKeySvc.SetUsage(counter, apikey.NewUsageRepository(c.DBConnector))
+
+	schedulerSvc, ok := service.GetRegistry().Get(scheduler.ServiceName).(scheduler.SchedulerService)
+	if !ok || schedulerSvc == nil {
+		logger.Warn("调度服务未初始化，API密钥调用量不会刷入数据库")
+		return
+	}
+	interval := config.GetUsageFlushInterval()
+	description := "将API密钥调用量从计数器刷入数据库"
+	// 同名任务已由调度服务从数据库加载并绑定到刷入任务处理函数时，沿用持久化的执行计划
+	_, err := schedulerSvc.AddJob(usageFlushTaskID, description, interval.String(), usageFlushJobType, nil)
+	if err != nil && !errors.Is(err, scheduler.ErrTaskAlreadyExists) {
+		logger.Error("注册API密钥调用量刷入任务失败", zap.Error(err))
+	}
+}
+
+// flushUsageJob 将API密钥调用量刷入数据库，API密钥服务未初始化时跳过
+func flushUsageJob(ctx context.Context, _ struct{}) error {
+	apiKeySvc, ok := service.GetRegistry().Get(ServiceName).(*APIKeyService)
+	if !ok || apiKeySvc == nil {
+		return errors.New("API密钥服务未初始化")
+	}
+	ctx, cancel := context.WithTimeout(ctx, usageFlushTimeout)
+	defer cancel()
+	return apiKeySvc.FlushUsage(ctx)
+}
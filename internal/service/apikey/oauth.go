@@ -0,0 +1,214 @@
+package apikey
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	jwtpkg "goWebExample/internal/pkg/jwt"
+	"goWebExample/internal/repository/apikey"
+	"goWebExample/internal/repository/token"
+)
+
+// TokenTypeBearer OAuth2 access token 类型
+const TokenTypeBearer = "Bearer"
+
+var (
+	// ErrOAuthUnavailable 未配置 JWT 管理器或 token 存储，无法签发客户端 token
+	ErrOAuthUnavailable = errors.New("OAuth2服务未初始化")
+	// ErrInvalidClient 客户端认证失败，不区分密钥不存在、已禁用、已过期或秘钥错误
+	ErrInvalidClient = errors.New("客户端认证失败")
+	// ErrInvalidScope 申请的授权范围超出客户端允许的范围
+	ErrInvalidScope = errors.New("申请的授权范围无效")
+	// ErrInvalidAccessToken access token 无效、已过期或已吊销
+	ErrInvalidAccessToken = errors.New("access token无效或已过期")
+)
+
+// ClientToken OAuth2 token 端点响应（RFC 6749 第 5.1 节）
+type ClientToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// Introspection token 内省响应（RFC 7662 第 2.2 节），token 无效时只返回 active=false
+type Introspection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Aud       string `json:"aud,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+}
+
+// SetOAuth 设置签发客户端 token 所需的 JWT 管理器、吊销列表存储与 token 有效期，未设置时不支持 OAuth2
+func (s *APIKeyService) SetOAuth(jwtMgr *jwtpkg.JwtManager, store token.Store, ttl time.Duration) {
+	s.jwtMgr = jwtMgr
+	s.tokens = store
+	s.tokenTTL = ttl
+}
+
+// AuthenticateClient 使用 client_id（APIKey）与 client_secret（APISecret）认证客户端
+func (s *APIKeyService) AuthenticateClient(clientID, clientSecret string) (*apikey.APIKey, error) {
+	if clientID == "" || clientSecret == "" {
+		return nil, ErrInvalidClient
+	}
+
+	key, err := s.repo.GetByAPIKey(clientID)
+	if err != nil {
+		if errors.Is(err, apikey.ErrAPIKeyNotFound) || errors.Is(err, apikey.ErrAPIKeyDisabled) || errors.Is(err, apikey.ErrAPIKeyExpired) {
+			s.logger.Warn("客户端认证失败", zap.String("clientId", clientID), zap.Error(err))
+			return nil, ErrInvalidClient
+		}
+		return nil, err
+	}
+	// 轮换过渡期内新旧秘钥均可认证
+	for _, secret := range key.ActiveSecrets(time.Now()) {
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(clientSecret)) == 1 {
+			return key, nil
+		}
+	}
+	s.logger.Warn("客户端认证失败：秘钥错误", zap.String("clientId", clientID))
+	return nil, ErrInvalidClient
+}
+
+// IssueClientToken 客户端凭证模式签发 access token；scope 为空时授予客户端允许的全部范围
+func (s *APIKeyService) IssueClientToken(clientID, clientSecret, scope string) (*ClientToken, error) {
+	if s.jwtMgr == nil {
+		return nil, ErrOAuthUnavailable
+	}
+
+	key, err := s.AuthenticateClient(clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := key.ScopeList()
+	granted := allowed
+	if requested := strings.Fields(scope); len(requested) > 0 {
+		for _, sc := range requested {
+			if !slices.Contains(allowed, sc) {
+				return nil, ErrInvalidScope
+			}
+		}
+		granted = slices.Compact(slices.Sorted(slices.Values(requested)))
+	}
+
+	// token 有效期不超过密钥本身的过期时间
+	ttl := s.tokenTTL
+	if remaining := time.Until(key.ExpiredAt); remaining < ttl {
+		ttl = remaining
+	}
+
+	signed, claims, err := s.jwtMgr.IssueClientToken(key.APIKey, granted, ttl)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("签发客户端token", zap.String("clientId", key.APIKey), zap.String("scope", claims.Scope))
+
+	return &ClientToken{
+		AccessToken: signed,
+		TokenType:   TokenTypeBearer,
+		ExpiresIn:   int64(ttl.Seconds()),
+		Scope:       claims.Scope,
+	}, nil
+}
+
+// ValidateAccessToken 校验客户端 access token：签名、有效期、吊销状态，以及签发后密钥是否被禁用或过期
+func (s *APIKeyService) ValidateAccessToken(ctx context.Context, tokenString string) (*jwtpkg.ClientClaims, error) {
+	claims, _, err := s.AuthenticateAccessToken(ctx, tokenString)
+	return claims, err
+}
+
+// AuthenticateAccessToken 校验客户端 access token，同时返回签发该 token 的API密钥
+func (s *APIKeyService) AuthenticateAccessToken(ctx context.Context, tokenString string) (*jwtpkg.ClientClaims, *apikey.APIKey, error) {
+	if s.jwtMgr == nil {
+		return nil, nil, ErrOAuthUnavailable
+	}
+
+	claims, err := s.jwtMgr.ParseClientToken(tokenString)
+	if err != nil {
+		return nil, nil, ErrInvalidAccessToken
+	}
+
+	if s.tokens != nil {
+		revoked, err := s.tokens.IsAccessTokenRevoked(ctx, claims.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if revoked {
+			return nil, nil, ErrInvalidAccessToken
+		}
+	}
+
+	key, err := s.repo.GetByAPIKey(claims.ClientID)
+	if err != nil {
+		if errors.Is(err, apikey.ErrAPIKeyNotFound) || errors.Is(err, apikey.ErrAPIKeyDisabled) || errors.Is(err, apikey.ErrAPIKeyExpired) {
+			return nil, nil, ErrInvalidAccessToken
+		}
+		return nil, nil, err
+	}
+	return claims, key, nil
+}
+
+// IntrospectToken token 内省，客户端只能查看签发给自己的 token，其他情况一律返回 active=false
+func (s *APIKeyService) IntrospectToken(ctx context.Context, clientID, clientSecret, tokenString string) (*Introspection, error) {
+	if _, err := s.AuthenticateClient(clientID, clientSecret); err != nil {
+		return nil, err
+	}
+
+	claims, err := s.ValidateAccessToken(ctx, tokenString)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAccessToken) {
+			return &Introspection{Active: false}, nil
+		}
+		return nil, err
+	}
+	if claims.ClientID != clientID {
+		return &Introspection{Active: false}, nil
+	}
+
+	return &Introspection{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientID:  claims.ClientID,
+		TokenType: TokenTypeBearer,
+		Exp:       claims.ExpiresAt.Unix(),
+		Iat:       claims.IssuedAt.Unix(),
+		Sub:       claims.Subject,
+		Aud:       jwtpkg.ClientAudience,
+		Iss:       claims.Issuer,
+		Jti:       claims.ID,
+	}, nil
+}
+
+// RevokeToken 吊销 access token（RFC 7009），无效或不属于该客户端的 token 同样视为成功
+func (s *APIKeyService) RevokeToken(ctx context.Context, clientID, clientSecret, tokenString string) error {
+	if _, err := s.AuthenticateClient(clientID, clientSecret); err != nil {
+		return err
+	}
+	if s.jwtMgr == nil || s.tokens == nil {
+		return ErrOAuthUnavailable
+	}
+
+	claims, err := s.jwtMgr.ParseClientToken(tokenString)
+	if err != nil || claims.ClientID != clientID {
+		return nil
+	}
+
+	if err := s.tokens.RevokeAccessToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+	s.logger.Info("吊销客户端token", zap.String("clientId", clientID), zap.String("jti", claims.ID))
+	return nil
+}
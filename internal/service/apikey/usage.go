@@ -0,0 +1,165 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"goWebExample/internal/repository/apikey"
+)
+
+// 调用量查询范围（天）
+const (
+	DefaultUsageDays = 30
+	MaxUsageDays     = 90
+)
+
+// 限流与配额错误
+var (
+	// ErrRateLimited 超出每分钟请求数上限
+	ErrRateLimited = errors.New("请求过于频繁，请稍后再试")
+	// ErrDailyQuotaExceeded 超出每日配额
+	ErrDailyQuotaExceeded = errors.New("已超出每日调用配额")
+	// ErrMonthlyQuotaExceeded 超出每月配额
+	ErrMonthlyQuotaExceeded = errors.New("已超出每月调用配额")
+	// ErrInvalidLimits 限流或配额设置无效
+	ErrInvalidLimits = errors.New("限流与配额不能为负数")
+)
+
+// QuotaStatus 计数后的限流与配额状态，用于设置响应头
+type QuotaStatus struct {
+	Limits apikey.UsageLimits
+	Counts apikey.UsageCounts
+	// RetryAfter 超出限制时距离该限制重置的时间
+	RetryAfter time.Duration
+}
+
+// UsageDTO API密钥调用量
+type UsageDTO struct {
+	APIKey       string           `json:"apiKey"`
+	RateLimit    int64            `json:"rateLimit"`    // 每分钟请求数上限，0 表示不限制
+	DailyQuota   int64            `json:"dailyQuota"`   // 每日配额，0 表示不限制
+	MonthlyQuota int64            `json:"monthlyQuota"` // 每月配额，0 表示不限制
+	Today        int64            `json:"today"`
+	ThisMonth    int64            `json:"thisMonth"`
+	Daily        []*DailyUsageDTO `json:"daily"` // 按日期升序，包含无调用的日期
+}
+
+// DailyUsageDTO 每日调用量
+type DailyUsageDTO struct {
+	Date     string `json:"date"`
+	Requests int64  `json:"requests"`
+}
+
+// SetUsage 设置调用量计数器与调用量仓库，未设置时不限流、不统计
+func (s *APIKeyService) SetUsage(counter apikey.UsageCounter, usageRepo apikey.RepositoryUsage) {
+	s.usage = counter
+	s.usageRepo = usageRepo
+}
+
+// ConsumeQuota 检查密钥的限流与配额并计数一次，超出限制时返回对应错误且不计数；未启用统计时返回 nil, nil
+func (s *APIKeyService) ConsumeQuota(ctx context.Context, key *apikey.APIKey) (*QuotaStatus, error) {
+	if s.usage == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	result, err := s.usage.Consume(ctx, key.APIKey, key.Limits(), now)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &QuotaStatus{Limits: key.Limits(), Counts: result.Counts}
+	switch result.Exceeded {
+	case apikey.LimitRate:
+		status.RetryAfter = now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+		return status, ErrRateLimited
+	case apikey.LimitDaily:
+		y, m, d := now.Date()
+		status.RetryAfter = time.Date(y, m, d+1, 0, 0, 0, 0, now.Location()).Sub(now)
+		return status, ErrDailyQuotaExceeded
+	case apikey.LimitMonthly:
+		y, m, _ := now.Date()
+		status.RetryAfter = time.Date(y, m+1, 1, 0, 0, 0, 0, now.Location()).Sub(now)
+		return status, ErrMonthlyQuotaExceeded
+	}
+	return status, nil
+}
+
+// FlushUsage 将昨日与今日的计数刷入数据库，由定时任务调用；昨日的计数在次日结束前仍会保留，保证跨天后最终值被刷入
+func (s *APIKeyService) FlushUsage(ctx context.Context) error {
+	if s.usage == nil || s.usageRepo == nil {
+		return nil
+	}
+
+	now := time.Now()
+	for _, day := range []time.Time{now.AddDate(0, 0, -1), now} {
+		counts, err := s.usage.DailyCounts(ctx, day)
+		if err != nil {
+			return err
+		}
+		if err := s.usageRepo.SaveDailyUsage(day, counts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetUsage 获取密钥最近 days 天（含今天）的调用量，今日与本月数据来自计数器
+func (s *APIKeyService) GetUsage(ctx context.Context, apiKey string, days int) (*UsageDTO, error) {
+	if days <= 0 {
+		days = DefaultUsageDays
+	}
+	if days > MaxUsageDays {
+		days = MaxUsageDays
+	}
+
+	key, err := s.repo.FindByAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var counts apikey.UsageCounts
+	if s.usage != nil {
+		if counts, err = s.usage.Current(ctx, apiKey, now); err != nil {
+			return nil, err
+		}
+	}
+
+	from := now.AddDate(0, 0, 1-days)
+	saved := make(map[string]int64)
+	if s.usageRepo != nil {
+		rows, err := s.usageRepo.ListUsage(apiKey, from, now)
+		if err != nil {
+			s.logger.Error("获取API密钥调用量失败", zap.String("apiKey", apiKey), zap.Error(err))
+			return nil, err
+		}
+		for _, row := range rows {
+			saved[row.UsageDate.Format(time.DateOnly)] = row.RequestCount
+		}
+	}
+
+	// 计数器丢失后重新计数时，今日数据以较大者为准
+	today := now.Format(time.DateOnly)
+	counts.Daily = max(counts.Daily, saved[today])
+	saved[today] = counts.Daily
+
+	daily := make([]*DailyUsageDTO, 0, days)
+	for day := from; !day.After(now); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		daily = append(daily, &DailyUsageDTO{Date: date, Requests: saved[date]})
+	}
+
+	return &UsageDTO{
+		APIKey:       key.APIKey,
+		RateLimit:    key.RateLimit,
+		DailyQuota:   key.DailyQuota,
+		MonthlyQuota: key.MonthlyQuota,
+		Today:        counts.Daily,
+		ThisMonth:    counts.Monthly,
+		Daily:        daily,
+	}, nil
+}
@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"goWebExample/internal/configs"
+	"goWebExample/internal/infra/di/container"
+	jwtpkg "goWebExample/internal/pkg/jwt"
+	"goWebExample/internal/pkg/oidc"
+	"goWebExample/internal/service/user"
+)
+
+const ServiceName = "auth"
+
+// state token 附加数据的键
+const (
+	dataNonce    = "nonce"
+	dataVerifier = "verifier"
+)
+
+// 定义错误
+var (
+	ErrProviderNotFound    = errors.New("身份提供方不存在")
+	ErrInvalidState        = errors.New("登录请求已失效，请重新登录")
+	ErrProviderUnavailable = errors.New("身份提供方暂时不可用")
+	ErrInvalidIdentity     = errors.New("第三方身份校验失败")
+)
+
+// ProviderInfo 对外展示的身份提供方信息
+type ProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// LoginStart 发起第三方登录的结果
+type LoginStart struct {
+	AuthURL    string        // 跳转到身份提供方的授权地址
+	StateToken string        // 保存在浏览器 Cookie 中的签名 state，回调时校验
+	TTL        time.Duration // 授权流程有效期
+}
+
+// LoginResult 第三方登录回调校验通过后的身份信息
+type LoginResult struct {
+	Identity user.ExternalIdentity
+	Policy   user.IdentityPolicy
+}
+
+type provider struct {
+	config configs.OIDCProvider
+	client *oidc.Provider
+}
+
+// OIDCService 提供 OpenID Connect 授权码（PKCE）登录
+type OIDCService struct {
+	logger    *zap.Logger
+	jwtMgr    *jwtpkg.JwtManager
+	stateTTL  time.Duration
+	providers map[string]*provider
+	order     []string
+}
+
+// NewOIDCService 创建 OIDCService 实例
+func NewOIDCService(logger *zap.Logger, jwtMgr *jwtpkg.JwtManager, config configs.OIDCConfig) *OIDCService {
+	s := &OIDCService{
+		logger:    logger,
+		jwtMgr:    jwtMgr,
+		stateTTL:  config.GetStateTTL(),
+		providers: make(map[string]*provider),
+	}
+
+	for _, p := range config.Providers {
+		if p.Name == "" || p.Issuer == "" || p.ClientID == "" {
+			logger.Warn("身份提供方配置不完整，已忽略", zap.String("provider", p.Name))
+			continue
+		}
+		if _, exists := s.providers[p.Name]; exists {
+			logger.Warn("身份提供方重复配置，已忽略", zap.String("provider", p.Name))
+			continue
+		}
+
+		s.providers[p.Name] = &provider{
+			config: p,
+			client: oidc.NewProvider(oidc.Config{
+				Issuer:       p.Issuer,
+				ClientID:     p.ClientID,
+				ClientSecret: p.ClientSecret,
+				RedirectURL:  p.RedirectURL,
+				Scopes:       p.Scopes,
+			}),
+		}
+		s.order = append(s.order, p.Name)
+	}
+	return s
+}
+
+// NewOIDCServiceFromContainer 使用服务容器中的依赖创建 OIDC 服务，依赖缺失时返回 nil
+func NewOIDCServiceFromContainer(logger *zap.Logger, c *container.ServiceContainer) *OIDCService {
+	if c == nil {
+		return nil
+	}
+
+	jwtManager := c.GetJWTManager()
+	if jwtManager == nil {
+		logger.Error("无法初始化OIDC服务：JWT管理器未初始化")
+		return nil
+	}
+
+	var config configs.OIDCConfig
+	if allConfig := c.GetConfig(); allConfig != nil {
+		config = allConfig.OIDC
+	}
+	return NewOIDCService(logger, jwtManager, config)
+}
+
+// Providers 获取已配置的身份提供方
+func (s *OIDCService) Providers() []ProviderInfo {
+	infos := make([]ProviderInfo, 0, len(s.order))
+	for _, name := range s.order {
+		p := s.providers[name]
+		displayName := p.config.DisplayName
+		if displayName == "" {
+			displayName = name
+		}
+		infos = append(infos, ProviderInfo{Name: name, DisplayName: displayName})
+	}
+	return infos
+}
+
+// BeginLogin 生成 state、nonce 与 PKCE 参数并返回授权地址；
+// 三者签名后存放在 StateToken 中，由调用方写入 Cookie，服务端无需保存
+func (s *OIDCService) BeginLogin(ctx context.Context, providerName string) (*LoginStart, error) {
+	p, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrProviderNotFound
+	}
+
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := p.client.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		s.logger.Error("获取身份提供方授权地址失败", zap.String("provider", providerName), zap.Error(err))
+		return nil, ErrProviderUnavailable
+	}
+
+	stateToken, _, err := s.jwtMgr.IssueActionTokenWithData(jwtpkg.PurposeOIDCState, state, providerName, map[string]string{
+		dataNonce:    nonce,
+		dataVerifier: verifier,
+	}, s.stateTTL)
+	if err != nil {
+		return nil, fmt.Errorf("生成state token失败: %w", err)
+	}
+
+	return &LoginStart{AuthURL: authURL, StateToken: stateToken, TTL: s.stateTTL}, nil
+}
+
+// CompleteLogin 校验回调的 state，使用授权码换取并校验 ID Token，返回第三方身份
+func (s *OIDCService) CompleteLogin(ctx context.Context, providerName, code, state, stateToken string) (*LoginResult, error) {
+	p, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrProviderNotFound
+	}
+
+	claims, err := s.jwtMgr.ParseActionToken(stateToken, jwtpkg.PurposeOIDCState)
+	if err != nil ||
+		claims.Binding != providerName ||
+		subtle.ConstantTimeCompare([]byte(claims.Subject), []byte(state)) != 1 ||
+		claims.Data[dataNonce] == "" || claims.Data[dataVerifier] == "" {
+		return nil, ErrInvalidState
+	}
+	if code == "" {
+		return nil, ErrInvalidState
+	}
+
+	token, err := p.client.Exchange(ctx, code, claims.Data[dataVerifier])
+	if err != nil {
+		s.logger.Error("授权码换取token失败", zap.String("provider", providerName), zap.Error(err))
+		if errors.Is(err, oidc.ErrExchangeFailed) {
+			return nil, ErrInvalidState
+		}
+		return nil, ErrProviderUnavailable
+	}
+
+	idToken, err := p.client.VerifyIDToken(ctx, token.IDToken, claims.Data[dataNonce])
+	if err != nil {
+		s.logger.Warn("ID Token校验失败", zap.String("provider", providerName), zap.Error(err))
+		if errors.Is(err, oidc.ErrInvalidIDToken) || errors.Is(err, oidc.ErrUnknownKey) {
+			return nil, ErrInvalidIdentity
+		}
+		return nil, ErrProviderUnavailable
+	}
+
+	return &LoginResult{
+		Identity: user.ExternalIdentity{
+			Provider:          providerName,
+			Subject:           idToken.Subject,
+			Email:             idToken.Email,
+			EmailVerified:     idToken.EmailVerified,
+			Name:              idToken.Name,
+			PreferredUsername: idToken.PreferredUsername,
+		},
+		Policy: user.IdentityPolicy{
+			LinkByEmail: p.config.LinkByEmail,
+			AutoCreate:  p.config.AutoCreate,
+		},
+	}, nil
+}
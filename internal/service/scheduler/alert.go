@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// alertTimeout bounds how long an alert hook may take
+const alertTimeout = 30 * time.Second
+
+// TaskAlert describes a run of a task that failed after all of its attempts
+type TaskAlert struct {
+	TaskID      string    `json:"taskId"`
+	Description string    `json:"description"`
+	Attempts    int       `json:"attempts"`
+	Error       string    `json:"error"`
+	FailedAt    time.Time `json:"failedAt"`
+	Node        string    `json:"node"`
+	Trigger     string    `json:"trigger"`
+}
+
+// AlertHook is called when a run of a task fails for good. Hooks run in the background and
+// receive a context that is cancelled after a timeout.
+type AlertHook func(ctx context.Context, alert TaskAlert)
+
+// AddAlertHook adds a hook that is called when a run of a task fails after all of its attempts.
+// Runs cancelled because the scheduler stops do not raise alerts.
+func (s *schedulerService) AddAlertHook(hook AlertHook) {
+	if hook == nil {
+		return
+	}
+
+	s.taskMutex.Lock()
+	defer s.taskMutex.Unlock()
+
+	s.alertHooks = append(s.alertHooks, hook)
+}
+
+// raiseAlert calls the alert hooks for a failed run, a panicking hook does not affect the others
+func (s *schedulerService) raiseAlert(alert TaskAlert) {
+	s.taskMutex.RLock()
+	hooks := append([]AlertHook(nil), s.alertHooks...)
+	s.taskMutex.RUnlock()
+
+	for _, hook := range hooks {
+		go func(hook AlertHook) {
+			defer func() {
+				if r := recover(); r != nil {
+					s.logger.Error("Task alert hook panicked",
+						zap.String("id", alert.TaskID),
+						zap.Any("panic", r))
+				}
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+			defer cancel()
+			hook(ctx, alert)
+		}(hook)
+	}
+}
+
+// String formats the alert for logs and notifications
+func (a TaskAlert) String() string {
+	return fmt.Sprintf("task %s (%s) failed on %s after %d attempt(s) at %s: %s",
+		a.TaskID, a.Description, a.Node, a.Attempts, a.FailedAt.Format(time.RFC3339), a.Error)
+}
@@ -0,0 +1,212 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	repo "goWebExample/internal/repository/scheduler"
+)
+
+// ClusterMode selects how replicas sharing the same tasks coordinate their runs
+type ClusterMode string
+
+const (
+	// ClusterModeNone runs every task on every replica, for single instance deployments
+	ClusterModeNone ClusterMode = "none"
+	// ClusterModeLock takes a lock per task and scheduled time, the first replica to get it runs the task
+	ClusterModeLock ClusterMode = "lock"
+	// ClusterModeLeader elects a leader among the replicas, only the leader runs scheduled tasks
+	ClusterModeLeader ClusterMode = "leader"
+)
+
+// leaderLockKey is the lock held by the leader replica
+const leaderLockKey = "leader"
+
+// fencingTokenKey is the context key of the fencing token of a run
+type fencingTokenKey struct{}
+
+// FencingToken returns the fencing token of the lock a task run holds in cluster mode. Job handlers
+// writing to external systems can pass it along so that writes from a stale run are rejected.
+func FencingToken(ctx context.Context) (int64, bool) {
+	token, ok := ctx.Value(fencingTokenKey{}).(int64)
+	return token, ok
+}
+
+// cluster coordinates scheduled runs between replicas, a nil cluster runs every task locally
+type cluster struct {
+	mode   ClusterMode
+	locker repo.Locker
+	ttl    time.Duration
+	nodeID string
+	logger *zap.Logger
+
+	mu     sync.RWMutex
+	leader repo.Lock
+}
+
+// newCluster validates the cluster mode and creates the coordinator for it
+func newCluster(mode ClusterMode, locker repo.Locker, ttl time.Duration, nodeID string, logger *zap.Logger) (*cluster, error) {
+	switch mode {
+	case ClusterModeNone, "":
+		return nil, nil
+	case ClusterModeLock, ClusterModeLeader:
+	default:
+		return nil, fmt.Errorf("unknown cluster mode %q", mode)
+	}
+	if locker == nil {
+		return nil, fmt.Errorf("cluster mode %q requires a locker", mode)
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("cluster mode %q requires a positive lock ttl", mode)
+	}
+
+	return &cluster{
+		mode:   mode,
+		locker: locker,
+		ttl:    ttl,
+		nodeID: nodeID,
+		logger: logger,
+	}, nil
+}
+
+// claimRun decides whether this replica runs a scheduled task. slot identifies the scheduled run
+// and is the same on every replica, window is how long other replicas may still fire for it.
+// The returned context carries the fencing token of the claim.
+func (c *cluster) claimRun(ctx context.Context, taskID string, slot time.Time, window time.Duration) (context.Context, bool) {
+	if c == nil {
+		return ctx, true
+	}
+
+	if c.mode == ClusterModeLeader {
+		token, ok := c.leaderToken()
+		if !ok {
+			c.logger.Debug("Skipping task run, this node is not the scheduler leader",
+				zap.String("id", taskID),
+				zap.String("node", c.nodeID))
+			return nil, false
+		}
+		return context.WithValue(ctx, fencingTokenKey{}, token), true
+	}
+
+	// The lock is not released after the run but expires, so that replicas firing later for the
+	// same slot still find it taken
+	ttl := c.ttl
+	if window > ttl {
+		ttl = window
+	}
+	key := fmt.Sprintf("task:%s:%d", taskID, slot.Unix())
+	lock, err := c.locker.Acquire(ctx, key, ttl)
+	if err != nil {
+		if errors.Is(err, repo.ErrLockHeld) {
+			c.logger.Debug("Skipping task run, it is claimed by another node",
+				zap.String("id", taskID),
+				zap.Time("slot", slot))
+		} else {
+			c.logger.Error("Failed to acquire task lock, skipping run",
+				zap.String("id", taskID),
+				zap.String("node", c.nodeID),
+				zap.Error(err))
+		}
+		return nil, false
+	}
+
+	return context.WithValue(ctx, fencingTokenKey{}, lock.Token()), true
+}
+
+// leaderToken returns the fencing token of the leadership if this replica is the leader
+func (c *cluster) leaderToken() (int64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.leader == nil {
+		return 0, false
+	}
+	return c.leader.Token(), true
+}
+
+// campaign keeps trying to become the leader and renews the leadership until ctx is cancelled
+func (c *cluster) campaign(ctx context.Context) {
+	if c == nil || c.mode != ClusterModeLeader {
+		return
+	}
+
+	ticker := time.NewTicker(c.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		c.renewLeadership(ctx)
+
+		select {
+		case <-ctx.Done():
+			c.resign()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// renewLeadership refreshes the leadership lock, or tries to take it when this replica is not the leader
+func (c *cluster) renewLeadership(ctx context.Context) {
+	c.mu.RLock()
+	lock := c.leader
+	c.mu.RUnlock()
+
+	if lock != nil {
+		err := lock.Refresh(ctx, c.ttl)
+		if err == nil {
+			return
+		}
+		c.logger.Warn("Lost scheduler leadership",
+			zap.String("node", c.nodeID),
+			zap.Error(err))
+		c.setLeader(nil)
+	}
+
+	lock, err := c.locker.Acquire(ctx, leaderLockKey, c.ttl)
+	if err != nil {
+		if !errors.Is(err, repo.ErrLockHeld) && ctx.Err() == nil {
+			c.logger.Warn("Failed to campaign for scheduler leadership",
+				zap.String("node", c.nodeID),
+				zap.Error(err))
+		}
+		return
+	}
+
+	c.setLeader(lock)
+	c.logger.Info("Became scheduler leader",
+		zap.String("node", c.nodeID),
+		zap.Int64("token", lock.Token()))
+}
+
+// resign releases the leadership so that another replica can take over without waiting for it to expire
+func (c *cluster) resign() {
+	c.mu.Lock()
+	lock := c.leader
+	c.leader = nil
+	c.mu.Unlock()
+
+	if lock == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := lock.Release(ctx); err != nil {
+		c.logger.Warn("Failed to release scheduler leadership",
+			zap.String("node", c.nodeID),
+			zap.Error(err))
+		return
+	}
+	c.logger.Info("Resigned scheduler leadership", zap.String("node", c.nodeID))
+}
+
+// setLeader stores the leadership lock, nil when this replica is not the leader
+func (c *cluster) setLeader(lock repo.Lock) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.leader = lock
+}
@@ -0,0 +1,136 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	repo "goWebExample/internal/repository/scheduler"
+)
+
+// memoryLocker is a Locker shared by the replicas of a test
+type memoryLocker struct {
+	mu    sync.Mutex
+	held  map[string]time.Time
+	fence int64
+}
+
+func newMemoryLocker() *memoryLocker {
+	return &memoryLocker{held: make(map[string]time.Time)}
+}
+
+func (l *memoryLocker) Acquire(_ context.Context, key string, ttl time.Duration) (repo.Lock, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if expiresAt, ok := l.held[key]; ok && time.Now().Before(expiresAt) {
+		return nil, repo.ErrLockHeld
+	}
+	l.held[key] = time.Now().Add(ttl)
+	l.fence++
+	return &memoryLock{locker: l, key: key, token: l.fence}, nil
+}
+
+type memoryLock struct {
+	locker *memoryLocker
+	key    string
+	token  int64
+}
+
+func (l *memoryLock) Token() int64 { return l.token }
+
+func (l *memoryLock) Refresh(_ context.Context, ttl time.Duration) error {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+	l.locker.held[l.key] = time.Now().Add(ttl)
+	return nil
+}
+
+func (l *memoryLock) Release(_ context.Context) error {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+	delete(l.locker.held, l.key)
+	return nil
+}
+
+func TestNewClusterModes(t *testing.T) {
+	locker := newMemoryLocker()
+
+	if c, err := newCluster(ClusterModeNone, nil, 0, "node", zap.NewNop()); c != nil || err != nil {
+		t.Errorf("none mode = %v, %v, want nil cluster", c, err)
+	}
+	if _, err := newCluster("sharded", locker, time.Second, "node", zap.NewNop()); err == nil {
+		t.Error("unknown mode should be rejected")
+	}
+	if _, err := newCluster(ClusterModeLock, nil, time.Second, "node", zap.NewNop()); err == nil {
+		t.Error("lock mode without locker should be rejected")
+	}
+}
+
+func TestClaimRunLockMode(t *testing.T) {
+	locker := newMemoryLocker()
+	replicas := make([]*cluster, 3)
+	for i := range replicas {
+		c, err := newCluster(ClusterModeLock, locker, time.Minute, "node", zap.NewNop())
+		if err != nil {
+			t.Fatalf("newCluster() error = %v", err)
+		}
+		replicas[i] = c
+	}
+
+	slot := time.Unix(1700000000, 0)
+	claimed := 0
+	for _, c := range replicas {
+		ctx, ok := c.claimRun(context.Background(), "report", slot, 0)
+		if !ok {
+			continue
+		}
+		claimed++
+		if token, ok := FencingToken(ctx); !ok || token <= 0 {
+			t.Errorf("claimed run has fencing token %d, %v", token, ok)
+		}
+	}
+	if claimed != 1 {
+		t.Errorf("slot claimed by %d replicas, want 1", claimed)
+	}
+
+	if _, ok := replicas[1].claimRun(context.Background(), "report", slot.Add(time.Minute), 0); !ok {
+		t.Error("next slot should be claimable")
+	}
+	if _, ok := replicas[2].claimRun(context.Background(), "cleanup", slot, 0); !ok {
+		t.Error("other task in the same slot should be claimable")
+	}
+}
+
+func TestClaimRunLeaderMode(t *testing.T) {
+	locker := newMemoryLocker()
+	leader, _ := newCluster(ClusterModeLeader, locker, time.Minute, "a", zap.NewNop())
+	follower, _ := newCluster(ClusterModeLeader, locker, time.Minute, "b", zap.NewNop())
+
+	leader.renewLeadership(context.Background())
+	follower.renewLeadership(context.Background())
+
+	slot := time.Unix(1700000000, 0)
+	if _, ok := leader.claimRun(context.Background(), "report", slot, 0); !ok {
+		t.Error("leader should run scheduled tasks")
+	}
+	if _, ok := follower.claimRun(context.Background(), "report", slot, 0); ok {
+		t.Error("follower should skip scheduled tasks")
+	}
+
+	// The follower takes over once the leader resigns
+	leader.resign()
+	follower.renewLeadership(context.Background())
+	if _, ok := follower.claimRun(context.Background(), "report", slot, 0); !ok {
+		t.Error("follower should run scheduled tasks after taking over leadership")
+	}
+}
+
+func TestClaimRunStandalone(t *testing.T) {
+	var c *cluster
+	if _, ok := c.claimRun(context.Background(), "report", time.Now(), 0); !ok {
+		t.Error("standalone scheduler should run every task")
+	}
+}
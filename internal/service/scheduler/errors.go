@@ -6,7 +6,15 @@ import (
 
 // Error constants for the scheduler service
 var (
-	ErrTaskAlreadyExists = errors.New("task with this ID already exists")
-	ErrTaskNotFound      = errors.New("task not found")
-	ErrInvalidSchedule   = errors.New("invalid schedule format")
+	ErrTaskAlreadyExists  = errors.New("task with this ID already exists")
+	ErrTaskNotFound       = errors.New("task not found")
+	ErrInvalidSchedule    = errors.New("invalid schedule format")
+	ErrTaskDisabled       = errors.New("task is disabled")
+	ErrTaskRunning        = errors.New("task is already running")
+	ErrUnknownJobType     = errors.New("unknown job type")
+	ErrInvalidJobParams   = errors.New("invalid job params")
+	ErrTaskNotBound       = errors.New("task has no job type and no function registered in code")
+	ErrHistoryUnavailable = errors.New("task run history is not available")
+	ErrInvalidPolicy      = errors.New("invalid task policy")
+	ErrTaskAbandoned      = errors.New("task did not return after its context was done")
 )
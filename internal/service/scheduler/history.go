@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	repo "goWebExample/internal/repository/scheduler"
+)
+
+// TaskRun is a single execution of a task
+type TaskRun struct {
+	ID         string          `json:"id"`
+	TaskID     string          `json:"taskId"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+	DurationMs int64           `json:"durationMs"`
+	Status     repo.TaskStatus `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	Output     string          `json:"output,omitempty"`
+	Node       string          `json:"node"`
+	Trigger    string          `json:"trigger"`
+	Attempt    int             `json:"attempt"`
+}
+
+// toTaskRun converts a persisted run to a TaskRun
+func toTaskRun(m *repo.TaskRunModel) TaskRun {
+	return TaskRun{
+		ID:         m.ID,
+		TaskID:     m.TaskID,
+		StartedAt:  m.StartedAt,
+		FinishedAt: m.FinishedAt,
+		DurationMs: m.DurationMs,
+		Status:     m.Status,
+		Error:      m.Error,
+		Output:     m.Output,
+		Node:       m.Node,
+		Trigger:    m.Trigger,
+		Attempt:    m.Attempt,
+	}
+}
+
+// runOutputKey is the context key of the output of a run
+type runOutputKey struct{}
+
+// runOutput collects the output a job handler records for its run
+type runOutput struct {
+	mu      sync.Mutex
+	builder strings.Builder
+}
+
+// String returns the recorded output
+func (o *runOutput) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.builder.String()
+}
+
+// RecordOutput appends output to the history of the current run, for example a summary of what
+// the job did. It does nothing when run history is not available.
+func RecordOutput(ctx context.Context, output string) {
+	o, ok := ctx.Value(runOutputKey{}).(*runOutput)
+	if !ok {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.builder.Len() >= repo.MaxRunOutputLength {
+		return
+	}
+	if o.builder.Len() > 0 {
+		o.builder.WriteByte('\n')
+	}
+	o.builder.WriteString(output)
+}
+
+// SetHistory enables recording every run of a task
+func (s *schedulerService) SetHistory(runs repo.TaskRunRepository, nodeID string) {
+	s.taskMutex.Lock()
+	defer s.taskMutex.Unlock()
+
+	s.runs = runs
+	s.nodeID = nodeID
+}
+
+// ListTaskRuns returns a page of runs of a task, most recent first, and the total number of runs.
+// Runs are kept after the task is removed, until they are pruned.
+func (s *schedulerService) ListTaskRuns(ctx context.Context, id string, page, pageSize int) ([]TaskRun, int64, error) {
+	if s.runs == nil {
+		return nil, 0, ErrHistoryUnavailable
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	models, total, err := s.runs.FindByTask(ctx, id, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	runs := make([]TaskRun, 0, len(models))
+	for _, m := range models {
+		runs = append(runs, toTaskRun(m))
+	}
+	return runs, total, nil
+}
+
+// startRun records the start of an attempt of a run and returns its ID and the context collecting its
+// output. The ID is empty when run history is not available or the run could not be recorded.
+func (s *schedulerService) startRun(runCtx context.Context, taskID, trigger string, attempt int, startedAt time.Time) (string, *runOutput, context.Context) {
+	if s.runs == nil {
+		return "", nil, runCtx
+	}
+
+	run := &repo.TaskRunModel{
+		ID:        uuid.NewString(),
+		TaskID:    taskID,
+		StartedAt: startedAt,
+		Status:    repo.TaskStatusRunning,
+		Node:      s.nodeID,
+		Trigger:   trigger,
+		Attempt:   attempt,
+	}
+	if err := s.runs.Create(context.Background(), run); err != nil {
+		s.logger.Warn("Failed to record task run",
+			zap.String("id", taskID),
+			zap.Error(err))
+		return "", nil, runCtx
+	}
+
+	output := &runOutput{}
+	return run.ID, output, context.WithValue(runCtx, runOutputKey{}, output)
+}
+
+// finishRun records the end of a run started with startRun
+func (s *schedulerService) finishRun(runID, taskID string, startedAt time.Time, status repo.TaskStatus, runErr error, output *runOutput) {
+	if runID == "" {
+		return
+	}
+
+	finishedAt := time.Now()
+	var errMessage string
+	if runErr != nil {
+		errMessage = runErr.Error()
+	}
+
+	if err := s.runs.Finish(context.Background(), runID, finishedAt, finishedAt.Sub(startedAt), status, errMessage, output.String()); err != nil {
+		s.logger.Warn("Failed to record task run result",
+			zap.String("id", taskID),
+			zap.String("run", runID),
+			zap.Error(err))
+	}
+}
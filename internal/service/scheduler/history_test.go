@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	repo "goWebExample/internal/repository/scheduler"
+)
+
+func TestRecordOutput(t *testing.T) {
+	// Without run history the output is dropped
+	RecordOutput(context.Background(), "ignored")
+
+	output := &runOutput{}
+	ctx := context.WithValue(context.Background(), runOutputKey{}, output)
+	RecordOutput(ctx, "deleted 3 rows")
+	RecordOutput(ctx, "done")
+	if got := output.String(); got != "deleted 3 rows\ndone" {
+		t.Errorf("output = %q, want lines joined by newline", got)
+	}
+
+	RecordOutput(ctx, strings.Repeat("x", repo.MaxRunOutputLength))
+	length := len(output.String())
+	RecordOutput(ctx, "more")
+	if len(output.String()) != length {
+		t.Error("output beyond the maximum length should be dropped")
+	}
+}
@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// jobBinder decodes the persisted params of a job and returns the function that runs it
+type jobBinder func(params json.RawMessage) (func(ctx context.Context) error, error)
+
+// jobRegistry holds the job handlers registered by modules, keyed by job type
+type jobRegistry struct {
+	mu      sync.RWMutex
+	binders map[string]jobBinder
+}
+
+var jobs = &jobRegistry{
+	binders: make(map[string]jobBinder),
+}
+
+// RegisterJob registers a handler for a job type. The params persisted with a task are decoded
+// from JSON into P before the handler is called, so P should be a struct (use struct{} for jobs
+// without params). Handlers are meant to be registered from init functions, before the scheduler
+// loads persisted tasks on start; registering the same job type twice panics.
+func RegisterJob[P any](jobType string, handler func(ctx context.Context, params P) error) {
+	if jobType == "" || handler == nil {
+		panic("scheduler: job type and handler are required")
+	}
+
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+
+	if _, exists := jobs.binders[jobType]; exists {
+		panic(fmt.Sprintf("scheduler: job type %q registered twice", jobType))
+	}
+
+	jobs.binders[jobType] = func(raw json.RawMessage) (func(ctx context.Context) error, error) {
+		var params P
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidJobParams, jobType, err)
+			}
+		}
+		return func(ctx context.Context) error {
+			return handler(ctx, params)
+		}, nil
+	}
+}
+
+// bindJob resolves the handler of a job type and decodes its params
+func bindJob(jobType string, params json.RawMessage) (func(ctx context.Context) error, error) {
+	jobs.mu.RLock()
+	binder, exists := jobs.binders[jobType]
+	jobs.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownJobType, jobType)
+	}
+
+	return binder(params)
+}
+
+// encodeJobParams marshals job params to JSON, nil params are stored as an empty string
+func encodeJobParams(params interface{}) (json.RawMessage, error) {
+	if params == nil {
+		return nil, nil
+	}
+	if raw, ok := params.(json.RawMessage); ok {
+		return raw, nil
+	}
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJobParams, err)
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type testJobParams struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestBindJob(t *testing.T) {
+	var got testJobParams
+	RegisterJob("test.bind", func(ctx context.Context, params testJobParams) error {
+		got = params
+		return nil
+	})
+
+	run, err := bindJob("test.bind", json.RawMessage(`{"name":"report","count":3}`))
+	if err != nil {
+		t.Fatalf("bindJob() error = %v", err)
+	}
+	if err := run(context.Background()); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if got.Name != "report" || got.Count != 3 {
+		t.Errorf("handler got params %+v, want {report 3}", got)
+	}
+
+	run, err = bindJob("test.bind", nil)
+	if err != nil {
+		t.Fatalf("bindJob() without params error = %v", err)
+	}
+	if err := run(context.Background()); err != nil || got != (testJobParams{}) {
+		t.Errorf("handler without params got %+v, %v, want zero params", got, err)
+	}
+}
+
+func TestBindJobErrors(t *testing.T) {
+	RegisterJob("test.errors", func(ctx context.Context, params testJobParams) error {
+		return nil
+	})
+
+	if _, err := bindJob("test.missing", nil); !errors.Is(err, ErrUnknownJobType) {
+		t.Errorf("unknown job type error = %v, want ErrUnknownJobType", err)
+	}
+	if _, err := bindJob("test.errors", json.RawMessage(`{"count":"three"}`)); !errors.Is(err, ErrInvalidJobParams) {
+		t.Errorf("invalid params error = %v, want ErrInvalidJobParams", err)
+	}
+}
+
+func TestRegisterJobTwicePanics(t *testing.T) {
+	handler := func(ctx context.Context, params struct{}) error { return nil }
+	RegisterJob("test.twice", handler)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a job type twice should panic")
+		}
+	}()
+	RegisterJob("test.twice", handler)
+}
+
+func TestEncodeJobParams(t *testing.T) {
+	raw, err := encodeJobParams(nil)
+	if err != nil || raw != nil {
+		t.Errorf("encodeJobParams(nil) = %s, %v, want nil", raw, err)
+	}
+
+	raw, err = encodeJobParams(testJobParams{Name: "report"})
+	if err != nil || string(raw) != `{"name":"report","count":0}` {
+		t.Errorf("encodeJobParams(struct) = %s, %v", raw, err)
+	}
+
+	if _, err := encodeJobParams(func() {}); !errors.Is(err, ErrInvalidJobParams) {
+		t.Errorf("encodeJobParams(func) error = %v, want ErrInvalidJobParams", err)
+	}
+}
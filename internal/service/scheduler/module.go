@@ -2,61 +2,181 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
+	"goWebExample/internal/configs"
 	"goWebExample/internal/infra/cache"
 	"goWebExample/internal/infra/di/container"
 	"goWebExample/internal/pkg/handlers"
+	"goWebExample/internal/pkg/mail"
 	"goWebExample/internal/pkg/module"
 	repo "goWebExample/internal/repository/scheduler"
+	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// demoLogJobType is the job type of the demo tasks
+const demoLogJobType = "demo.log"
+
+// demoLogParams are the params of the demo log job
+type demoLogParams struct {
+	Message string `json:"message"`
+}
+
+// registerDemoJobs registers the job handlers used by the demo tasks
+func registerDemoJobs(logger *zap.Logger) {
+	RegisterJob(demoLogJobType, func(ctx context.Context, params demoLogParams) error {
+		logger.Info(params.Message)
+		return nil
+	})
+}
+
 // addDemoTasks adds some demo tasks to the scheduler
 func addDemoTasks(svc SchedulerService, logger *zap.Logger) {
 	// Add a task that runs every 30 seconds
-	_, err := svc.AddTaskWithSchedule(
+	_, err := svc.AddJob(
 		"demo-task-1",
 		"Demo task that logs a message every 30 seconds",
 		"30s",
-		func() error {
-			logger.Info("Demo task 1 executed")
-			return nil
-		},
+		demoLogJobType,
+		demoLogParams{Message: "Demo task 1 executed"},
 	)
 	if err != nil {
 		logger.Error("Failed to add demo task 1", zap.Error(err))
 	}
 
 	// Add a task that runs every minute
-	_, err = svc.AddTaskWithSchedule(
+	_, err = svc.AddJob(
 		"demo-task-2",
 		"Demo task that logs a message every minute",
 		"1m",
-		func() error {
-			logger.Info("Demo task 2 executed")
-			return nil
-		},
+		demoLogJobType,
+		demoLogParams{Message: "Demo task 2 executed"},
 	)
 	if err != nil {
 		logger.Error("Failed to add demo task 2", zap.Error(err))
 	}
 
 	// Add a task that runs every 5 minutes using a cron expression
-	_, err = svc.AddTaskWithSchedule(
+	_, err = svc.AddJob(
 		"demo-task-3",
 		"Demo task that logs a message every 5 minutes using cron expression",
 		"0 */5 * * * *", // Seconds Minutes Hours DayOfMonth Month DayOfWeek
-		func() error {
-			logger.Info("Demo task 3 executed (cron expression)")
-			return nil
-		},
+		demoLogJobType,
+		demoLogParams{Message: "Demo task 3 executed (cron expression)"},
 	)
 	if err != nil {
 		logger.Error("Failed to add demo task 3", zap.Error(err))
 	}
 }
 
+// setupCluster enables the configured cluster mode. An error is returned when the cluster mode
+// cannot be enabled, running standalone instead would run every task on every replica.
+func setupCluster(logger *zap.Logger, svc SchedulerService, container *container.ServiceContainer) error {
+	config := schedulerConfig(container)
+	mode := ClusterMode(config.GetClusterMode())
+	if mode == ClusterModeNone {
+		return nil
+	}
+
+	nodeID := config.GetNodeID()
+	locker, err := repo.NewLockerFromFactory(container.GetFactory(), config.GetLockBackend(), nodeID)
+	if err == nil {
+		err = svc.SetCluster(mode, locker, config.GetLockTTL(), nodeID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to enable cluster mode %q with lock backend %q: %w", mode, config.GetLockBackend(), err)
+	}
+
+	logger.Info("Scheduler cluster mode enabled",
+		zap.String("mode", string(mode)),
+		zap.String("backend", config.GetLockBackend()),
+		zap.String("node", nodeID))
+	return nil
+}
+
+// historyPruneTaskID is the task pruning the run history
+const (
+	historyPruneTaskID  = "scheduler-history-prune"
+	historyPruneJobType = "scheduler.history_prune"
+)
+
+// setupHistory records task runs in the database and prunes runs older than the retention period
+func setupHistory(logger *zap.Logger, svc SchedulerService, db *gorm.DB, container *container.ServiceContainer) {
+	if db == nil {
+		logger.Warn("Database not available, scheduler will run without run history")
+		return
+	}
+
+	config := schedulerConfig(container)
+	runs := repo.NewTaskRunRepository(db, logger)
+	svc.SetHistory(runs, config.GetNodeID())
+
+	retention := config.GetHistoryRetention()
+	RegisterJob(historyPruneJobType, func(ctx context.Context, _ struct{}) error {
+		deleted, err := runs.DeleteBefore(ctx, time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+		RecordOutput(ctx, fmt.Sprintf("deleted %d runs older than %s", deleted, retention))
+		return nil
+	})
+
+	_, err := svc.AddJob(historyPruneTaskID, "Prune scheduler run history", "1h", historyPruneJobType, nil)
+	if err != nil {
+		logger.Error("Failed to add scheduler history prune task", zap.Error(err))
+	}
+}
+
+// setupPolicy applies the default timeout and retry policy of tasks and sends an email to the
+// configured addresses when a run fails after all of its attempts
+func setupPolicy(logger *zap.Logger, svc SchedulerService, container *container.ServiceContainer) {
+	config := schedulerConfig(container)
+	policy := TaskPolicy{
+		Timeout:        Duration(config.GetTaskTimeout()),
+		MaxAttempts:    config.GetMaxAttempts(),
+		InitialBackoff: Duration(config.GetRetryBackoff()),
+		MaxBackoff:     Duration(config.GetMaxRetryBackoff()),
+		Multiplier:     config.GetRetryMultiplier(),
+		Jitter:         config.GetRetryJitter(),
+	}
+	if err := svc.SetDefaults(policy, config.GetShutdownTimeout()); err != nil {
+		logger.Error("Invalid scheduler task policy, using the built-in defaults", zap.Error(err))
+	}
+
+	if len(config.AlertEmails) == 0 {
+		return
+	}
+	mailer := container.GetMailer()
+	if mailer == nil {
+		logger.Warn("Mailer not available, scheduler alerts will only be logged")
+		return
+	}
+
+	recipients := config.AlertEmails
+	svc.AddAlertHook(func(ctx context.Context, alert TaskAlert) {
+		err := mailer.Send(ctx, &mail.Message{
+			To:       recipients,
+			Subject:  fmt.Sprintf("[scheduler] Task %s failed", alert.TaskID),
+			TextBody: alert.String(),
+		})
+		if err != nil {
+			logger.Error("Failed to send scheduler alert email",
+				zap.String("id", alert.TaskID),
+				zap.Error(err))
+		}
+	})
+}
+
+// schedulerConfig returns the scheduler configuration, or the defaults when no configuration is loaded
+func schedulerConfig(container *container.ServiceContainer) configs.SchedulerConfig {
+	if allConfig := container.GetConfig(); allConfig != nil {
+		return allConfig.Scheduler
+	}
+	return configs.SchedulerConfig{}
+}
+
 func init() {
 	// Register the scheduler module
 	module.GetRegistry().Register(module.NewBaseModule(
@@ -73,11 +193,11 @@ func init() {
 
 				// Check if db is not nil before auto migrating
 				if db != nil {
-					// Auto migrate the task model
-					if err := db.AutoMigrate(&repo.TaskModel{}); err != nil {
-						logger.Error("Failed to auto migrate scheduler task model", zap.Error(err))
+					// Auto migrate the task and run models
+					if err := db.AutoMigrate(&repo.TaskModel{}, &repo.TaskRunModel{}); err != nil {
+						logger.Error("Failed to auto migrate scheduler models", zap.Error(err))
 					} else {
-						logger.Info("Scheduler task model auto migrated")
+						logger.Info("Scheduler models auto migrated")
 					}
 				} else {
 					logger.Warn("Database instance is nil, skipping auto migration")
@@ -127,7 +247,17 @@ func init() {
 			schedulerSvc := NewSchedulerService(logger, taskRepo, taskCache)
 			logger.Info("Scheduler service created with persistence")
 
-			// Add demo tasks
+			// Coordinate with other replicas before any task is scheduled. The scheduler is not
+			// started when the configured cluster mode cannot be enabled.
+			if err := setupCluster(logger, schedulerSvc, container); err != nil {
+				logger.Error("Scheduler not started, tasks will not run on this replica", zap.Error(err))
+				return "", nil
+			}
+			setupHistory(logger, schedulerSvc, db, container)
+			setupPolicy(logger, schedulerSvc, container)
+
+			// Register job handlers and add demo tasks, before persisted tasks are loaded on start
+			registerDemoJobs(logger)
 			addDemoTasks(schedulerSvc, logger)
 
 			// Start the scheduler service
@@ -0,0 +1,208 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"go.uber.org/zap"
+	repo "goWebExample/internal/repository/scheduler"
+)
+
+// maxPolicyAttempts bounds the attempts of a run so that a misconfigured policy cannot retry forever
+const maxPolicyAttempts = 20
+
+// Duration is a time.Duration that is written to JSON as a duration string such as "30s"
+type Duration time.Duration
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON reads a duration string, or a number of nanoseconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*d = Duration(parsed)
+	case float64:
+		*d = Duration(time.Duration(v))
+	default:
+		return fmt.Errorf("invalid duration %s", string(data))
+	}
+	return nil
+}
+
+// TaskPolicy bounds each run of a task and controls how a failed run is retried. A run fails
+// for good, and alert hooks are called, once all of its attempts have failed.
+type TaskPolicy struct {
+	// Timeout is how long a single attempt may run before its context is cancelled, 0 means no timeout
+	Timeout Duration `json:"timeout"`
+	// MaxAttempts is the number of attempts of a run including the first one, 1 disables retries
+	MaxAttempts int `json:"maxAttempts"`
+	// InitialBackoff is the wait before the first retry
+	InitialBackoff Duration `json:"initialBackoff"`
+	// MaxBackoff caps the wait between retries
+	MaxBackoff Duration `json:"maxBackoff"`
+	// Multiplier is how much the wait grows after each retry
+	Multiplier float64 `json:"multiplier"`
+	// Jitter is the fraction, between 0 and 1, by which each wait is randomly shortened or lengthened
+	Jitter float64 `json:"jitter"`
+}
+
+// DefaultTaskPolicy returns the policy of tasks without a policy of their own when no defaults are
+// configured: attempts time out after 10 minutes and are not retried
+func DefaultTaskPolicy() TaskPolicy {
+	return TaskPolicy{
+		Timeout:        Duration(10 * time.Minute),
+		MaxAttempts:    1,
+		InitialBackoff: Duration(time.Second),
+		MaxBackoff:     Duration(time.Minute),
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// Validate checks that the policy can be applied
+func (p TaskPolicy) Validate() error {
+	switch {
+	case p.Timeout < 0:
+		return fmt.Errorf("%w: timeout must not be negative", ErrInvalidPolicy)
+	case p.MaxAttempts < 1 || p.MaxAttempts > maxPolicyAttempts:
+		return fmt.Errorf("%w: max attempts must be between 1 and %d", ErrInvalidPolicy, maxPolicyAttempts)
+	case p.InitialBackoff < 0 || p.MaxBackoff < 0:
+		return fmt.Errorf("%w: backoff must not be negative", ErrInvalidPolicy)
+	case p.MaxBackoff > 0 && p.MaxBackoff < p.InitialBackoff:
+		return fmt.Errorf("%w: max backoff must not be less than initial backoff", ErrInvalidPolicy)
+	case p.Multiplier < 1:
+		return fmt.Errorf("%w: multiplier must be at least 1", ErrInvalidPolicy)
+	case p.Jitter < 0 || p.Jitter > 1:
+		return fmt.Errorf("%w: jitter must be between 0 and 1", ErrInvalidPolicy)
+	}
+	return nil
+}
+
+// backoff returns the wait before retrying after the given failed attempt, starting at 1. The wait
+// grows exponentially from the initial backoff, is capped at the max backoff and varied by the jitter.
+func (p TaskPolicy) backoff(attempt int, random float64) time.Duration {
+	wait := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+
+	// random is in [0, 1), spread it over [-jitter, +jitter]
+	wait *= 1 + p.Jitter*(2*random-1)
+	if wait < 0 {
+		return 0
+	}
+	return time.Duration(wait)
+}
+
+// parsePolicy decodes a persisted policy, an empty string means the task uses the default policy
+func parsePolicy(raw string) (*TaskPolicy, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var policy TaskPolicy
+	if err := json.Unmarshal([]byte(raw), &policy); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// encodePolicy encodes a policy for persistence, nil is stored as an empty string
+func encodePolicy(policy *TaskPolicy) string {
+	if policy == nil {
+		return ""
+	}
+	raw, _ := json.Marshal(policy)
+	return string(raw)
+}
+
+// SetDefaults sets the policy of tasks without a policy of their own and how long Stop waits for
+// running tasks to return after cancelling them
+func (s *schedulerService) SetDefaults(policy TaskPolicy, shutdownTimeout time.Duration) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if shutdownTimeout <= 0 {
+		return errors.New("shutdown timeout must be positive")
+	}
+
+	s.taskMutex.Lock()
+	defer s.taskMutex.Unlock()
+
+	s.defaultPolicy = policy
+	s.shutdownTimeout = shutdownTimeout
+	return nil
+}
+
+// SetTaskPolicy sets the policy of a task, nil resets it to the default policy. The policy is
+// persisted and applies from the next run.
+func (s *schedulerService) SetTaskPolicy(id string, policy *TaskPolicy) error {
+	if policy != nil {
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	s.taskMutex.Lock()
+	defer s.taskMutex.Unlock()
+
+	task, exists := s.tasks[id]
+	if !exists {
+		return ErrTaskNotFound
+	}
+
+	ctx := context.Background()
+	raw := encodePolicy(policy)
+	if s.repository != nil {
+		if err := s.repository.UpdatePolicy(ctx, id, raw); err != nil && !errors.Is(err, repo.ErrTaskNotFound) {
+			return fmt.Errorf("failed to persist task policy: %w", err)
+		}
+	}
+	if s.cache != nil {
+		if err := s.cache.UpdatePolicy(ctx, id, raw); err != nil && !errors.Is(err, repo.ErrTaskNotFound) {
+			s.logger.Warn("Failed to update task policy in cache",
+				zap.String("id", id),
+				zap.Error(err))
+		}
+	}
+
+	task.Policy = policy
+
+	s.logger.Info("Task policy updated",
+		zap.String("id", id),
+		zap.Bool("default", policy == nil))
+	return nil
+}
+
+// policyFor returns the policy a task runs with, the caller must hold the task lock
+func (s *schedulerService) policyFor(task *Task) TaskPolicy {
+	if task.Policy != nil {
+		return *task.Policy
+	}
+	return s.defaultPolicy
+}
+
+// retryWait returns the wait before retrying after the given failed attempt
+func retryWait(policy TaskPolicy, attempt int) time.Duration {
+	return policy.backoff(attempt, rand.Float64())
+}
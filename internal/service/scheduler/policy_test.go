@@ -0,0 +1,238 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	repo "goWebExample/internal/repository/scheduler"
+)
+
+func TestPolicyBackoff(t *testing.T) {
+	policy := TaskPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: Duration(time.Second),
+		MaxBackoff:     Duration(5 * time.Second),
+		Multiplier:     2,
+		Jitter:         0.5,
+	}
+
+	// With random at 0.5 the jitter cancels out
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := policy.backoff(i+1, 0.5); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+
+	if got := policy.backoff(1, 0); got != 500*time.Millisecond {
+		t.Errorf("backoff with lowest jitter = %v, want 500ms", got)
+	}
+	if got := policy.backoff(1, 0.999999); got < 1499*time.Millisecond || got > 1500*time.Millisecond {
+		t.Errorf("backoff with highest jitter = %v, want about 1.5s", got)
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	if err := DefaultTaskPolicy().Validate(); err != nil {
+		t.Fatalf("default policy is invalid: %v", err)
+	}
+
+	invalid := []func(p *TaskPolicy){
+		func(p *TaskPolicy) { p.MaxAttempts = 0 },
+		func(p *TaskPolicy) { p.MaxAttempts = maxPolicyAttempts + 1 },
+		func(p *TaskPolicy) { p.Timeout = -1 },
+		func(p *TaskPolicy) { p.MaxBackoff = p.InitialBackoff - 1 },
+		func(p *TaskPolicy) { p.Multiplier = 0.5 },
+		func(p *TaskPolicy) { p.Jitter = 1.5 },
+	}
+	for i, change := range invalid {
+		policy := DefaultTaskPolicy()
+		change(&policy)
+		if err := policy.Validate(); !errors.Is(err, ErrInvalidPolicy) {
+			t.Errorf("case %d: Validate() = %v, want ErrInvalidPolicy", i, err)
+		}
+	}
+}
+
+func TestPolicyJSON(t *testing.T) {
+	policy := DefaultTaskPolicy()
+	raw := encodePolicy(&policy)
+
+	decoded, err := parsePolicy(raw)
+	if err != nil {
+		t.Fatalf("parsePolicy() error = %v", err)
+	}
+	if *decoded != policy {
+		t.Errorf("decoded policy = %+v, want %+v", *decoded, policy)
+	}
+
+	var d Duration
+	if err := json.Unmarshal([]byte(`"1m30s"`), &d); err != nil || time.Duration(d) != 90*time.Second {
+		t.Errorf("duration = %v, %v, want 1m30s", time.Duration(d), err)
+	}
+
+	if p, err := parsePolicy(""); p != nil || err != nil {
+		t.Errorf("empty policy = %v, %v, want nil", p, err)
+	}
+}
+
+func TestExecuteTaskRetries(t *testing.T) {
+	s := NewSchedulerService(zap.NewNop(), nil, nil).(*schedulerService)
+
+	var calls atomic.Int32
+	_, err := s.AddTask("flaky", "fails twice", time.Hour, func(ctx context.Context) error {
+		if calls.Add(1) < 3 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+	policy := TaskPolicy{MaxAttempts: 3, InitialBackoff: Duration(time.Millisecond), Multiplier: 1}
+	if err := s.SetTaskPolicy("flaky", &policy); err != nil {
+		t.Fatalf("SetTaskPolicy() error = %v", err)
+	}
+
+	alerts := make(chan TaskAlert, 1)
+	s.AddAlertHook(func(_ context.Context, alert TaskAlert) {
+		alerts <- alert
+	})
+
+	task, _ := s.GetTask("flaky")
+	s.executeTask(context.Background(), task, repo.RunTriggerManual)
+	if calls.Load() != 3 || task.Status != repo.TaskStatusCompleted {
+		t.Errorf("calls = %d, status = %s, want 3 attempts and completed", calls.Load(), task.Status)
+	}
+
+	// A run failing every attempt fails for good and raises an alert
+	calls.Store(-10)
+	s.executeTask(context.Background(), task, repo.RunTriggerManual)
+	if task.Status != repo.TaskStatusFailed {
+		t.Errorf("status = %s, want failed", task.Status)
+	}
+	select {
+	case alert := <-alerts:
+		if alert.TaskID != "flaky" || alert.Attempts != 3 {
+			t.Errorf("alert = %+v, want task flaky after 3 attempts", alert)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected an alert after the last attempt failed")
+	}
+}
+
+func TestExecuteTaskTimeout(t *testing.T) {
+	s := NewSchedulerService(zap.NewNop(), nil, nil).(*schedulerService)
+
+	_, err := s.AddTask("slow", "waits for its deadline", time.Hour, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+	policy := TaskPolicy{Timeout: Duration(10 * time.Millisecond), MaxAttempts: 1, Multiplier: 1}
+	if err := s.SetTaskPolicy("slow", &policy); err != nil {
+		t.Fatalf("SetTaskPolicy() error = %v", err)
+	}
+
+	task, _ := s.GetTask("slow")
+	s.executeTask(context.Background(), task, repo.RunTriggerManual)
+	if !errors.Is(task.Error, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want deadline exceeded", task.Error)
+	}
+}
+
+func TestExecuteTaskAbandonedIsNotRetried(t *testing.T) {
+	s := NewSchedulerService(zap.NewNop(), nil, nil).(*schedulerService)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	defer close(release)
+	_, err := s.AddTask("hung", "ignores its context", time.Hour, func(ctx context.Context) error {
+		calls.Add(1)
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+	policy := TaskPolicy{Timeout: Duration(10 * time.Millisecond), MaxAttempts: 3, InitialBackoff: Duration(time.Millisecond), Multiplier: 1}
+	if err := s.SetTaskPolicy("hung", &policy); err != nil {
+		t.Fatalf("SetTaskPolicy() error = %v", err)
+	}
+
+	task, _ := s.GetTask("hung")
+	s.executeTask(context.Background(), task, repo.RunTriggerManual)
+	if calls.Load() != 1 || !errors.Is(task.Error, ErrTaskAbandoned) {
+		t.Errorf("calls = %d, error = %v, want one abandoned attempt", calls.Load(), task.Error)
+	}
+}
+
+func TestRunScheduledSkipsWhileRunning(t *testing.T) {
+	s := NewSchedulerService(zap.NewNop(), nil, nil).(*schedulerService)
+
+	var calls atomic.Int32
+	_, err := s.AddTask("long", "still running", time.Hour, func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+
+	task, _ := s.GetTask("long")
+	task.IsRunning = true
+	s.runScheduled(task)
+	if calls.Load() != 0 {
+		t.Error("scheduled run should be skipped while the previous run is in progress")
+	}
+
+	task.IsRunning = false
+	s.runScheduled(task)
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want the task to run once the previous run finished", calls.Load())
+	}
+}
+
+func TestStopCancelsRunningTasks(t *testing.T) {
+	s := NewSchedulerService(zap.NewNop(), nil, nil).(*schedulerService)
+	if err := s.SetDefaults(TaskPolicy{MaxAttempts: 1, Multiplier: 1}, 5*time.Second); err != nil {
+		t.Fatalf("SetDefaults() error = %v", err)
+	}
+
+	started := make(chan struct{})
+	_, err := s.AddTask("blocking", "waits for cancellation", time.Hour, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := s.RunTask("blocking"); err != nil {
+		t.Fatalf("RunTask() error = %v", err)
+	}
+	<-started
+
+	begin := time.Now()
+	if err := s.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("Stop() took %v, want running tasks to be cancelled", elapsed)
+	}
+
+	task, _ := s.GetTask("blocking")
+	if task.IsRunning || !errors.Is(task.Error, context.Canceled) {
+		t.Errorf("running = %v, error = %v, want the run cancelled", task.IsRunning, task.Error)
+	}
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -16,36 +17,119 @@ import (
 
 const ServiceName = "scheduler"
 
+// defaultShutdownTimeout is how long Stop waits for running tasks to return after cancelling them
+const defaultShutdownTimeout = 30 * time.Second
+
+// syncInterval is how often tasks are synced with the database, so that pausing, resuming,
+// rescheduling or removing a task on another replica takes effect on this one
+const syncInterval = 30 * time.Second
+
+// abandonGrace is how long a task may take to return after its context is done before it is abandoned
+const abandonGrace = time.Second
+
 // Task represents a scheduled task
 type Task struct {
 	ID          string
 	Description string
 	Schedule    string
 	Interval    time.Duration
-	Func        func() error
+	JobType     string
+	Params      json.RawMessage
+	Policy      *TaskPolicy
+	Func        func(ctx context.Context) error
 	IsRunning   bool
 	LastRun     time.Time
 	NextRun     time.Time
 	Error       error
 	Status      repo.TaskStatus
 	entryID     cron.EntryID
+	bindErr     error
+}
+
+// TaskInfo is a point-in-time snapshot of a task, safe to read while the task is running
+type TaskInfo struct {
+	ID          string          `json:"id"`
+	Description string          `json:"description"`
+	Schedule    string          `json:"schedule"`
+	JobType     string          `json:"jobType,omitempty"`
+	Params      json.RawMessage `json:"params,omitempty"`
+	Policy      TaskPolicy      `json:"policy"`
+	PolicySet   bool            `json:"policySet"`
+	Status      repo.TaskStatus `json:"status"`
+	IsRunning   bool            `json:"isRunning"`
+	LastRun     *time.Time      `json:"lastRun,omitempty"`
+	NextRun     *time.Time      `json:"nextRun,omitempty"`
+	LastError   string          `json:"lastError,omitempty"`
+}
+
+// info returns a snapshot of the task with the policy it runs with, the caller must hold the task lock
+func (t *Task) info(policy TaskPolicy) TaskInfo {
+	info := TaskInfo{
+		ID:          t.ID,
+		Description: t.Description,
+		Schedule:    t.Schedule,
+		JobType:     t.JobType,
+		Params:      t.Params,
+		Policy:      policy,
+		PolicySet:   t.Policy != nil,
+		Status:      t.Status,
+		IsRunning:   t.IsRunning,
+	}
+	if !t.LastRun.IsZero() {
+		lastRun := t.LastRun
+		info.LastRun = &lastRun
+	}
+	if !t.NextRun.IsZero() && t.Status != repo.TaskStatusDisabled {
+		nextRun := t.NextRun
+		info.NextRun = &nextRun
+	}
+	if t.Error != nil {
+		info.LastError = t.Error.Error()
+	}
+	return info
 }
 
 // SchedulerService interface defines the methods for a scheduler service
 type SchedulerService interface {
 	// AddTask adds a new task to the scheduler with a duration interval
-	AddTask(id, description string, interval time.Duration, taskFunc func() error) (string, error)
+	AddTask(id, description string, interval time.Duration, taskFunc func(ctx context.Context) error) (string, error)
 	// AddTaskWithSchedule adds a new task with a schedule string (e.g. "5s", "1m", "2h")
-	AddTaskWithSchedule(id, description, schedule string, taskFunc func() error) (string, error)
+	AddTaskWithSchedule(id, description, schedule string, taskFunc func(ctx context.Context) error) (string, error)
+	// AddJob adds a new task that runs a registered job type with the given params
+	AddJob(id, description, schedule, jobType string, params interface{}) (string, error)
 	// RemoveTask removes a task from the scheduler
 	RemoveTask(id string) error
 	// GetTasks returns all registered tasks
 	GetTasks() []*Task
 	// GetTask returns a specific task by ID
 	GetTask(id string) (*Task, error)
+	// ListTaskInfos returns snapshots of all tasks ordered by ID
+	ListTaskInfos() []TaskInfo
+	// GetTaskInfo returns a snapshot of a specific task
+	GetTaskInfo(id string) (TaskInfo, error)
+	// RunTask triggers a task immediately, outside of its schedule
+	RunTask(id string) error
+	// PauseTask disables a task so that it is no longer scheduled
+	PauseTask(id string) error
+	// ResumeTask re-enables a disabled task
+	ResumeTask(id string) error
+	// UpdateSchedule changes the schedule of a task at runtime
+	UpdateSchedule(id, schedule string) error
+	// SetTaskPolicy sets the timeout and retry policy of a task, nil resets it to the default policy
+	SetTaskPolicy(id string, policy *TaskPolicy) error
+	// ListTaskRuns returns a page of the run history of a task, most recent first
+	ListTaskRuns(ctx context.Context, id string, page, pageSize int) ([]TaskRun, int64, error)
+	// SetHistory enables recording every run of a task, nodeID identifies this replica in the history
+	SetHistory(runs repo.TaskRunRepository, nodeID string)
+	// SetCluster enables coordination with other replicas sharing the same tasks, it must be called before Start
+	SetCluster(mode ClusterMode, locker repo.Locker, ttl time.Duration, nodeID string) error
+	// SetDefaults sets the policy of tasks without a policy of their own and how long Stop waits for running tasks
+	SetDefaults(policy TaskPolicy, shutdownTimeout time.Duration) error
+	// AddAlertHook adds a hook called when a run of a task fails after all of its attempts
+	AddAlertHook(hook AlertHook)
 	// Start starts the scheduler
 	Start() error
-	// Stop stops the scheduler
+	// Stop stops the scheduler, cancels running tasks and waits for them to return
 	Stop() error
 }
 
@@ -60,6 +144,14 @@ type schedulerService struct {
 	cron       *cron.Cron
 	repository repo.TaskRepository
 	cache      repo.TaskCache
+	cluster    *cluster
+	runs       repo.TaskRunRepository
+	nodeID     string
+
+	defaultPolicy   TaskPolicy
+	shutdownTimeout time.Duration
+	alertHooks      []AlertHook
+	active          sync.WaitGroup
 }
 
 // NewSchedulerService creates a new scheduler service
@@ -76,6 +168,9 @@ func NewSchedulerService(logger *zap.Logger, repository repo.TaskRepository, cac
 		cron:       cron.New(cron.WithSeconds()),
 		repository: repository,
 		cache:      cache,
+
+		defaultPolicy:   DefaultTaskPolicy(),
+		shutdownTimeout: defaultShutdownTimeout,
 	}
 }
 
@@ -99,12 +194,18 @@ func parseSchedule(schedule string) (string, time.Duration, error) {
 	// Try to parse as a duration directly
 	duration, err := time.ParseDuration(schedule)
 	if err == nil {
+		if duration < time.Second {
+			return "", 0, ErrInvalidSchedule
+		}
 		return durationToCronExpression(duration), duration, nil
 	}
 
 	// If it's a simple number, assume seconds
 	if _, err := strconv.Atoi(schedule); err == nil {
 		duration, _ := time.ParseDuration(schedule + "s")
+		if duration < time.Second {
+			return "", 0, ErrInvalidSchedule
+		}
 		return durationToCronExpression(duration), duration, nil
 	}
 
@@ -114,21 +215,42 @@ func parseSchedule(schedule string) (string, time.Duration, error) {
 	fields := strings.Fields(schedule)
 	if len(fields) == 5 || len(fields) == 6 || strings.HasPrefix(schedule, "@") {
 		// Validate the cron expression by parsing it
-		parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-		_, err := parser.Parse(schedule)
+		_, err := scheduleParser.Parse(schedule)
 		if err == nil {
 			// It's a valid cron expression
 			return schedule, 0, nil
 		}
 
-		return "", 0, fmt.Errorf("invalid cron expression: %w", err)
+		return "", 0, fmt.Errorf("%w: invalid cron expression: %v", ErrInvalidSchedule, err)
 	}
 
 	return "", 0, ErrInvalidSchedule
 }
 
 // AddTaskWithSchedule adds a new task with a schedule string
-func (s *schedulerService) AddTaskWithSchedule(id, description, schedule string, taskFunc func() error) (string, error) {
+func (s *schedulerService) AddTaskWithSchedule(id, description, schedule string, taskFunc func(ctx context.Context) error) (string, error) {
+	return s.addTask(id, description, schedule, "", nil, taskFunc)
+}
+
+// AddJob adds a new task that runs a registered job type. The job type and params are persisted,
+// so the task is bound to the same handler when it is loaded after a restart.
+func (s *schedulerService) AddJob(id, description, schedule, jobType string, params interface{}) (string, error) {
+	rawParams, err := encodeJobParams(params)
+	if err != nil {
+		return "", err
+	}
+
+	run, err := bindJob(jobType, rawParams)
+	if err != nil {
+		return "", err
+	}
+
+	return s.addTask(id, description, schedule, jobType, rawParams, run)
+}
+
+// addTask adds a new task with a schedule string and persists it
+func (s *schedulerService) addTask(id, description, schedule, jobType string, params json.RawMessage,
+	taskFunc func(ctx context.Context) error) (string, error) {
 	// Parse the schedule string into a cron expression
 	cronExpr, interval, err := parseSchedule(schedule)
 	if err != nil {
@@ -139,7 +261,12 @@ func (s *schedulerService) AddTaskWithSchedule(id, description, schedule string,
 	defer s.taskMutex.Unlock()
 
 	// Check if a task with this ID already exists
-	if _, exists := s.tasks[id]; exists {
+	if existing, exists := s.tasks[id]; exists {
+		// A persisted task that could not be bound to a handler is taken over by the job
+		if jobType != "" && existing.bindErr != nil {
+			s.rebindTask(existing, jobType, params, taskFunc)
+			return id, nil
+		}
 		return "", ErrTaskAlreadyExists
 	}
 
@@ -152,6 +279,8 @@ func (s *schedulerService) AddTaskWithSchedule(id, description, schedule string,
 		Description: description,
 		Schedule:    schedule,
 		Interval:    interval,
+		JobType:     jobType,
+		Params:      params,
 		Func:        taskFunc,
 		IsRunning:   false,
 		Status:      repo.TaskStatusPending,
@@ -168,6 +297,8 @@ func (s *schedulerService) AddTaskWithSchedule(id, description, schedule string,
 			ID:          id,
 			Description: description,
 			Schedule:    schedule,
+			JobType:     jobType,
+			Params:      string(params),
 			Status:      repo.TaskStatusPending,
 			NextRun:     &nextRun,
 			CreatedAt:   time.Now(),
@@ -219,7 +350,7 @@ func (s *schedulerService) AddTaskWithSchedule(id, description, schedule string,
 }
 
 // AddTask adds a new task to the scheduler
-func (s *schedulerService) AddTask(id, description string, interval time.Duration, taskFunc func() error) (string, error) {
+func (s *schedulerService) AddTask(id, description string, interval time.Duration, taskFunc func(ctx context.Context) error) (string, error) {
 	// Convert duration to cron expression
 	cronExpr := durationToCronExpression(interval)
 
@@ -388,7 +519,7 @@ func (s *schedulerService) GetTask(id string) (*Task, error) {
 func (s *schedulerService) startTask(task *Task, cronExpr string) {
 	// Add the task to cron
 	entryID, err := s.cron.AddFunc(cronExpr, func() {
-		s.executeTask(task)
+		s.runScheduled(task)
 	})
 
 	if err != nil {
@@ -425,15 +556,30 @@ func (s *schedulerService) Start() error {
 
 	// Load tasks from database
 	if err := s.loadTasks(); err != nil {
-		s.logger.Error("Failed to load tasks from database", zap.Error(err))
+		s.logger.Error("Failed to load tasks", zap.Error(err))
 		// Continue anyway, as we might have in-memory tasks
 	}
 
 	// Start the cron scheduler
 	s.cron.Start()
 
+	// Campaign for leadership when only the leader runs scheduled tasks
+	if s.cluster != nil {
+		go s.cluster.campaign(s.ctx)
+	}
+
+	// Pick up changes made to the tasks on other replicas
+	if s.repository != nil {
+		go s.syncLoop(s.ctx)
+	}
+
 	// Start all tasks
 	for _, task := range s.tasks {
+		// Disabled tasks stay registered so they can be resumed, but are not scheduled
+		if task.Status == repo.TaskStatusDisabled {
+			continue
+		}
+
 		var cronExpr string
 
 		// Check if the schedule is a cron expression
@@ -463,18 +609,14 @@ func (s *schedulerService) loadTasks() error {
 			s.logger.Info("Loaded tasks from cache", zap.Int("count", len(cachedTasks)))
 
 			// Convert cached tasks to service tasks
+			var errs []error
 			for _, cachedTask := range cachedTasks {
-				// Skip disabled tasks
-				if cachedTask.Status == repo.TaskStatusDisabled {
-					s.logger.Info("Skipping disabled task", zap.String("id", cachedTask.ID))
-					continue
+				if err := s.addLoadedTask(cachedTask.ToTask()); err != nil {
+					errs = append(errs, err)
 				}
-
-				task := s.convertRepoTaskToServiceTask(cachedTask.ToTask())
-				s.tasks[task.ID] = task
 			}
 
-			return nil
+			return errors.Join(errs...)
 		}
 	} else {
 		s.logger.Warn("Cache is not available, skipping cache lookup")
@@ -494,13 +636,8 @@ func (s *schedulerService) loadTasks() error {
 	s.logger.Info("Loaded tasks from database", zap.Int("count", len(dbTasks)))
 
 	// Convert database tasks to service tasks and update cache
+	var errs []error
 	for _, dbTask := range dbTasks {
-		// Skip disabled tasks
-		if dbTask.Status == repo.TaskStatusDisabled {
-			s.logger.Info("Skipping disabled task", zap.String("id", dbTask.ID))
-			continue
-		}
-
 		// Update cache if available
 		if s.cache != nil {
 			if err := s.cache.Set(ctx, dbTask); err != nil {
@@ -508,24 +645,80 @@ func (s *schedulerService) loadTasks() error {
 			}
 		}
 
-		task := s.convertRepoTaskToServiceTask(dbTask.ToTask())
-		s.tasks[task.ID] = task
+		if err := s.addLoadedTask(dbTask.ToTask()); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
-// convertRepoTaskToServiceTask converts a repository task to a service task
-func (s *schedulerService) convertRepoTaskToServiceTask(repoTask *repo.Task) *Task {
-	// Create a placeholder function that logs the task execution
-	// Real functions will be registered separately
-	placeholderFunc := func() error {
-		s.logger.Info("Executing task loaded from database",
-			zap.String("id", repoTask.ID),
-			zap.String("description", repoTask.Description))
-		return nil
+// addLoadedTask adds a task loaded from the database or cache. A task with a job type is bound
+// to the registered handler of that type, otherwise a task already registered in code keeps its
+// function. Either way it takes over the persisted state, so that pauses and schedule changes
+// made at runtime survive restarts. A task that cannot be bound is kept so that it shows up
+// with its error, every run of it fails with that error.
+func (s *schedulerService) addLoadedTask(repoTask *repo.Task) error {
+	task := s.convertRepoTaskToServiceTask(repoTask)
+	existing, exists := s.tasks[task.ID]
+
+	switch {
+	case task.JobType != "":
+		run, err := bindJob(task.JobType, task.Params)
+		if err != nil {
+			task.bindErr = fmt.Errorf("task %s: %w", task.ID, err)
+		} else {
+			task.Func = run
+		}
+	case exists && existing.bindErr == nil:
+		task.Func = existing.Func
+		if existing.JobType != "" {
+			// The persisted task predates job types, record the job it was registered with
+			task.JobType, task.Params = existing.JobType, existing.Params
+			s.persistJob(task)
+		}
+	default:
+		task.bindErr = fmt.Errorf("task %s: %w", task.ID, ErrTaskNotBound)
+	}
+
+	if task.bindErr != nil {
+		bindErr := task.bindErr
+		task.Func = func(context.Context) error {
+			return bindErr
+		}
+		task.Error = bindErr
+		s.logger.Error("Failed to bind task to a job handler",
+			zap.String("id", task.ID),
+			zap.String("jobType", task.JobType),
+			zap.Error(bindErr))
+	}
+
+	if task.Status == repo.TaskStatusDisabled {
+		s.logger.Info("Loaded disabled task", zap.String("id", task.ID))
 	}
+	s.tasks[task.ID] = task
+
+	return task.bindErr
+}
+
+// rebindTask binds a task that could not be bound when it was loaded to a job, the caller must
+// hold the task lock
+func (s *schedulerService) rebindTask(task *Task, jobType string, params json.RawMessage, job func(ctx context.Context) error) {
+	task.JobType = jobType
+	task.Params = params
+	task.Func = job
+	task.Error = nil
+	task.bindErr = nil
+	s.persistJob(task)
+
+	s.logger.Info("Task bound to job handler",
+		zap.String("id", task.ID),
+		zap.String("jobType", jobType))
+}
 
+// convertRepoTaskToServiceTask converts a repository task to a service task, the function
+// of the task is bound by the caller
+func (s *schedulerService) convertRepoTaskToServiceTask(repoTask *repo.Task) *Task {
 	// Parse the schedule
 	var interval time.Duration
 
@@ -551,7 +744,7 @@ func (s *schedulerService) convertRepoTaskToServiceTask(repoTask *repo.Task) *Ta
 		Description: repoTask.Description,
 		Schedule:    repoTask.Schedule,
 		Interval:    interval,
-		Func:        placeholderFunc,
+		JobType:     repoTask.JobType,
 		IsRunning:   false,
 		Status:      repoTask.Status,
 	}
@@ -566,34 +759,141 @@ func (s *schedulerService) convertRepoTaskToServiceTask(repoTask *repo.Task) *Ta
 		task.NextRun = *repoTask.NextRun
 	}
 
+	// Set Params if available
+	if repoTask.Params != "" {
+		task.Params = json.RawMessage(repoTask.Params)
+	}
+
+	// Restore the policy, an invalid policy falls back to the default policy
+	if policy, err := parsePolicy(repoTask.Policy); err != nil {
+		s.logger.Warn("Failed to parse task policy, using the default policy",
+			zap.String("id", repoTask.ID),
+			zap.Error(err))
+	} else {
+		task.Policy = policy
+	}
+
+	// Restore the last error so that it can be reported
+	if repoTask.LastError != "" {
+		task.Error = errors.New(repoTask.LastError)
+	}
+
 	return task
 }
 
-// Stop stops the scheduler
-func (s *schedulerService) Stop() error {
+// SetCluster enables coordination with other replicas sharing the same tasks
+func (s *schedulerService) SetCluster(mode ClusterMode, locker repo.Locker, ttl time.Duration, nodeID string) error {
+	c, err := newCluster(mode, locker, ttl, nodeID, s.logger)
+	if err != nil {
+		return err
+	}
+
 	s.taskMutex.Lock()
 	defer s.taskMutex.Unlock()
 
+	if s.running {
+		return errors.New("cluster mode must be set before the scheduler starts")
+	}
+	s.cluster = c
+
+	return nil
+}
+
+// Stop stops the scheduler. No new runs start, running tasks and pending retries are cancelled
+// through their context, and Stop waits for them to return for at most the shutdown timeout.
+func (s *schedulerService) Stop() error {
+	s.taskMutex.Lock()
 	if !s.running {
+		s.taskMutex.Unlock()
 		return nil // Already stopped
 	}
+	s.running = false
+	timeout := s.shutdownTimeout
+	s.taskMutex.Unlock()
 
 	s.logger.Info("Stopping scheduler service")
 
-	// Stop the cron scheduler
-	ctx := s.cron.Stop()
+	// Stop the cron scheduler so that no new runs start, then cancel the running ones. The task
+	// lock is not held while waiting, running tasks need it to record their result.
+	cronCtx := s.cron.Stop()
+	s.cancel()
 
-	// Wait for all running jobs to complete
-	<-ctx.Done()
+	done := make(chan struct{})
+	go func() {
+		<-cronCtx.Done()
+		s.active.Wait()
+		close(done)
+	}()
 
-	// Cancel the context
-	s.cancel()
-	s.running = false
+	select {
+	case <-done:
+		s.logger.Info("Scheduler service stopped")
+	case <-time.After(timeout):
+		s.logger.Warn("Scheduler service stopped before all running tasks returned",
+			zap.Duration("timeout", timeout))
+	}
 	return nil
 }
 
-// executeTask executes a task and updates its status
-func (s *schedulerService) executeTask(task *Task) {
+// runScheduled runs a task fired by cron. The task is synced with the database first and the run is
+// skipped when the task was paused, rescheduled or removed on another replica. In cluster mode the
+// run is also skipped unless this replica claims it, so that every scheduled run happens on one replica only.
+// A firing while the previous run of the task is still in progress is skipped.
+func (s *schedulerService) runScheduled(task *Task) {
+	if !s.syncTask(task) {
+		return
+	}
+
+	s.taskMutex.RLock()
+	running := task.IsRunning
+	s.taskMutex.RUnlock()
+	if running {
+		s.logger.Warn("Skipping scheduled run, the previous run is still in progress",
+			zap.String("id", task.ID))
+		return
+	}
+
+	slot, window := s.runSlot(task)
+	runCtx, ok := s.cluster.claimRun(s.ctx, task.ID, slot, window)
+	if !ok {
+		return
+	}
+
+	s.executeTask(runCtx, task, repo.RunTriggerSchedule)
+}
+
+// runSlot returns the scheduled time of the current run of a task, which is the same on every replica,
+// and how long after it other replicas may fire for the same run. Cron expressions fire at the same
+// time everywhere, while "@every" schedules depend on when each replica started and are aligned to
+// multiples of their interval.
+func (s *schedulerService) runSlot(task *Task) (time.Time, time.Duration) {
+	s.taskMutex.RLock()
+	schedule, entryID := task.Schedule, task.entryID
+	s.taskMutex.RUnlock()
+
+	now := time.Now()
+	if cronExpr, _, err := parseSchedule(schedule); err == nil {
+		if parsed, err := scheduleParser.Parse(cronExpr); err == nil {
+			if every, ok := parsed.(cron.ConstantDelaySchedule); ok {
+				return now.Truncate(every.Delay), every.Delay
+			}
+		}
+	}
+
+	if entryID != 0 {
+		if prev := s.cron.Entry(entryID).Prev; !prev.IsZero() {
+			return prev, 0
+		}
+	}
+	return now.Truncate(time.Second), 0
+}
+
+// executeTask executes a task with its policy, retrying failed attempts, updates its status and
+// records every attempt. runCtx is passed to the task and is cancelled when the scheduler stops.
+func (s *schedulerService) executeTask(runCtx context.Context, task *Task, trigger string) {
+	s.active.Add(1)
+	defer s.active.Done()
+
 	ctx := context.Background()
 	now := time.Now()
 
@@ -611,6 +911,8 @@ func (s *schedulerService) executeTask(task *Task) {
 			task.NextRun = now.Add(task.Interval)
 		}
 	}
+	policy := s.policyFor(task)
+	taskFunc := task.Func
 	s.taskMutex.Unlock()
 
 	// Update task status in database and cache
@@ -650,8 +952,40 @@ func (s *schedulerService) executeTask(task *Task) {
 		zap.String("id", task.ID),
 		zap.String("description", task.Description))
 
-	// Execute the task
-	err := task.Func()
+	// Run the attempts until one succeeds, they are exhausted or the scheduler stops
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		err = s.runAttempt(runCtx, task.ID, taskFunc, trigger, attempt, policy.Timeout)
+		if err == nil || attempt >= policy.MaxAttempts || runCtx.Err() != nil {
+			break
+		}
+		if errors.Is(err, ErrTaskAbandoned) {
+			// The abandoned attempt may still be running, a retry would run the task twice at once
+			break
+		}
+
+		wait := retryWait(policy, attempt)
+		s.logger.Warn("Task attempt failed, retrying",
+			zap.String("id", task.ID),
+			zap.Int("attempt", attempt),
+			zap.Int("maxAttempts", policy.MaxAttempts),
+			zap.Duration("backoff", wait),
+			zap.Error(err))
+		s.setRunStatus(task, repo.TaskStatusRetrying)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-runCtx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if runCtx.Err() != nil {
+			break
+		}
+		s.setRunStatus(task, repo.TaskStatusRunning)
+	}
+
 	lastError := ""
 	status := repo.TaskStatusCompleted
 
@@ -663,6 +997,10 @@ func (s *schedulerService) executeTask(task *Task) {
 	s.taskMutex.Lock()
 	task.IsRunning = false
 	task.Error = err
+	if task.Status == repo.TaskStatusDisabled {
+		// The task was paused while running, keep it disabled
+		status = repo.TaskStatusDisabled
+	}
 	task.Status = status
 	s.taskMutex.Unlock()
 
@@ -685,12 +1023,87 @@ func (s *schedulerService) executeTask(task *Task) {
 		}
 	}()
 
-	if err != nil {
+	switch {
+	case err == nil:
+		s.logger.Info("Task executed successfully",
+			zap.String("id", task.ID),
+			zap.Int("attempts", attempt))
+	case runCtx.Err() != nil:
+		// Cancelled because the scheduler stops, this is not a failure of the task
+		s.logger.Warn("Task execution cancelled",
+			zap.String("id", task.ID),
+			zap.Int("attempts", attempt),
+			zap.Error(err))
+	default:
 		s.logger.Error("Task execution failed",
 			zap.String("id", task.ID),
+			zap.Int("attempts", attempt),
 			zap.Error(err))
-	} else {
-		s.logger.Info("Task executed successfully",
-			zap.String("id", task.ID))
+		s.raiseAlert(TaskAlert{
+			TaskID:      task.ID,
+			Description: task.Description,
+			Attempts:    attempt,
+			Error:       lastError,
+			FailedAt:    time.Now(),
+			Node:        s.nodeID,
+			Trigger:     trigger,
+		})
+	}
+}
+
+// runAttempt runs a single attempt of a task and records it in the run history. The attempt is
+// cancelled after the timeout, and abandoned when it does not return soon after its context is
+// done, so that a hung task does not block the scheduler. A panic fails the attempt.
+func (s *schedulerService) runAttempt(runCtx context.Context, taskID string, taskFunc func(ctx context.Context) error,
+	trigger string, attempt int, timeout Duration) error {
+	startedAt := time.Now()
+	runID, output, attemptCtx := s.startRun(runCtx, taskID, trigger, attempt, startedAt)
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(attemptCtx, time.Duration(timeout))
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("task panicked: %v", r)
+			}
+		}()
+		done <- taskFunc(attemptCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-attemptCtx.Done():
+		select {
+		case err = <-done:
+		case <-time.After(abandonGrace):
+			s.logger.Warn("Task did not return after its context was done, abandoning it",
+				zap.String("id", taskID),
+				zap.Error(attemptCtx.Err()))
+			err = fmt.Errorf("%w: %v", ErrTaskAbandoned, attemptCtx.Err())
+		}
+	}
+
+	status := repo.TaskStatusCompleted
+	if err != nil {
+		status = repo.TaskStatusFailed
+	}
+	go s.finishRun(runID, taskID, startedAt, status, err, output)
+
+	return err
+}
+
+// setRunStatus sets the status of a running task unless it was paused meanwhile
+func (s *schedulerService) setRunStatus(task *Task, status repo.TaskStatus) {
+	s.taskMutex.Lock()
+	defer s.taskMutex.Unlock()
+
+	if task.Status != repo.TaskStatusDisabled {
+		task.Status = status
 	}
 }
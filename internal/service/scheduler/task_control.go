@@ -0,0 +1,410 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	repo "goWebExample/internal/repository/scheduler"
+)
+
+// scheduleParser parses cron expressions the same way as the cron instance used by the scheduler
+var scheduleParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ListTaskInfos returns snapshots of all tasks ordered by ID
+func (s *schedulerService) ListTaskInfos() []TaskInfo {
+	s.taskMutex.RLock()
+	defer s.taskMutex.RUnlock()
+
+	infos := make([]TaskInfo, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		infos = append(infos, task.info(s.policyFor(task)))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+
+	return infos
+}
+
+// GetTaskInfo returns a snapshot of a specific task
+func (s *schedulerService) GetTaskInfo(id string) (TaskInfo, error) {
+	s.taskMutex.RLock()
+	defer s.taskMutex.RUnlock()
+
+	task, exists := s.tasks[id]
+	if !exists {
+		return TaskInfo{}, ErrTaskNotFound
+	}
+
+	return task.info(s.policyFor(task)), nil
+}
+
+// RunTask triggers a task immediately in the background on this replica, without coordinating with
+// other replicas in cluster mode. Disabled tasks must be resumed first.
+func (s *schedulerService) RunTask(id string) error {
+	s.taskMutex.RLock()
+	task, exists := s.tasks[id]
+	if !exists {
+		s.taskMutex.RUnlock()
+		return ErrTaskNotFound
+	}
+	status, running := task.Status, task.IsRunning
+	s.taskMutex.RUnlock()
+
+	if status == repo.TaskStatusDisabled {
+		return ErrTaskDisabled
+	}
+	if running {
+		return ErrTaskRunning
+	}
+
+	s.logger.Info("Task triggered manually", zap.String("id", id))
+	go s.executeTask(s.ctx, task, repo.RunTriggerManual)
+
+	return nil
+}
+
+// PauseTask disables a task so that it is no longer scheduled. A run in progress is not interrupted.
+func (s *schedulerService) PauseTask(id string) error {
+	s.taskMutex.Lock()
+	defer s.taskMutex.Unlock()
+
+	task, exists := s.tasks[id]
+	if !exists {
+		return ErrTaskNotFound
+	}
+	if task.Status == repo.TaskStatusDisabled {
+		return nil
+	}
+
+	if err := s.persistStatus(id, repo.TaskStatusDisabled); err != nil {
+		return err
+	}
+
+	if task.entryID != 0 {
+		s.cron.Remove(task.entryID)
+		task.entryID = 0
+	}
+	task.Status = repo.TaskStatusDisabled
+
+	s.logger.Info("Task paused", zap.String("id", id))
+	return nil
+}
+
+// ResumeTask re-enables a disabled task and schedules it again
+func (s *schedulerService) ResumeTask(id string) error {
+	s.taskMutex.Lock()
+	defer s.taskMutex.Unlock()
+
+	task, exists := s.tasks[id]
+	if !exists {
+		return ErrTaskNotFound
+	}
+	if task.Status != repo.TaskStatusDisabled {
+		return nil
+	}
+
+	cronExpr, _, err := parseSchedule(task.Schedule)
+	if err != nil {
+		return err
+	}
+	if err := s.persistStatus(id, repo.TaskStatusPending); err != nil {
+		return err
+	}
+
+	task.Status = repo.TaskStatusPending
+	if s.running {
+		s.startTask(task, cronExpr)
+	}
+	s.persistNextRun(task)
+
+	s.logger.Info("Task resumed", zap.String("id", id))
+	return nil
+}
+
+// UpdateSchedule changes the schedule of a task at runtime. The new schedule is persisted and
+// takes precedence over the schedule the task is registered with in code after a restart.
+func (s *schedulerService) UpdateSchedule(id, schedule string) error {
+	cronExpr, interval, err := parseSchedule(schedule)
+	if err != nil {
+		return err
+	}
+	next, err := scheduleParser.Parse(cronExpr)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+
+	s.taskMutex.Lock()
+	defer s.taskMutex.Unlock()
+
+	task, exists := s.tasks[id]
+	if !exists {
+		return ErrTaskNotFound
+	}
+
+	var nextRun *time.Time
+	if task.Status != repo.TaskStatusDisabled {
+		t := next.Next(time.Now())
+		nextRun = &t
+	}
+
+	ctx := context.Background()
+	if s.repository != nil {
+		if err := s.repository.UpdateSchedule(ctx, id, schedule, nextRun); err != nil && !errors.Is(err, repo.ErrTaskNotFound) {
+			return fmt.Errorf("failed to persist task schedule: %w", err)
+		}
+	}
+	if s.cache != nil {
+		if err := s.cache.UpdateSchedule(ctx, id, schedule, nextRun); err != nil && !errors.Is(err, repo.ErrTaskNotFound) {
+			s.logger.Warn("Failed to update task schedule in cache",
+				zap.String("id", id),
+				zap.Error(err))
+		}
+	}
+
+	if task.entryID != 0 {
+		s.cron.Remove(task.entryID)
+		task.entryID = 0
+	}
+	task.Schedule = schedule
+	task.Interval = interval
+	if nextRun != nil {
+		task.NextRun = *nextRun
+	}
+	if s.running && task.Status != repo.TaskStatusDisabled {
+		s.startTask(task, cronExpr)
+	}
+
+	s.logger.Info("Task schedule updated",
+		zap.String("id", id),
+		zap.String("schedule", schedule))
+	return nil
+}
+
+// persistStatus saves the status of a task. Database errors are returned so that the
+// in-memory state is not changed when it cannot be persisted, cache errors are only logged.
+func (s *schedulerService) persistStatus(id string, status repo.TaskStatus) error {
+	ctx := context.Background()
+
+	if s.repository != nil {
+		if err := s.repository.UpdateStatus(ctx, id, status); err != nil && !errors.Is(err, repo.ErrTaskNotFound) {
+			return fmt.Errorf("failed to persist task status: %w", err)
+		}
+	}
+
+	if s.cache != nil {
+		if err := s.cache.UpdateStatus(ctx, id, status); err != nil && !errors.Is(err, repo.ErrTaskNotFound) {
+			s.logger.Warn("Failed to update task status in cache",
+				zap.String("id", id),
+				zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// persistNextRun saves the next run time of a task, failures are only logged
+func (s *schedulerService) persistNextRun(task *Task) {
+	if task.NextRun.IsZero() {
+		return
+	}
+	ctx := context.Background()
+
+	if s.repository != nil {
+		if err := s.repository.UpdateNextRun(ctx, task.ID, task.NextRun); err != nil && !errors.Is(err, repo.ErrTaskNotFound) {
+			s.logger.Warn("Failed to update task next run in database",
+				zap.String("id", task.ID),
+				zap.Error(err))
+		}
+	}
+
+	if s.cache != nil {
+		if err := s.cache.UpdateNextRun(ctx, task.ID, task.NextRun); err != nil && !errors.Is(err, repo.ErrTaskNotFound) {
+			s.logger.Warn("Failed to update task next run in cache",
+				zap.String("id", task.ID),
+				zap.Error(err))
+		}
+	}
+}
+
+// persistJob saves the job type and params of a task, failures are only logged
+func (s *schedulerService) persistJob(task *Task) {
+	ctx := context.Background()
+
+	if s.repository != nil {
+		if err := s.repository.UpdateJob(ctx, task.ID, task.JobType, string(task.Params)); err != nil && !errors.Is(err, repo.ErrTaskNotFound) {
+			s.logger.Warn("Failed to update task job in database",
+				zap.String("id", task.ID),
+				zap.Error(err))
+		}
+	}
+
+	if s.cache != nil {
+		if err := s.cache.UpdateJob(ctx, task.ID, task.JobType, string(task.Params)); err != nil && !errors.Is(err, repo.ErrTaskNotFound) {
+			s.logger.Warn("Failed to update task job in cache",
+				zap.String("id", task.ID),
+				zap.Error(err))
+		}
+	}
+}
+
+// syncLoop syncs the tasks with the database every syncInterval until ctx is done
+func (s *schedulerService) syncLoop(ctx context.Context) {
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncTasks(ctx)
+		}
+	}
+}
+
+// syncTasks applies the persisted status, schedule and policy of every task to this replica. Tasks that
+// are no longer in the database were removed on another replica and are removed here as well.
+func (s *schedulerService) syncTasks(ctx context.Context) {
+	models, err := s.repository.FindAll(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to load tasks to sync", zap.Error(err))
+		return
+	}
+
+	persisted := make(map[string]*repo.TaskModel, len(models))
+	for _, model := range models {
+		persisted[model.ID] = model
+	}
+
+	s.taskMutex.Lock()
+	defer s.taskMutex.Unlock()
+
+	for id, task := range s.tasks {
+		if model, exists := persisted[id]; exists {
+			s.applyPersisted(task, model)
+		} else {
+			s.dropTask(task)
+		}
+	}
+}
+
+// syncTask applies the persisted status, schedule and policy of a task before a scheduled run and reports
+// whether the run should go ahead. When the task cannot be loaded it runs as scheduled.
+func (s *schedulerService) syncTask(task *Task) bool {
+	if s.repository == nil {
+		return true
+	}
+	model, err := s.repository.FindByID(s.ctx, task.ID)
+
+	s.taskMutex.Lock()
+	defer s.taskMutex.Unlock()
+
+	if s.tasks[task.ID] != task {
+		// Removed meanwhile
+		return false
+	}
+
+	switch {
+	case errors.Is(err, repo.ErrTaskNotFound):
+		s.dropTask(task)
+		return false
+	case err != nil:
+		s.logger.Warn("Failed to load task to sync, running it as scheduled",
+			zap.String("id", task.ID),
+			zap.Error(err))
+	case s.applyPersisted(task, model):
+		// Rescheduled or paused, this firing belongs to the old schedule
+		return false
+	}
+
+	return task.Status != repo.TaskStatusDisabled
+}
+
+// applyPersisted applies the persisted status, schedule and policy of a task that were changed on
+// another replica and reports whether the status or schedule changed. A changed policy applies from
+// the next run and is not reported. The caller must hold the task lock.
+func (s *schedulerService) applyPersisted(task *Task, model *repo.TaskModel) bool {
+	s.applyPersistedPolicy(task, model.Policy)
+
+	disabled := model.Status == repo.TaskStatusDisabled
+	changed := disabled != (task.Status == repo.TaskStatusDisabled)
+
+	if model.Schedule != "" && model.Schedule != task.Schedule {
+		if _, interval, err := parseSchedule(model.Schedule); err != nil {
+			s.logger.Warn("Ignoring invalid persisted task schedule",
+				zap.String("id", task.ID),
+				zap.String("schedule", model.Schedule),
+				zap.Error(err))
+		} else {
+			task.Schedule = model.Schedule
+			task.Interval = interval
+			changed = true
+		}
+	}
+	if !changed {
+		return false
+	}
+
+	if task.entryID != 0 {
+		s.cron.Remove(task.entryID)
+		task.entryID = 0
+	}
+	if disabled {
+		task.Status = repo.TaskStatusDisabled
+	} else {
+		if task.Status == repo.TaskStatusDisabled {
+			task.Status = repo.TaskStatusPending
+		}
+		if s.running {
+			cronExpr, _, _ := parseSchedule(task.Schedule)
+			s.startTask(task, cronExpr)
+		}
+	}
+
+	s.logger.Info("Task changed on another replica",
+		zap.String("id", task.ID),
+		zap.String("schedule", task.Schedule),
+		zap.String("status", string(task.Status)))
+	return true
+}
+
+// applyPersistedPolicy applies the persisted policy of a task when it differs from the policy the
+// task runs with. An invalid persisted policy is ignored. The caller must hold the task lock.
+func (s *schedulerService) applyPersistedPolicy(task *Task, raw string) {
+	policy, err := parsePolicy(raw)
+	if err != nil {
+		s.logger.Warn("Ignoring invalid persisted task policy",
+			zap.String("id", task.ID),
+			zap.Error(err))
+		return
+	}
+
+	switch {
+	case policy == nil && task.Policy == nil:
+		return
+	case policy != nil && task.Policy != nil && *policy == *task.Policy:
+		return
+	}
+	task.Policy = policy
+
+	s.logger.Info("Task policy changed on another replica",
+		zap.String("id", task.ID),
+		zap.Bool("default", policy == nil))
+}
+
+// dropTask removes a task that was removed on another replica. The caller must hold the task lock.
+func (s *schedulerService) dropTask(task *Task) {
+	if task.entryID != 0 {
+		s.cron.Remove(task.entryID)
+		task.entryID = 0
+	}
+	delete(s.tasks, task.ID)
+
+	s.logger.Info("Task removed on another replica", zap.String("id", task.ID))
+}
@@ -0,0 +1,251 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	repo "goWebExample/internal/repository/scheduler"
+)
+
+// memoryTaskRepository is a TaskRepository shared by the replicas of a test
+type memoryTaskRepository struct {
+	mu    sync.Mutex
+	tasks map[string]repo.TaskModel
+}
+
+func newMemoryTaskRepository() *memoryTaskRepository {
+	return &memoryTaskRepository{tasks: make(map[string]repo.TaskModel)}
+}
+
+func (r *memoryTaskRepository) update(id string, apply func(m *repo.TaskModel)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	model, ok := r.tasks[id]
+	if !ok {
+		return repo.ErrTaskNotFound
+	}
+	apply(&model)
+	r.tasks[id] = model
+	return nil
+}
+
+func (r *memoryTaskRepository) Create(_ context.Context, task *repo.TaskModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[task.ID]; ok {
+		return repo.ErrTaskAlreadyExists
+	}
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+func (r *memoryTaskRepository) Update(_ context.Context, task *repo.TaskModel) error {
+	return r.update(task.ID, func(m *repo.TaskModel) { *m = *task })
+}
+
+func (r *memoryTaskRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[id]; !ok {
+		return repo.ErrTaskNotFound
+	}
+	delete(r.tasks, id)
+	return nil
+}
+
+func (r *memoryTaskRepository) FindByID(_ context.Context, id string) (*repo.TaskModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	model, ok := r.tasks[id]
+	if !ok {
+		return nil, repo.ErrTaskNotFound
+	}
+	return &model, nil
+}
+
+func (r *memoryTaskRepository) FindAll(_ context.Context) ([]*repo.TaskModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	models := make([]*repo.TaskModel, 0, len(r.tasks))
+	for _, model := range r.tasks {
+		model := model
+		models = append(models, &model)
+	}
+	return models, nil
+}
+
+func (r *memoryTaskRepository) FindByStatus(ctx context.Context, status repo.TaskStatus) ([]*repo.TaskModel, error) {
+	all, _ := r.FindAll(ctx)
+	models := make([]*repo.TaskModel, 0, len(all))
+	for _, model := range all {
+		if model.Status == status {
+			models = append(models, model)
+		}
+	}
+	return models, nil
+}
+
+func (r *memoryTaskRepository) UpdateStatus(_ context.Context, id string, status repo.TaskStatus) error {
+	return r.update(id, func(m *repo.TaskModel) { m.Status = status })
+}
+
+func (r *memoryTaskRepository) UpdateNextRun(_ context.Context, id string, nextRun time.Time) error {
+	return r.update(id, func(m *repo.TaskModel) { m.NextRun = &nextRun })
+}
+
+func (r *memoryTaskRepository) UpdateLastRun(_ context.Context, id string, lastRun time.Time, status repo.TaskStatus, lastError string) error {
+	return r.update(id, func(m *repo.TaskModel) {
+		m.LastRun = &lastRun
+		if m.Status != repo.TaskStatusDisabled {
+			m.Status = status
+		}
+		m.LastError = lastError
+	})
+}
+
+func (r *memoryTaskRepository) UpdateSchedule(_ context.Context, id string, schedule string, nextRun *time.Time) error {
+	return r.update(id, func(m *repo.TaskModel) { m.Schedule, m.NextRun = schedule, nextRun })
+}
+
+func (r *memoryTaskRepository) UpdateJob(_ context.Context, id string, jobType string, params string) error {
+	return r.update(id, func(m *repo.TaskModel) { m.JobType, m.Params = jobType, params })
+}
+
+func (r *memoryTaskRepository) UpdatePolicy(_ context.Context, id string, policy string) error {
+	return r.update(id, func(m *repo.TaskModel) { m.Policy = policy })
+}
+
+// newReplica starts a scheduler sharing the repository with the other replicas of a test, runs
+// counts the runs of its task
+func newReplica(t *testing.T, tasks repo.TaskRepository, runs *int) *schedulerService {
+	t.Helper()
+
+	s := NewSchedulerService(zap.NewNop(), tasks, nil).(*schedulerService)
+	if _, err := s.AddTaskWithSchedule("sync", "sync test", "@every 1h", func(context.Context) error {
+		*runs++
+		return nil
+	}); err != nil {
+		t.Fatalf("AddTaskWithSchedule() error = %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = s.Stop() })
+	return s
+}
+
+func TestChangesOnOtherReplicas(t *testing.T) {
+	tasks := newMemoryTaskRepository()
+	var runsA, runsB int
+	a := newReplica(t, tasks, &runsA)
+	b := newReplica(t, tasks, &runsB)
+
+	task := func(s *schedulerService) *Task {
+		s.taskMutex.RLock()
+		defer s.taskMutex.RUnlock()
+		return s.tasks["sync"]
+	}
+	scheduled := func(s *schedulerService) bool {
+		s.taskMutex.RLock()
+		defer s.taskMutex.RUnlock()
+		return s.tasks["sync"].entryID != 0
+	}
+
+	// A paused task is skipped and unscheduled on the other replica when it fires there
+	if err := a.PauseTask("sync"); err != nil {
+		t.Fatalf("PauseTask() error = %v", err)
+	}
+	b.runScheduled(task(b))
+	if runsB != 0 {
+		t.Error("task paused on another replica should not run")
+	}
+	if info, _ := b.GetTaskInfo("sync"); info.Status != repo.TaskStatusDisabled || scheduled(b) {
+		t.Errorf("status = %s, scheduled = %v, want the task paused", info.Status, scheduled(b))
+	}
+
+	// A resumed task is scheduled again on the next sync
+	if err := a.ResumeTask("sync"); err != nil {
+		t.Fatalf("ResumeTask() error = %v", err)
+	}
+	b.syncTasks(context.Background())
+	if info, _ := b.GetTaskInfo("sync"); info.Status == repo.TaskStatusDisabled || !scheduled(b) {
+		t.Errorf("status = %s, scheduled = %v, want the task resumed", info.Status, scheduled(b))
+	}
+	b.runScheduled(task(b))
+	if runsB != 1 {
+		t.Errorf("runs = %d, want the resumed task to run", runsB)
+	}
+
+	// A rescheduled task takes the new schedule, the firing of the old schedule is skipped
+	if err := a.UpdateSchedule("sync", "0 0 3 * * *"); err != nil {
+		t.Fatalf("UpdateSchedule() error = %v", err)
+	}
+	b.runScheduled(task(b))
+	if runsB != 1 {
+		t.Errorf("runs = %d, want the firing of the old schedule skipped", runsB)
+	}
+	if info, _ := b.GetTaskInfo("sync"); info.Schedule != "0 0 3 * * *" || !scheduled(b) {
+		t.Errorf("schedule = %q, scheduled = %v, want the new schedule", info.Schedule, scheduled(b))
+	}
+
+	// A removed task is removed on the next sync
+	if err := a.RemoveTask("sync"); err != nil {
+		t.Fatalf("RemoveTask() error = %v", err)
+	}
+	b.syncTasks(context.Background())
+	if _, err := b.GetTask("sync"); err != ErrTaskNotFound {
+		t.Errorf("GetTask() error = %v, want the task removed", err)
+	}
+	if runsA != 0 {
+		t.Errorf("runs on the replica changing the task = %d, want 0", runsA)
+	}
+}
+
+func TestPolicyChangesOnOtherReplicas(t *testing.T) {
+	tasks := newMemoryTaskRepository()
+	var runsA, runsB int
+	a := newReplica(t, tasks, &runsA)
+	b := newReplica(t, tasks, &runsB)
+
+	task := func(s *schedulerService) *Task {
+		s.taskMutex.RLock()
+		defer s.taskMutex.RUnlock()
+		return s.tasks["sync"]
+	}
+
+	// A policy set on another replica is applied on the next sync
+	policy := TaskPolicy{
+		Timeout:        Duration(time.Minute),
+		MaxAttempts:    3,
+		InitialBackoff: Duration(time.Second),
+		MaxBackoff:     Duration(time.Minute),
+		Multiplier:     2,
+	}
+	if err := a.SetTaskPolicy("sync", &policy); err != nil {
+		t.Fatalf("SetTaskPolicy() error = %v", err)
+	}
+	b.syncTasks(context.Background())
+	if info, _ := b.GetTaskInfo("sync"); !info.PolicySet || info.Policy != policy {
+		t.Errorf("policy = %+v, set = %v, want %+v", info.Policy, info.PolicySet, policy)
+	}
+
+	// A policy reset on another replica is applied before the next run, which still goes ahead
+	if err := a.SetTaskPolicy("sync", nil); err != nil {
+		t.Fatalf("SetTaskPolicy() error = %v", err)
+	}
+	b.runScheduled(task(b))
+	if info, _ := b.GetTaskInfo("sync"); info.PolicySet || info.Policy != b.defaultPolicy {
+		t.Errorf("policy = %+v, set = %v, want the default policy", info.Policy, info.PolicySet)
+	}
+	if runsB != 1 {
+		t.Errorf("runs = %d, want the run to go ahead with the new policy", runsB)
+	}
+}
@@ -0,0 +1,41 @@
+package user
+
+import "errors"
+
+// 定义错误
+var (
+	ErrInvalidCredentials = errors.New("用户不存在或密码错误")
+	ErrAccountLocked      = errors.New("账户已被锁定，请稍后再试")
+	ErrAccountDisabled    = errors.New("账户已被禁用")
+	ErrUserNotFound       = errors.New("用户不存在")
+	ErrInvalidUserID      = errors.New("无效的用户ID")
+	ErrUsernameExists     = errors.New("用户名已存在")
+	ErrEmailExists        = errors.New("邮箱已被使用")
+	ErrUserConflict       = errors.New("用户名或邮箱已存在")
+	ErrEmailNotVerified   = errors.New("邮箱未验证，请先完成邮箱验证")
+	ErrChangeOwnSuperuser = errors.New("不能修改自己的超级管理员状态")
+
+	ErrRegistrationDisabled     = errors.New("暂未开放注册")
+	ErrInvalidVerificationToken = errors.New("验证链接无效或已过期")
+	ErrVerificationTokenUsed    = errors.New("验证链接已被使用")
+
+	ErrInvalidResetToken = errors.New("重置链接无效或已过期")
+	ErrResetTokenUsed    = errors.New("重置链接已被使用")
+	ErrIncorrectPassword = errors.New("原密码错误")
+
+	ErrTwoFactorUnavailable    = errors.New("双重认证未配置")
+	ErrTwoFactorAlreadyEnabled = errors.New("已启用双重认证")
+	ErrTwoFactorNotEnrolled    = errors.New("请先获取双重认证密钥")
+	ErrTwoFactorNotEnabled     = errors.New("未启用双重认证")
+	ErrInvalidTwoFactorCode    = errors.New("验证码错误")
+	ErrInvalidMFAToken         = errors.New("登录验证已过期，请重新登录")
+
+	ErrExternalLoginUnavailable = errors.New("第三方登录不可用")
+	ErrIdentityNotLinked        = errors.New("第三方账号未关联本站用户")
+
+	ErrInvalidRefreshToken = errors.New("refresh token无效或已过期")
+	ErrRefreshTokenReused  = errors.New("refresh token已被使用，请重新登录")
+
+	ErrSessionUnavailable = errors.New("未启用会话登录")
+	ErrSessionNotFound    = errors.New("会话不存在")
+)
@@ -0,0 +1,435 @@
+package user
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	jwtpkg "goWebExample/internal/pkg/jwt"
+	"goWebExample/internal/pkg/password"
+	"goWebExample/internal/repository/session"
+	"goWebExample/internal/repository/token"
+	"goWebExample/internal/repository/user"
+)
+
+// fakeUserRepo 内存中的用户仓储，只实现测试用到的行为
+type fakeUserRepo struct {
+	mu     sync.Mutex
+	users  map[uint64]*user.Users
+	nextID uint64
+	gets   int // GetByID 与 GetByUUID 的调用次数
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	return &fakeUserRepo{users: make(map[uint64]*user.Users)}
+}
+
+// add 保存用户的副本并分配 ID
+func (r *fakeUserRepo) add(u user.Users) *user.Users {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	u.ID = r.nextID
+	r.users[u.ID] = &u
+	return &u
+}
+
+// get 返回用户的当前状态
+func (r *fakeUserRepo) get(id uint64) user.Users {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.users[id]
+}
+
+func (r *fakeUserRepo) GetDB() *gorm.DB { return nil }
+
+func (r *fakeUserRepo) Create(u *user.Users) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Username == u.Username || existing.Email == u.Email {
+			return user.ErrDuplicateUser
+		}
+	}
+	r.nextID++
+	u.ID = r.nextID
+	stored := *u
+	r.users[u.ID] = &stored
+	return nil
+}
+
+func (r *fakeUserRepo) GetByID(id uint64) (*user.Users, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.gets++
+	u, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *u
+	return &copied, nil
+}
+
+func (r *fakeUserRepo) GetByUUID(uuid string) (*user.Users, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.gets++
+	for _, u := range r.users {
+		if u.UUID == uuid {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) GetAll() ([]user.Users, error) { return nil, nil }
+
+func (r *fakeUserRepo) List(user.ListQuery) ([]user.Users, int64, error) { return nil, 0, nil }
+
+func (r *fakeUserRepo) Update(id uint64, updates map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return user.ErrUserNotFound
+	}
+	for column, value := range updates {
+		switch column {
+		case "is_active":
+			u.IsActive = value.(bool)
+		case "is_superuser":
+			u.IsSuperuser = value.(bool)
+		case "security_stamp":
+			stamp := value.(string)
+			u.SecurityStamp = &stamp
+		case "nickname":
+			u.Nickname = value.(string)
+		case "email":
+			u.Email = value.(string)
+		case "email_verified":
+			u.EmailVerified = value.(bool)
+		}
+	}
+	return nil
+}
+
+func (r *fakeUserRepo) Delete(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[uint64(id)]
+	if !ok {
+		return user.ErrUserNotFound
+	}
+	u.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (r *fakeUserRepo) Restore(uint64) error { return nil }
+
+func (r *fakeUserRepo) GetUserByUsername(username string) (*user.Users, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.users {
+		if u.Username == username {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) GetUserByEmail(string) (*user.Users, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) UpdateLoginInfo(uint64, string) error { return nil }
+
+func (r *fakeUserRepo) UpdatePasswordHash(id uint64, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].PasswordHash = hash
+	return nil
+}
+
+func (r *fakeUserRepo) UpdatePassword(id uint64, hash, stamp string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].PasswordHash = hash
+	r.users[id].SecurityStamp = &stamp
+	return nil
+}
+
+func (r *fakeUserRepo) ReplaceRecoveryCodes(id uint64, old, new string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u := r.users[id]
+	current := ""
+	if u.RecoveryCodes != nil {
+		current = *u.RecoveryCodes
+	}
+	if current != old {
+		return false, nil
+	}
+	u.RecoveryCodes = &new
+	return true, nil
+}
+
+func (r *fakeUserRepo) IncrementFailedLoginAttempts(id uint64, window time.Duration) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u := r.users[id]
+	now := time.Now()
+	attempts := 1
+	if u.FailedLoginAttempts != nil && u.LastFailedLoginAt != nil && !u.LastFailedLoginAt.Before(now.Add(-window)) {
+		attempts = *u.FailedLoginAttempts + 1
+	}
+	u.FailedLoginAttempts = &attempts
+	u.LastFailedLoginAt = &now
+	return attempts, nil
+}
+
+func (r *fakeUserRepo) LockUntil(id uint64, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].LockoutEnd = &until
+	return nil
+}
+
+func (r *fakeUserRepo) ResetLoginFailures(id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	zero := 0
+	r.users[id].FailedLoginAttempts = &zero
+	r.users[id].LockoutEnd = nil
+	return nil
+}
+
+// newTestService 创建使用内存仓储与低开销哈希参数的用户服务
+func newTestService(t *testing.T) (*UserService, *fakeUserRepo) {
+	t.Helper()
+
+	hasher, err := password.NewHasher(password.Config{
+		Argon2: password.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1},
+	})
+	if err != nil {
+		t.Fatalf("NewHasher() error = %v", err)
+	}
+
+	jwtManager, err := jwtpkg.NewJWTManager(jwtpkg.Config{SecretKey: "test-secret", Issuer: "test"})
+	if err != nil {
+		t.Fatalf("NewJWTManager() error = %v", err)
+	}
+
+	repo := newFakeUserRepo()
+	return NewUserService(repo, zap.NewNop(), jwtManager, hasher), repo
+}
+
+// mustHash 计算测试密码的哈希
+func mustHash(t *testing.T, s *UserService, plain string) string {
+	t.Helper()
+
+	hash, err := s.hasher.Hash(plain)
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	return hash
+}
+
+// fakeTokenStore 内存中的 token 存储
+type fakeTokenStore struct {
+	mu       sync.Mutex
+	refresh  map[string]*token.RefreshToken
+	used     map[string]bool
+	families map[string]bool
+	revoked  map[string]time.Time
+	actions  map[string]time.Time
+}
+
+func newFakeTokenStore() *fakeTokenStore {
+	return &fakeTokenStore{
+		refresh:  make(map[string]*token.RefreshToken),
+		used:     make(map[string]bool),
+		families: make(map[string]bool),
+		revoked:  make(map[string]time.Time),
+		actions:  make(map[string]time.Time),
+	}
+}
+
+func (f *fakeTokenStore) SaveRefreshToken(_ context.Context, t *token.RefreshToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copied := *t
+	f.refresh[t.TokenHash] = &copied
+	return nil
+}
+
+func (f *fakeTokenStore) GetRefreshToken(_ context.Context, tokenHash string) (*token.RefreshToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, ok := f.refresh[tokenHash]
+	if !ok || !time.Now().Before(t.ExpiresAt) {
+		return nil, token.ErrRefreshTokenNotFound
+	}
+	copied := *t
+	return &copied, nil
+}
+
+func (f *fakeTokenStore) MarkRefreshTokenUsed(_ context.Context, tokenHash string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.used[tokenHash] {
+		return false, nil
+	}
+	f.used[tokenHash] = true
+	return true, nil
+}
+
+func (f *fakeTokenStore) RevokeFamily(_ context.Context, familyID string, _ time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.families[familyID] = true
+	return nil
+}
+
+func (f *fakeTokenStore) IsFamilyRevoked(_ context.Context, familyID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.families[familyID], nil
+}
+
+func (f *fakeTokenStore) RevokeAccessToken(_ context.Context, jti string, expiresAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.revoked[jti] = expiresAt
+	return nil
+}
+
+func (f *fakeTokenStore) IsAccessTokenRevoked(_ context.Context, jti string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	expiresAt, ok := f.revoked[jti]
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+func (f *fakeTokenStore) ConsumeActionToken(_ context.Context, jti string, expiresAt time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.actions[jti]; ok {
+		return false, nil
+	}
+	f.actions[jti] = expiresAt
+	return true, nil
+}
+
+func (f *fakeTokenStore) PruneExpired(_ context.Context, before time.Time) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var deleted int64
+	for hash, t := range f.refresh {
+		if t.ExpiresAt.Before(before) {
+			delete(f.refresh, hash)
+			deleted++
+		}
+	}
+	for jti, expiresAt := range f.revoked {
+		if expiresAt.Before(before) {
+			delete(f.revoked, jti)
+			deleted++
+		}
+	}
+	for jti, expiresAt := range f.actions {
+		if expiresAt.Before(before) {
+			delete(f.actions, jti)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// fakeSessionStore 内存中的会话存储，不模拟空闲超时
+type fakeSessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]session.Session
+}
+
+func newFakeSessionStore() *fakeSessionStore {
+	return &fakeSessionStore{sessions: make(map[string]session.Session)}
+}
+
+func (f *fakeSessionStore) Create(_ context.Context, s *session.Session, _ time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions[s.ID] = *s
+	return nil
+}
+
+func (f *fakeSessionStore) Touch(_ context.Context, s *session.Session, _ time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.sessions[s.ID]; !ok {
+		return session.ErrSessionNotFound
+	}
+	f.sessions[s.ID] = *s
+	return nil
+}
+
+func (f *fakeSessionStore) Get(_ context.Context, id string) (*session.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.sessions[id]
+	if !ok {
+		return nil, session.ErrSessionNotFound
+	}
+	return &s, nil
+}
+
+func (f *fakeSessionStore) Delete(_ context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.sessions, id)
+	return nil
+}
+
+func (f *fakeSessionStore) ListByUser(_ context.Context, userID uint64) ([]*session.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var list []*session.Session
+	for _, s := range f.sessions {
+		if s.UserID == userID {
+			copied := s
+			list = append(list, &copied)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeSessionStore) DeleteByUser(_ context.Context, userID uint64, exceptID string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	count := 0
+	for id, s := range f.sessions {
+		if s.UserID == userID && id != exceptID {
+			delete(f.sessions, id)
+			count++
+		}
+	}
+	return count, nil
+}
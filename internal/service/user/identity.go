@@ -0,0 +1,228 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"goWebExample/internal/repository/user"
+)
+
+// 自动创建用户时生成用户名的限制，与注册接口的用户名长度校验一致
+const (
+	minUsernameLength     = 3
+	maxUsernameLength     = 32
+	usernameSuffixLength  = 6
+	maxUsernameCollisions = 5
+)
+
+// ExternalIdentity 第三方身份提供方返回的用户身份
+type ExternalIdentity struct {
+	Provider          string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// IdentityPolicy 第三方身份尚未关联用户时的处理策略
+type IdentityPolicy struct {
+	LinkByEmail bool // 按已验证邮箱关联到已有用户
+	AutoCreate  bool // 自动创建用户
+}
+
+// SetIdentityRepository 设置第三方身份仓库，未设置时不支持第三方登录
+func (s *UserService) SetIdentityRepository(repo user.RepositoryIdentity) {
+	s.identities = repo
+}
+
+// LoginWithIdentity 使用第三方身份登录，按策略关联或创建用户后签发本站 token；
+// 用户启用双重认证时同样需要完成第二步
+func (s *UserService) LoginWithIdentity(ctx context.Context, ext ExternalIdentity, policy IdentityPolicy, ip string) (*AuthResponse, error) {
+	if s.identities == nil {
+		return nil, ErrExternalLoginUnavailable
+	}
+	s.logger.Info("第三方登录", zap.String("provider", ext.Provider), zap.String("subject", ext.Subject), zap.String("ip", ip))
+
+	identity, u, err := s.resolveIdentity(ext, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.IsActive {
+		s.logger.Warn("用户已禁用", zap.String("username", u.Username))
+		return nil, ErrAccountDisabled
+	}
+	if isLocked(u, time.Now()) {
+		s.logger.Warn("用户被锁定", zap.String("username", u.Username), zap.Time("lockoutEnd", *u.LockoutEnd))
+		return nil, ErrAccountLocked
+	}
+	if s.registration.RequireEmailVerification && !u.EmailVerified {
+		s.logger.Warn("邮箱未验证", zap.String("username", u.Username))
+		return nil, ErrEmailNotVerified
+	}
+
+	if err := s.identities.TouchLogin(identity.ID, ext.Email); err != nil {
+		s.logger.Error("更新第三方身份登录信息失败", zap.Uint64("identityId", identity.ID), zap.Error(err))
+	}
+
+	if u.Is2FAEnabled {
+		return s.startMFAChallenge(u)
+	}
+	s.resetLoginFailures(u)
+
+	authResp, err := s.issueTokens(ctx, u, "")
+	if err != nil {
+		s.logger.Error("生成token失败", zap.String("username", u.Username), zap.Error(err))
+		return nil, err
+	}
+	if err := s.repo.UpdateLoginInfo(u.ID, ip); err != nil {
+		s.logger.Error("更新登录信息失败", zap.String("username", u.Username), zap.Error(err))
+	}
+	return authResp, nil
+}
+
+// resolveIdentity 查找第三方身份关联的用户，尚未关联时按策略关联已有用户或创建新用户
+func (s *UserService) resolveIdentity(ext ExternalIdentity, policy IdentityPolicy) (*user.UserIdentity, *user.Users, error) {
+	identity, err := s.identities.GetByProviderSubject(ext.Provider, ext.Subject)
+	if err == nil {
+		u, err := s.repo.GetByID(identity.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 关联的用户已被删除
+			return nil, nil, ErrAccountDisabled
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return identity, u, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	u, err := s.findOrCreateIdentityUser(ext, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	identity = &user.UserIdentity{
+		UserID:   u.ID,
+		Provider: ext.Provider,
+		Subject:  ext.Subject,
+		Email:    ext.Email,
+	}
+	if err := s.identities.Create(identity); err != nil {
+		if !errors.Is(err, user.ErrIdentityExists) {
+			return nil, nil, err
+		}
+		// 同一身份并发首次登录，以先写入的关联为准
+		return s.resolveIdentity(ext, IdentityPolicy{})
+	}
+	s.logger.Info("关联第三方身份", zap.String("provider", ext.Provider), zap.String("username", u.Username))
+	return identity, u, nil
+}
+
+// findOrCreateIdentityUser 为尚未关联的第三方身份查找或创建用户
+func (s *UserService) findOrCreateIdentityUser(ext ExternalIdentity, policy IdentityPolicy) (*user.Users, error) {
+	email := strings.ToLower(strings.TrimSpace(ext.Email))
+
+	// 只信任提供方已验证的邮箱，否则任何人都能用他人邮箱注册第三方账号接管本站用户
+	if policy.LinkByEmail && ext.EmailVerified && email != "" {
+		u, err := s.repo.GetUserByEmail(email)
+		if err == nil {
+			return u, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	if !policy.AutoCreate || email == "" {
+		return nil, ErrIdentityNotLinked
+	}
+	return s.createIdentityUser(ext, email)
+}
+
+// createIdentityUser 为第三方身份创建用户，密码为随机值，用户可通过找回密码设置本站密码
+func (s *UserService) createIdentityUser(ext ExternalIdentity, email string) (*user.Users, error) {
+	plain, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	base := identityUsername(ext, email)
+	for i := 0; i < maxUsernameCollisions; i++ {
+		username := base
+		if i > 0 {
+			suffix, err := randomHex(usernameSuffixLength / 2)
+			if err != nil {
+				return nil, err
+			}
+			username = truncate(base, maxUsernameLength-usernameSuffixLength-1) + "_" + suffix
+		}
+
+		u, err := s.newUser(username, plain, email, ext.Name)
+		if err != nil {
+			return nil, err
+		}
+		u.EmailVerified = ext.EmailVerified
+
+		err = s.createUser(u)
+		if err == nil {
+			s.logger.Info("第三方登录自动创建用户", zap.String("provider", ext.Provider), zap.String("username", u.Username))
+			return u, nil
+		}
+		if !errors.Is(err, ErrUsernameExists) {
+			if errors.Is(err, ErrEmailExists) {
+				// 邮箱已被本站用户使用但不允许按邮箱关联
+				return nil, ErrIdentityNotLinked
+			}
+			return nil, err
+		}
+	}
+	return nil, ErrUsernameExists
+}
+
+// identityUsername 根据第三方身份生成用户名候选值，只保留字母、数字、下划线、点与连字符
+func identityUsername(ext ExternalIdentity, email string) string {
+	candidate := ext.PreferredUsername
+	if candidate == "" {
+		candidate, _, _ = strings.Cut(email, "@")
+	}
+
+	var b strings.Builder
+	for _, r := range candidate {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			b.WriteRune(r)
+		}
+	}
+
+	username := truncate(b.String(), maxUsernameLength)
+	if len(username) < minUsernameLength {
+		username = truncate(ext.Provider, maxUsernameLength-usernameSuffixLength-1) + "_user"
+	}
+	return username
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,87 @@
+package user
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"goWebExample/internal/configs"
+	"goWebExample/internal/repository/user"
+)
+
+// SetLockoutConfig 设置登录失败锁定策略，未设置时不记录失败次数
+func (s *UserService) SetLockoutConfig(config configs.LockoutConfig) {
+	s.lockout = config
+}
+
+// isLocked 账户当前是否处于锁定期
+func isLocked(u *user.Users, now time.Time) bool {
+	return u.LockoutEnd != nil && now.Before(*u.LockoutEnd)
+}
+
+// lockoutDuration 根据连续失败次数计算锁定时长，未达到阈值时返回 0
+//
+// 达到阈值时锁定 Duration；启用指数递增后，锁定期满仍继续失败的，
+// 每多失败一次锁定时长翻倍，最长不超过 MaxDuration。
+func lockoutDuration(config *configs.LockoutConfig, attempts int) time.Duration {
+	maxAttempts := config.GetMaxAttempts()
+	if attempts < maxAttempts {
+		return 0
+	}
+
+	duration := config.GetDuration()
+	if !config.Exponential {
+		return duration
+	}
+
+	maxDuration := config.GetMaxDuration()
+	for i := maxAttempts; i < attempts && duration < maxDuration; i++ {
+		duration *= 2
+	}
+	if duration > maxDuration {
+		duration = maxDuration
+	}
+	return duration
+}
+
+// recordLoginFailure 记录一次登录失败，观察窗口内的失败次数达到阈值时锁定账户，返回是否因此被锁定
+func (s *UserService) recordLoginFailure(u *user.Users) bool {
+	if !s.lockout.Enable {
+		return false
+	}
+
+	attempts, err := s.repo.IncrementFailedLoginAttempts(u.ID, s.lockout.GetWindow())
+	if err != nil {
+		s.logger.Error("记录登录失败次数失败", zap.String("username", u.Username), zap.Error(err))
+		return false
+	}
+
+	duration := lockoutDuration(&s.lockout, attempts)
+	if duration == 0 {
+		return false
+	}
+
+	until := time.Now().Add(duration)
+	if err := s.repo.LockUntil(u.ID, until); err != nil {
+		s.logger.Error("锁定账户失败", zap.String("username", u.Username), zap.Error(err))
+		return false
+	}
+
+	s.logger.Warn("连续登录失败次数过多，账户已锁定",
+		zap.String("username", u.Username),
+		zap.Int("attempts", attempts),
+		zap.Duration("duration", duration),
+		zap.Time("until", until))
+	return true
+}
+
+// resetLoginFailures 登录成功后清零失败次数
+func (s *UserService) resetLoginFailures(u *user.Users) {
+	if (u.FailedLoginAttempts == nil || *u.FailedLoginAttempts == 0) && u.LockoutEnd == nil {
+		return
+	}
+
+	if err := s.repo.ResetLoginFailures(u.ID); err != nil {
+		s.logger.Error("重置登录失败次数失败", zap.String("username", u.Username), zap.Error(err))
+	}
+}
@@ -0,0 +1,90 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"goWebExample/internal/configs"
+	"goWebExample/internal/repository/user"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	fixed := configs.LockoutConfig{MaxAttempts: 3, Duration: time.Minute}
+	exponential := configs.LockoutConfig{MaxAttempts: 3, Duration: time.Minute, Exponential: true, MaxDuration: 10 * time.Minute}
+
+	tests := []struct {
+		name     string
+		config   configs.LockoutConfig
+		attempts int
+		want     time.Duration
+	}{
+		{"below threshold", fixed, 2, 0},
+		{"at threshold", fixed, 3, time.Minute},
+		{"fixed after threshold", fixed, 6, time.Minute},
+		{"exponential at threshold", exponential, 3, time.Minute},
+		{"exponential doubles", exponential, 4, 2 * time.Minute},
+		{"exponential doubles again", exponential, 5, 4 * time.Minute},
+		{"exponential capped", exponential, 8, 10 * time.Minute},
+		{"exponential far beyond cap", exponential, 1000, 10 * time.Minute},
+		{"defaults", configs.LockoutConfig{}, 5, 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lockoutDuration(&tt.config, tt.attempts); got != tt.want {
+				t.Errorf("lockoutDuration(%d) = %v, want %v", tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginLockedAccount(t *testing.T) {
+	s, repo := newTestService(t)
+	s.SetLockoutConfig(configs.LockoutConfig{Enable: true, MaxAttempts: 2, Duration: time.Hour})
+	repo.add(user.Users{Username: "alice", PasswordHash: mustHash(t, s, "correct"), IsActive: true})
+
+	// Failed attempts do not reveal the lockout, not even the one that triggers it
+	for i := 0; i < 3; i++ {
+		if _, err := s.Login(context.Background(), "alice", "wrong", ""); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: Login() error = %v, want ErrInvalidCredentials", i+1, err)
+		}
+	}
+
+	u, _ := repo.GetUserByUsername("alice")
+	if !isLocked(u, time.Now()) {
+		t.Fatal("account should be locked after too many failures")
+	}
+	if *u.FailedLoginAttempts != 2 {
+		t.Errorf("failed attempts = %d, want attempts during the lockout not counted", *u.FailedLoginAttempts)
+	}
+
+	// Only the correct password reveals that the account is locked
+	if _, err := s.Login(context.Background(), "alice", "correct", ""); !errors.Is(err, ErrAccountLocked) {
+		t.Errorf("Login() with correct password error = %v, want ErrAccountLocked", err)
+	}
+}
+
+func TestLoginFailuresOutsideWindowAreNotCounted(t *testing.T) {
+	s, repo := newTestService(t)
+	s.SetLockoutConfig(configs.LockoutConfig{Enable: true, MaxAttempts: 2, Duration: time.Hour, Window: time.Hour})
+	u := repo.add(user.Users{Username: "bob", PasswordHash: mustHash(t, s, "correct"), IsActive: true})
+
+	// A typo long ago does not add up with a new one
+	attempts, lastFailed := 1, time.Now().Add(-2*time.Hour)
+	u.FailedLoginAttempts, u.LastFailedLoginAt = &attempts, &lastFailed
+	if _, err := s.Login(context.Background(), "bob", "wrong", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login() error = %v, want ErrInvalidCredentials", err)
+	}
+	u, _ = repo.GetUserByUsername("bob")
+	if isLocked(u, time.Now()) || *u.FailedLoginAttempts != 1 {
+		t.Errorf("locked = %v, failed attempts = %d, want the counter restarted", isLocked(u, time.Now()), *u.FailedLoginAttempts)
+	}
+
+	// Failures within the window still lock the account
+	_, _ = s.Login(context.Background(), "bob", "wrong", "")
+	u, _ = repo.GetUserByUsername("bob")
+	if !isLocked(u, time.Now()) {
+		t.Error("account should be locked after too many failures within the window")
+	}
+}
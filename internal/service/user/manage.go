@@ -0,0 +1,263 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"goWebExample/internal/repository/user"
+)
+
+// CreateUserInput 管理员创建用户参数
+type CreateUserInput struct {
+	Username string
+	Password string
+	Email    string
+	Nickname string
+	IsActive *bool
+}
+
+// UpdateUserInput 管理员更新用户参数，nil 字段表示不修改
+type UpdateUserInput struct {
+	Nickname *string
+	Email    *string
+	IsActive *bool
+	Timezone *string
+	Locale   *string
+}
+
+// ListUsersInput 用户列表查询参数
+type ListUsersInput = user.ListQuery
+
+// CreateUser 创建用户，用户名或邮箱重复时返回冲突错误
+//
+// 管理员创建的用户不会收到验证邮件，邮箱视为已验证。
+func (s *UserService) CreateUser(in CreateUserInput) (*UserDTO, error) {
+	u, err := s.newUser(in.Username, in.Password, in.Email, in.Nickname)
+	if err != nil {
+		return nil, err
+	}
+	u.EmailVerified = true
+	if in.IsActive != nil {
+		u.IsActive = *in.IsActive
+	}
+
+	if err := s.createUser(u); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("用户已创建", zap.String("username", u.Username), zap.Uint64("userID", u.ID))
+	return toDTO(u), nil
+}
+
+// newUser 构造新用户模型并计算密码哈希
+func (s *UserService) newUser(username, plain, email, nickname string) (*user.Users, error) {
+	hash, err := s.hasher.Hash(plain)
+	if err != nil {
+		return nil, fmt.Errorf("计算密码哈希失败: %w", err)
+	}
+
+	return &user.Users{
+		UUID:         uuid.NewString(),
+		Username:     strings.TrimSpace(username),
+		Nickname:     nickname,
+		Email:        strings.ToLower(strings.TrimSpace(email)),
+		PasswordHash: hash,
+		IsActive:     true,
+		Gender:       "unknown",
+		Timezone:     "UTC",
+		Locale:       "en-US",
+	}, nil
+}
+
+// createUser 保存新用户
+func (s *UserService) createUser(u *user.Users) error {
+	if err := s.repo.Create(u); err != nil {
+		return translateRepoError(err)
+	}
+
+	// is_active 带有数据库默认值，false 会被 gorm 当作零值忽略，需单独更新
+	if !u.IsActive {
+		if err := s.repo.Update(u.ID, map[string]interface{}{"is_active": false}); err != nil {
+			return translateRepoError(err)
+		}
+	}
+	return nil
+}
+
+// UpdateUser 更新用户信息，修改邮箱后需重新验证
+func (s *UserService) UpdateUser(userID string, in UpdateUserInput) (*UserDTO, error) {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if in.Nickname != nil {
+		updates["nickname"] = *in.Nickname
+	}
+	if in.Email != nil {
+		updates["email"] = strings.ToLower(strings.TrimSpace(*in.Email))
+	}
+	if in.IsActive != nil {
+		updates["is_active"] = *in.IsActive
+	}
+	if in.Timezone != nil {
+		updates["timezone"] = *in.Timezone
+	}
+	if in.Locale != nil {
+		updates["locale"] = *in.Locale
+	}
+
+	current, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, translateRepoError(err)
+	}
+	if email, ok := updates["email"]; ok && email != current.Email {
+		updates["email_verified"] = false
+	}
+	// 禁用用户时轮换安全戳，已签发的 token 与会话随之失效
+	deactivated := in.IsActive != nil && !*in.IsActive && current.IsActive
+	if deactivated {
+		updates["security_stamp"] = uuid.NewString()
+	}
+
+	if len(updates) > 0 {
+		if err := s.repo.Update(id, updates); err != nil {
+			return nil, translateRepoError(err)
+		}
+	}
+	if deactivated {
+		s.stamps.invalidate(current.UUID)
+	}
+
+	updated, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, translateRepoError(err)
+	}
+	return toDTO(updated), nil
+}
+
+// SetSuperuser 授予或撤销超级管理员，operatorUUID 为操作者的 UUID，不允许修改自己的超级管理员状态
+func (s *UserService) SetSuperuser(operatorUUID, userID string, isSuperuser bool) (*UserDTO, error) {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, translateRepoError(err)
+	}
+	if target.UUID == operatorUUID {
+		return nil, ErrChangeOwnSuperuser
+	}
+
+	if target.IsSuperuser != isSuperuser {
+		updates := map[string]interface{}{"is_superuser": isSuperuser}
+		// 撤销超级管理员时轮换安全戳，已签发的 token 与会话中的权限快照随之失效
+		if !isSuperuser {
+			updates["security_stamp"] = uuid.NewString()
+		}
+		if err := s.repo.Update(id, updates); err != nil {
+			return nil, translateRepoError(err)
+		}
+		s.stamps.invalidate(target.UUID)
+		target.IsSuperuser = isSuperuser
+	}
+
+	s.logger.Info("超级管理员状态已修改",
+		zap.String("username", target.Username),
+		zap.Bool("isSuperuser", isSuperuser),
+		zap.String("operator", operatorUUID))
+	return toDTO(target), nil
+}
+
+// DeleteUser 软删除用户，已签发的 token 与会话随之失效
+func (s *UserService) DeleteUser(userID string) error {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	u, err := s.repo.GetByID(id)
+	if err != nil {
+		return translateRepoError(err)
+	}
+	// 轮换安全戳，恢复用户后删除前签发的 token 与会话也不会重新生效
+	if err := s.rotateSecurityStamp(u); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(uint(id)); err != nil {
+		return translateRepoError(err)
+	}
+
+	s.logger.Info("用户已删除", zap.Uint64("userID", id))
+	return nil
+}
+
+// RestoreUser 恢复已软删除的用户
+func (s *UserService) RestoreUser(userID string) (*UserDTO, error) {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(id); err != nil {
+		return nil, translateRepoError(err)
+	}
+
+	restored, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, translateRepoError(err)
+	}
+
+	s.logger.Info("用户已恢复", zap.Uint64("userID", id))
+	return toDTO(restored), nil
+}
+
+// ListUsers 分页查询用户，返回当前页数据与总数
+func (s *UserService) ListUsers(query ListUsersInput) ([]*UserDTO, int64, error) {
+	query.Normalize()
+
+	users, total, err := s.repo.List(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list := make([]*UserDTO, 0, len(users))
+	for i := range users {
+		list = append(list, toDTO(&users[i]))
+	}
+	return list, total, nil
+}
+
+// parseUserID 解析路径中的用户ID
+func parseUserID(userID string) (uint64, error) {
+	id, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil || id == 0 {
+		return 0, ErrInvalidUserID
+	}
+	return id, nil
+}
+
+// translateRepoError 将仓储层错误转换为服务层错误
+func translateRepoError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, user.ErrUserNotFound):
+		return ErrUserNotFound
+	case errors.Is(err, user.ErrUsernameExists):
+		return ErrUsernameExists
+	case errors.Is(err, user.ErrEmailExists):
+		return ErrEmailExists
+	case errors.Is(err, user.ErrDuplicateUser):
+		return ErrUserConflict
+	default:
+		return err
+	}
+}
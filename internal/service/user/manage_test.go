@@ -0,0 +1,81 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"goWebExample/internal/configs"
+	"goWebExample/internal/repository/user"
+)
+
+func TestSetSuperuser(t *testing.T) {
+	s, repo := newTestService(t)
+	operator := repo.add(user.Users{UUID: "uuid-admin", Username: "admin", IsActive: true, IsSuperuser: true})
+	target := repo.add(user.Users{UUID: "uuid-bob", Username: "bob", IsActive: true})
+
+	tests := []struct {
+		name    string
+		userID  string
+		grant   bool
+		wantErr error
+		want    bool
+	}{
+		{"grant", strconv.FormatUint(target.ID, 10), true, nil, true},
+		{"grant again", strconv.FormatUint(target.ID, 10), true, nil, true},
+		{"revoke", strconv.FormatUint(target.ID, 10), false, nil, false},
+		{"own flag", strconv.FormatUint(operator.ID, 10), false, ErrChangeOwnSuperuser, true},
+		{"invalid id", "abc", true, ErrInvalidUserID, false},
+		{"unknown user", "999", true, ErrUserNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.SetSuperuser(operator.UUID, tt.userID, tt.grant)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetSuperuser() error = %v, want %v", err, tt.wantErr)
+			}
+			if id, parseErr := strconv.ParseUint(tt.userID, 10, 64); parseErr == nil && tt.wantErr != ErrUserNotFound {
+				if got := repo.get(id).IsSuperuser; got != tt.want {
+					t.Errorf("IsSuperuser = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateAndUpdateUserCannotGrantSuperuser(t *testing.T) {
+	s, repo := newTestService(t)
+
+	created, err := s.CreateUser(CreateUserInput{Username: "carol", Password: "secret123", Email: "carol@example.com"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if created.IsSuperuser {
+		t.Error("created user should not be a superuser")
+	}
+
+	nickname := "Carol"
+	if _, err := s.UpdateUser(strconv.FormatUint(created.ID, 10), UpdateUserInput{Nickname: &nickname}); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if repo.get(created.ID).IsSuperuser {
+		t.Error("updated user should not be a superuser")
+	}
+}
+
+func TestCreatedUserCanLoginWithEmailVerificationRequired(t *testing.T) {
+	s, _ := newTestService(t)
+	s.SetRegistrationConfig(configs.RegistrationConfig{Enable: true, RequireEmailVerification: true})
+
+	created, err := s.CreateUser(CreateUserInput{Username: "dave", Password: "secret123", Email: "dave@example.com"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if !created.EmailVerified {
+		t.Error("users created by an administrator should be verified")
+	}
+	if _, err := s.Login(context.Background(), "dave", "secret123", ""); err != nil {
+		t.Errorf("Login() error = %v, want the created user to log in", err)
+	}
+}
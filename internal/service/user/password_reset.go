@@ -0,0 +1,191 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"goWebExample/internal/configs"
+	jwtpkg "goWebExample/internal/pkg/jwt"
+	"goWebExample/internal/pkg/mail"
+	"goWebExample/internal/repository/user"
+)
+
+// SetPasswordResetConfig 设置找回密码配置
+func (s *UserService) SetPasswordResetConfig(config configs.PasswordResetConfig) {
+	s.reset = config
+}
+
+// ForgotPassword 向邮箱发送密码重置链接
+//
+// 邮箱不存在或账户已禁用时同样返回成功，避免通过该接口探测已注册邮箱。
+func (s *UserService) ForgotPassword(ctx context.Context, email string) error {
+	u, err := s.repo.GetUserByEmail(strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !u.IsActive {
+		return nil
+	}
+	if s.mailer == nil {
+		return errors.New("邮件发送器未初始化")
+	}
+
+	// 重置 token 绑定当前安全戳，密码一旦修改，所有未使用的重置链接随之失效
+	ttl := s.reset.GetTokenTTL()
+	signed, _, err := s.jwtMgr.IssueActionToken(jwtpkg.PurposeResetPassword, strconv.FormatUint(u.ID, 10), securityStamp(u), ttl)
+	if err != nil {
+		return fmt.Errorf("生成重置token失败: %w", err)
+	}
+
+	link, err := actionLink(s.reset.ResetURL, signed)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("发送密码重置邮件", zap.String("username", u.Username))
+	return s.mailer.Send(ctx, &mail.Message{
+		To:      []string{u.Email},
+		Subject: "重置密码",
+		TextBody: fmt.Sprintf("%s，您好：\n\n我们收到了重置您账户密码的请求，请在 %s 内打开以下链接设置新密码：\n\n%s\n\n如果这不是您本人的操作，请忽略此邮件，您的密码不会被修改。\n",
+			u.Username, formatTTL(ttl), link),
+	})
+}
+
+// ResetPassword 使用重置 token 设置新密码，成功后该用户此前签发的所有 token 失效
+func (s *UserService) ResetPassword(ctx context.Context, tokenString, newPassword string) error {
+	claims, err := s.jwtMgr.ParseActionToken(tokenString, jwtpkg.PurposeResetPassword)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	u, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if claims.Binding != securityStamp(u) {
+		return ErrInvalidResetToken
+	}
+
+	if s.tokens == nil {
+		return errors.New("token存储未初始化")
+	}
+	consumed, err := s.tokens.ConsumeActionToken(ctx, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return fmt.Errorf("记录重置token失败: %w", err)
+	}
+	if !consumed {
+		return ErrResetTokenUsed
+	}
+
+	if err := s.setPassword(u, newPassword); err != nil {
+		return err
+	}
+
+	s.logger.Info("密码已重置", zap.String("username", u.Username))
+	return nil
+}
+
+// ChangePassword 已登录用户修改密码，成功后此前签发的所有 token 失效，并返回新的 token 对
+func (s *UserService) ChangePassword(ctx context.Context, userUUID, oldPassword, newPassword string) (*AuthResponse, error) {
+	u, err := s.repo.GetByUUID(userUUID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	matched, err := s.hasher.Verify(oldPassword, u.PasswordHash)
+	if err != nil {
+		s.logger.Error("校验密码失败", zap.String("username", u.Username), zap.Error(err))
+	}
+	if !matched {
+		return nil, ErrIncorrectPassword
+	}
+
+	if err := s.setPassword(u, newPassword); err != nil {
+		return nil, err
+	}
+	s.logger.Info("密码已修改", zap.String("username", u.Username))
+
+	return s.issueTokens(ctx, u, "")
+}
+
+// IsSecurityStampValid 实现 jwt.StampValidator，userID 为 token 中的用户 UUID，
+// 用户已禁用或已删除时同样视为无效
+//
+// 每个认证请求都会调用，安全戳按用户缓存 stampCacheTTL，本实例轮换安全戳时缓存立即失效。
+func (s *UserService) IsSecurityStampValid(_ context.Context, userID, stamp string) (bool, error) {
+	now := time.Now()
+	if entry, ok := s.stamps.get(userID, now); ok {
+		return entry.active && entry.stamp == stamp, nil
+	}
+
+	u, err := s.repo.GetByUUID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	active := u.IsActive && !u.DeletedAt.Valid
+	s.stamps.set(userID, securityStamp(u), active, now)
+	return active && securityStamp(u) == stamp, nil
+}
+
+// setPassword 保存新密码并轮换安全戳
+func (s *UserService) setPassword(u *user.Users, plain string) error {
+	hash, err := s.hasher.Hash(plain)
+	if err != nil {
+		return fmt.Errorf("计算密码哈希失败: %w", err)
+	}
+
+	stamp := uuid.NewString()
+	if err := s.repo.UpdatePassword(u.ID, hash, stamp); err != nil {
+		return translateRepoError(err)
+	}
+
+	s.stamps.invalidate(u.UUID)
+
+	u.PasswordHash = hash
+	u.SecurityStamp = &stamp
+	u.LockoutEnd = nil
+	return nil
+}
+
+// rotateSecurityStamp 轮换安全戳，使此前签发的全部 token 与会话失效
+func (s *UserService) rotateSecurityStamp(u *user.Users) error {
+	stamp := uuid.NewString()
+	if err := s.repo.Update(u.ID, map[string]interface{}{"security_stamp": stamp}); err != nil {
+		return translateRepoError(err)
+	}
+	s.stamps.invalidate(u.UUID)
+	u.SecurityStamp = &stamp
+	return nil
+}
+
+// securityStamp 获取用户当前安全戳，未设置时为空字符串
+func securityStamp(u *user.Users) string {
+	if u.SecurityStamp == nil {
+		return ""
+	}
+	return *u.SecurityStamp
+}
@@ -0,0 +1,38 @@
+package user
+
+import (
+	"go.uber.org/zap"
+
+	"goWebExample/internal/pkg/rbac"
+	rbacRepo "goWebExample/internal/repository/rbac"
+	"goWebExample/internal/repository/user"
+)
+
+// SetRBACRepository 设置角色权限仓库，未设置时除超级管理员外不授予任何权限
+func (s *UserService) SetRBACRepository(repo rbacRepo.RepositoryRBAC) {
+	s.rbac = repo
+}
+
+// resolvePermissions 解析用户的角色与权限，超级管理员拥有全部权限
+//
+// 查询失败时不授予任何权限（仍允许登录），避免因权限表异常导致越权。
+func (s *UserService) resolvePermissions(u *user.Users) ([]string, []string) {
+	if u.IsSuperuser {
+		return []string{"admin"}, []string{rbac.Wildcard}
+	}
+	if s.rbac == nil {
+		return nil, nil
+	}
+
+	roles, err := s.rbac.GetUserRoles(u.ID)
+	if err != nil {
+		s.logger.Error("获取用户角色失败", zap.String("username", u.Username), zap.Error(err))
+		return nil, nil
+	}
+	permissions, err := s.rbac.GetUserPermissions(u.ID)
+	if err != nil {
+		s.logger.Error("获取用户权限失败", zap.String("username", u.Username), zap.Error(err))
+		return roles, nil
+	}
+	return roles, permissions
+}
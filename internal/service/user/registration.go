@@ -0,0 +1,179 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"goWebExample/internal/configs"
+	jwtpkg "goWebExample/internal/pkg/jwt"
+	"goWebExample/internal/pkg/mail"
+	"goWebExample/internal/repository/user"
+)
+
+// RegisterInput 自助注册参数
+type RegisterInput struct {
+	Username string
+	Password string
+	Email    string
+	IP       string
+}
+
+// SetRegistrationConfig 设置自助注册配置
+func (s *UserService) SetRegistrationConfig(config configs.RegistrationConfig) {
+	s.registration = config
+}
+
+// SetMailer 设置邮件发送器，未设置时不发送验证邮件
+func (s *UserService) SetMailer(mailer mail.Sender) {
+	s.mailer = mailer
+}
+
+// Register 自助注册，创建用户后发送邮箱验证邮件
+//
+// 邮件发送失败不影响注册结果，用户可通过重新发送接口再次获取验证邮件。
+func (s *UserService) Register(ctx context.Context, in RegisterInput) (*UserDTO, error) {
+	if !s.registration.Enable {
+		return nil, ErrRegistrationDisabled
+	}
+
+	u, err := s.newUser(in.Username, in.Password, in.Email, "")
+	if err != nil {
+		return nil, err
+	}
+	if in.IP != "" {
+		u.RegistrationIP = &in.IP
+	}
+
+	if err := s.createUser(u); err != nil {
+		return nil, err
+	}
+	s.logger.Info("用户注册成功", zap.String("username", u.Username), zap.String("ip", in.IP))
+
+	if err := s.sendVerificationEmail(ctx, u); err != nil {
+		s.logger.Error("发送验证邮件失败", zap.String("username", u.Username), zap.Error(err))
+	}
+	return toDTO(u), nil
+}
+
+// ResendVerificationEmail 重新发送验证邮件
+//
+// 邮箱不存在或已验证时同样返回成功，避免通过该接口探测已注册邮箱。
+func (s *UserService) ResendVerificationEmail(ctx context.Context, email string) error {
+	if !s.registration.Enable {
+		return ErrRegistrationDisabled
+	}
+
+	u, err := s.repo.GetUserByEmail(strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if u.EmailVerified {
+		return nil
+	}
+	return s.sendVerificationEmail(ctx, u)
+}
+
+// VerifyEmail 校验邮箱验证 token 并将用户标记为已验证，每个 token 只能使用一次
+func (s *UserService) VerifyEmail(ctx context.Context, tokenString string) error {
+	claims, err := s.jwtMgr.ParseActionToken(tokenString, jwtpkg.PurposeVerifyEmail)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+	u, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+	// 签发后邮箱已修改，旧链接不能验证新邮箱
+	if claims.Binding != u.Email {
+		return ErrInvalidVerificationToken
+	}
+
+	if s.tokens == nil {
+		return errors.New("token存储未初始化")
+	}
+	consumed, err := s.tokens.ConsumeActionToken(ctx, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return fmt.Errorf("记录验证token失败: %w", err)
+	}
+	if !consumed {
+		return ErrVerificationTokenUsed
+	}
+
+	if u.EmailVerified {
+		return nil
+	}
+	if err := s.repo.Update(u.ID, map[string]interface{}{"email_verified": true}); err != nil {
+		return translateRepoError(err)
+	}
+
+	s.logger.Info("邮箱验证成功", zap.String("username", u.Username))
+	return nil
+}
+
+// sendVerificationEmail 签发邮箱验证 token 并发送验证邮件
+func (s *UserService) sendVerificationEmail(ctx context.Context, u *user.Users) error {
+	if s.mailer == nil {
+		return errors.New("邮件发送器未初始化")
+	}
+
+	ttl := s.registration.GetVerificationTTL()
+	signed, _, err := s.jwtMgr.IssueActionToken(jwtpkg.PurposeVerifyEmail, strconv.FormatUint(u.ID, 10), u.Email, ttl)
+	if err != nil {
+		return fmt.Errorf("生成验证token失败: %w", err)
+	}
+
+	link, err := actionLink(s.registration.VerifyURL, signed)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, &mail.Message{
+		To:      []string{u.Email},
+		Subject: "请验证您的邮箱",
+		TextBody: fmt.Sprintf("%s，您好：\n\n请在 %s 内打开以下链接完成邮箱验证：\n\n%s\n\n如果这不是您本人的操作，请忽略此邮件。\n",
+			u.Username, formatTTL(ttl), link),
+	})
+}
+
+// actionLink 将 token 作为查询参数附加到链接地址上，未配置地址时直接返回 token
+func actionLink(base, token string) (string, error) {
+	if base == "" {
+		return token, nil
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("链接地址配置无效: %w", err)
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// formatTTL 将有效期格式化为便于阅读的文字
+func formatTTL(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		return fmt.Sprintf("%d 小时", int(ttl.Hours()))
+	}
+	return fmt.Sprintf("%d 分钟", int(ttl.Minutes()))
+}
@@ -0,0 +1,293 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"goWebExample/internal/configs"
+	jwtpkg "goWebExample/internal/pkg/jwt"
+	"goWebExample/internal/repository/session"
+	"goWebExample/internal/repository/user"
+)
+
+// sessionTokenBytes 会话 Cookie 随机字节数
+const sessionTokenBytes = 32
+
+// sessionTouchInterval 会话最后访问时间的更新间隔，避免每个请求都写 Redis
+const sessionTouchInterval = time.Minute
+
+// sessionRequestKey 上下文中标记会话登录的键
+type sessionRequestKey struct{}
+
+// sessionRequest 会话登录的客户端信息
+type sessionRequest struct {
+	ip        string
+	userAgent string
+}
+
+// SessionDTO 会话信息，ID 可用于吊销指定会话，不能用于认证
+type SessionDTO struct {
+	ID         string `json:"id"`
+	IP         string `json:"ip,omitempty"`
+	UserAgent  string `json:"userAgent,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+	LastSeenAt string `json:"lastSeenAt"`
+	ExpiresAt  string `json:"expiresAt"`
+	Current    bool   `json:"current"`
+}
+
+// WithSession 标记本次登录使用会话模式：登录成功后创建服务端会话，
+// 通过 AuthResponse.SessionToken 返回会话 Cookie 值，不再签发 access token 与 refresh token
+func WithSession(ctx context.Context, ip, userAgent string) context.Context {
+	return context.WithValue(ctx, sessionRequestKey{}, sessionRequest{ip: ip, userAgent: userAgent})
+}
+
+// SetSessionStore 设置会话存储与配置，未设置时不支持会话模式
+func (s *UserService) SetSessionStore(store session.Store, config configs.SessionConfig) {
+	s.sessions = store
+	s.sessionCfg = config
+}
+
+// SessionEnabled 是否启用了会话模式
+func (s *UserService) SessionEnabled() bool {
+	return s.sessions != nil
+}
+
+// SessionConfig 获取会话配置
+func (s *UserService) SessionConfig() configs.SessionConfig {
+	return s.sessionCfg
+}
+
+// SessionCookieName 实现 jwt.SessionResolver
+func (s *UserService) SessionCookieName() string {
+	return s.sessionCfg.GetCookieName()
+}
+
+// ResolveSession 实现 jwt.SessionResolver，会话有效时按空闲超时续期
+func (s *UserService) ResolveSession(ctx context.Context, cookieValue string) (*jwtpkg.CustomClaims, error) {
+	if s.sessions == nil || cookieValue == "" {
+		return nil, jwtpkg.ErrInvalidSession
+	}
+
+	sess, err := s.sessions.Get(ctx, hashRefreshToken(cookieValue))
+	if errors.Is(err, session.ErrSessionNotFound) {
+		return nil, jwtpkg.ErrInvalidSession
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !now.Before(sess.ExpiresAt) {
+		return nil, jwtpkg.ErrInvalidSession
+	}
+	if now.Sub(sess.LastSeenAt) >= sessionTouchInterval {
+		// 续期时刷新会话中的用户快照，角色与权限变更最迟在一个续期间隔后生效
+		if err := s.refreshSession(ctx, sess); err != nil {
+			return nil, err
+		}
+		sess.LastSeenAt = now
+		if err := s.sessions.Touch(ctx, sess, s.sessionCfg.GetIdleTimeout()); err != nil {
+			s.logger.Warn("会话续期失败", zap.String("sessionID", sess.ID), zap.Error(err))
+		}
+	}
+
+	return &jwtpkg.CustomClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   sess.UserUUID,
+			Issuer:    "session",
+			IssuedAt:  jwt.NewNumericDate(sess.CreatedAt),
+			ExpiresAt: jwt.NewNumericDate(sess.ExpiresAt),
+		},
+		UserID:      sess.UserUUID,
+		Username:    sess.Username,
+		Nickname:    sess.Nickname,
+		IsAdmin:     sess.IsAdmin,
+		SessionID:   sess.ID,
+		Roles:       sess.Roles,
+		Permissions: sess.Permissions,
+		Stamp:       sess.Stamp,
+	}, nil
+}
+
+// refreshSession 重新加载会话所属用户并更新会话快照，
+// 用户已禁用、已删除或安全戳已变化时删除会话并返回 jwt.ErrInvalidSession
+func (s *UserService) refreshSession(ctx context.Context, sess *session.Session) error {
+	u, err := s.repo.GetByID(sess.UserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err != nil || !u.IsActive || u.DeletedAt.Valid || securityStamp(u) != sess.Stamp {
+		if err := s.sessions.Delete(ctx, sess.ID); err != nil {
+			s.logger.Warn("删除失效会话失败", zap.String("sessionID", sess.ID), zap.Error(err))
+		}
+		return jwtpkg.ErrInvalidSession
+	}
+
+	sess.Roles, sess.Permissions = s.resolvePermissions(u)
+	sess.Username = u.Username
+	sess.Nickname = u.Nickname
+	sess.IsAdmin = u.IsSuperuser
+	return nil
+}
+
+// sessionRequested 判断本次登录是否要求会话模式
+func (s *UserService) sessionRequested(ctx context.Context) (sessionRequest, bool) {
+	req, ok := ctx.Value(sessionRequestKey{}).(sessionRequest)
+	return req, ok && s.sessions != nil
+}
+
+// startSession 创建服务端会话，会话中保存用户信息快照
+func (s *UserService) startSession(ctx context.Context, u *user.Users, req sessionRequest) (*AuthResponse, error) {
+	buf := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("生成会话ID失败: %w", err)
+	}
+	cookieValue := base64.RawURLEncoding.EncodeToString(buf)
+
+	now := time.Now()
+	absolute := s.sessionCfg.GetAbsoluteTimeout()
+	roles, permissions := s.resolvePermissions(u)
+	sess := &session.Session{
+		ID:          hashRefreshToken(cookieValue),
+		UserID:      u.ID,
+		UserUUID:    u.UUID,
+		Username:    u.Username,
+		Nickname:    u.Nickname,
+		IsAdmin:     u.IsSuperuser,
+		Roles:       roles,
+		Permissions: permissions,
+		Stamp:       securityStamp(u),
+		IP:          req.ip,
+		UserAgent:   req.userAgent,
+		CreatedAt:   now,
+		LastSeenAt:  now,
+		ExpiresAt:   now.Add(absolute),
+	}
+	if err := s.sessions.Create(ctx, sess, s.sessionCfg.GetIdleTimeout()); err != nil {
+		return nil, fmt.Errorf("保存会话失败: %w", err)
+	}
+	s.logger.Info("创建会话", zap.String("username", u.Username), zap.String("ip", req.ip))
+
+	return &AuthResponse{
+		User:             toDTO(u),
+		SessionToken:     cookieValue,
+		SessionExpiresIn: int64(absolute.Seconds()),
+	}, nil
+}
+
+// ListSessions 列出当前用户的全部有效会话
+func (s *UserService) ListSessions(ctx context.Context, claims *jwtpkg.CustomClaims) ([]*SessionDTO, error) {
+	if s.sessions == nil {
+		return []*SessionDTO{}, nil
+	}
+
+	u, err := s.getByUUID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.sessions.ListByUser(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]*SessionDTO, 0, len(sessions))
+	stamp := securityStamp(u)
+	for _, sess := range sessions {
+		// 修改密码等操作轮换安全戳后，旧会话已失效但尚未过期
+		if sess.Stamp != stamp {
+			continue
+		}
+		dtos = append(dtos, &SessionDTO{
+			ID:         sess.ID,
+			IP:         sess.IP,
+			UserAgent:  sess.UserAgent,
+			CreatedAt:  sess.CreatedAt.Format(TimeFormat),
+			LastSeenAt: sess.LastSeenAt.Format(TimeFormat),
+			ExpiresAt:  sess.ExpiresAt.Format(TimeFormat),
+			Current:    sess.ID == claims.SessionID,
+		})
+	}
+	return dtos, nil
+}
+
+// RevokeSession 吊销当前用户的指定会话
+func (s *UserService) RevokeSession(ctx context.Context, claims *jwtpkg.CustomClaims, sessionID string) error {
+	if s.sessions == nil {
+		return ErrSessionNotFound
+	}
+
+	sess, err := s.sessions.Get(ctx, sessionID)
+	if errors.Is(err, session.ErrSessionNotFound) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	// 不暴露其他用户的会话是否存在
+	if sess.UserUUID != claims.UserID {
+		return ErrSessionNotFound
+	}
+
+	if err := s.sessions.Delete(ctx, sessionID); err != nil {
+		return err
+	}
+	s.logger.Info("吊销会话", zap.String("username", claims.Username), zap.String("sessionID", sessionID))
+	return nil
+}
+
+// LogoutOtherDevices 退出其他设备：轮换安全戳使此前签发的全部 token 与会话失效，并保留当前设备的登录状态
+//
+// 当前设备使用会话时更新会话中的安全戳并返回 nil；使用 Bearer token 时为当前设备重新签发 token。
+func (s *UserService) LogoutOtherDevices(ctx context.Context, claims *jwtpkg.CustomClaims, viaSession bool) (*AuthResponse, error) {
+	u, err := s.getByUUID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.rotateSecurityStamp(u); err != nil {
+		return nil, err
+	}
+	stamp := securityStamp(u)
+
+	keep := ""
+	if viaSession {
+		keep = claims.SessionID
+	}
+	if s.sessions != nil {
+		count, err := s.sessions.DeleteByUser(ctx, u.ID, keep)
+		if err != nil {
+			s.logger.Error("删除其他会话失败", zap.String("username", u.Username), zap.Error(err))
+		}
+		s.logger.Info("退出其他设备", zap.String("username", u.Username), zap.Int("sessions", count))
+	}
+
+	if viaSession {
+		sess, err := s.sessions.Get(ctx, claims.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("获取当前会话失败: %w", err)
+		}
+		sess.Stamp = stamp
+		sess.LastSeenAt = time.Now()
+		if err := s.sessions.Touch(ctx, sess, s.sessionCfg.GetIdleTimeout()); err != nil {
+			return nil, fmt.Errorf("更新当前会话失败: %w", err)
+		}
+		return nil, nil
+	}
+
+	// 旧 token 家族随安全戳一并失效，为当前设备开启新的家族
+	if s.tokens != nil && claims.SessionID != "" {
+		if err := s.tokens.RevokeFamily(ctx, claims.SessionID, s.jwtMgr.RefreshDuration()); err != nil {
+			s.logger.Error("吊销token家族失败", zap.String("familyID", claims.SessionID), zap.Error(err))
+		}
+	}
+	return s.issueTokens(ctx, u, "")
+}
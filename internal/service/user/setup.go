@@ -0,0 +1,179 @@
+package user
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"goWebExample/internal/configs"
+	"goWebExample/internal/infra/cache"
+	"goWebExample/internal/infra/di/container"
+	"goWebExample/internal/pkg/encryption"
+	"goWebExample/internal/repository/rbac"
+	"goWebExample/internal/repository/session"
+	"goWebExample/internal/repository/token"
+	"goWebExample/internal/repository/user"
+	"goWebExample/internal/service"
+	"goWebExample/internal/service/scheduler"
+	"goWebExample/pkg/envelope"
+)
+
+// 过期 token 清理任务
+const (
+	tokenPruneTaskID   = "user-token-prune"
+	tokenPruneJobType  = "user.token_prune"
+	tokenPruneSchedule = "1h"
+	tokenPruneTimeout  = 5 * time.Minute
+)
+
+func init() {
+	// 注册过期 token 清理任务处理函数，调度服务重启后据此恢复持久化的任务
+	scheduler.RegisterJob(tokenPruneJobType, pruneTokensJob)
+}
+
+// NewUserServiceFromContainer 使用服务容器中的依赖创建用户服务，依赖缺失时返回 nil
+func NewUserServiceFromContainer(logger *zap.Logger, c *container.ServiceContainer) *UserService {
+	if c == nil || c.DBConnector == nil {
+		logger.Error("无法初始化用户服务：数据库连接器未初始化")
+		return nil
+	}
+
+	jwtManager := c.GetJWTManager()
+	if jwtManager == nil {
+		logger.Error("无法初始化用户服务：JWT管理器未初始化")
+		return nil
+	}
+
+	passwordHasher := c.GetPasswordHasher()
+	if passwordHasher == nil {
+		logger.Error("无法初始化用户服务：密码哈希器未初始化")
+		return nil
+	}
+
+	userSvc := NewUserService(user.NewUserRepository(c.DBConnector), logger, jwtManager, passwordHasher)
+	if config := c.GetConfig(); config != nil {
+		userSvc.SetLockoutConfig(config.User.Lockout)
+		userSvc.SetRegistrationConfig(config.User.Registration)
+		userSvc.SetPasswordResetConfig(config.User.PasswordReset)
+		setupTwoFactor(logger, userSvc, config.User.TwoFactor, config.Encryption)
+	}
+
+	userSvc.SetRBACRepository(rbac.NewRBACRepository(c.DBConnector))
+	userSvc.SetIdentityRepository(user.NewIdentityRepository(c.DBConnector))
+
+	// refresh token 与吊销列表存储：启用 Redis 时使用 Redis，否则使用 MySQL
+	tokenStore := newTokenStore(logger, c)
+	userSvc.SetTokenStore(tokenStore)
+	setupTokenPrune(logger)
+	jwtManager.SetDenylist(tokenStore)
+	jwtManager.SetStampValidator(userSvc)
+	if config := c.GetConfig(); config != nil && config.User.Session.Enable {
+		setupSession(logger, c, userSvc, config.User.Session)
+	}
+
+	if mailer := c.GetMailer(); mailer != nil {
+		userSvc.SetMailer(mailer)
+	}
+
+	return userSvc
+}
+
+// newTokenStore 创建 token 存储
+func newTokenStore(logger *zap.Logger, c *container.ServiceContainer) token.Store {
+	store, backend := token.NewStoreFromFactory(c.GetFactory(), c.DBConnector)
+	logger.Info("token存储初始化完成", zap.String("backend", backend))
+	return store
+}
+
+// setupTokenPrune 通过调度服务定时清理过期 token，MySQL 存储的过期记录不会自动删除
+func setupTokenPrune(logger *zap.Logger) {
+	schedulerSvc, ok := service.GetRegistry().Get(scheduler.ServiceName).(scheduler.SchedulerService)
+	if !ok || schedulerSvc == nil {
+		logger.Warn("调度服务未初始化，过期token不会被清理")
+		return
+	}
+	// 用户服务同时为 HTTP 与 gRPC 创建，同名任务已存在时沿用已有任务
+	_, err := schedulerSvc.AddJob(tokenPruneTaskID, "清理过期的refresh token、吊销记录与已使用的操作token",
+		tokenPruneSchedule, tokenPruneJobType, nil)
+	if err != nil && !errors.Is(err, scheduler.ErrTaskAlreadyExists) {
+		logger.Error("注册过期token清理任务失败", zap.Error(err))
+	}
+}
+
+// pruneTokensJob 清理过期 token，用户服务未初始化时跳过
+func pruneTokensJob(ctx context.Context, _ struct{}) error {
+	userSvc, ok := service.GetRegistry().Get(ServiceName).(*UserService)
+	if !ok || userSvc == nil {
+		return errors.New("用户服务未初始化")
+	}
+	ctx, cancel := context.WithTimeout(ctx, tokenPruneTimeout)
+	defer cancel()
+
+	deleted, err := userSvc.PruneExpiredTokens(ctx)
+	if err != nil {
+		return err
+	}
+	scheduler.RecordOutput(ctx, fmt.Sprintf("deleted %d expired token records", deleted))
+	return nil
+}
+
+// setupSession 初始化服务端会话，会话只保存在 Redis 中，未启用 Redis 时不支持会话模式
+func setupSession(logger *zap.Logger, c *container.ServiceContainer, userSvc *UserService, config configs.SessionConfig) {
+	var redisConnector *cache.RedisConnector
+	if f := c.GetFactory(); f != nil {
+		redisConnector, _ = f.GetConnector("redis").(*cache.RedisConnector)
+	}
+	if redisConnector == nil {
+		logger.Warn("未启用Redis，会话登录不可用")
+		return
+	}
+
+	userSvc.SetSessionStore(session.NewRedisStore(redisConnector), config)
+	c.GetJWTManager().SetSessionResolver(userSvc)
+	logger.Info("会话登录已启用", zap.String("cookie", config.GetCookieName()))
+}
+
+// setupTwoFactor 初始化双重认证：配置了加密主密钥时使用信封加密，并兼容 encryptionKey 加密的旧密文；
+// 只配置了 encryptionKey 时只能解密旧密文，两者均未配置时不允许用户启用双重认证
+func setupTwoFactor(logger *zap.Logger, userSvc *UserService, config configs.TwoFactorConfig, encryptionConfig configs.EncryptionConfig) {
+	var legacy *envelope.LegacyKey
+	if config.EncryptionKey != "" {
+		var err error
+		if legacy, err = envelope.ParseLegacyKey(config.EncryptionKey); err != nil {
+			logger.Error("双重认证加密密钥无效", zap.Error(err))
+		}
+	}
+
+	if config.RecoveryCodeKey != "" {
+		key, err := base64.StdEncoding.DecodeString(config.RecoveryCodeKey)
+		if err == nil {
+			err = userSvc.SetRecoveryCodeKey(key)
+		}
+		if err != nil {
+			logger.Error("恢复码摘要密钥无效，恢复码将使用密码哈希算法保存", zap.Error(err))
+		}
+	} else {
+		logger.Warn("未配置恢复码摘要密钥，恢复码将使用密码哈希算法保存")
+	}
+
+	keyring, err := encryption.NewKeyring(encryptionConfig)
+	if err != nil {
+		logger.Error("加密主密钥无效，双重认证不可用", zap.Error(err))
+		userSvc.SetTwoFactor(config, nil)
+		return
+	}
+	switch {
+	case keyring != nil:
+		userSvc.SetTwoFactor(config, envelope.WithLegacy(keyring, legacy))
+	case legacy != nil:
+		logger.Warn("未配置加密主密钥，已启用的双重认证可继续使用，新用户无法启用双重认证")
+		userSvc.SetTwoFactor(config, envelope.WithLegacy(nil, legacy))
+	default:
+		logger.Warn("未配置双重认证加密密钥，双重认证不可用")
+		userSvc.SetTwoFactor(config, nil)
+	}
+}
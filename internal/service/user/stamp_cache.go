@@ -0,0 +1,75 @@
+package user
+
+import (
+	"sync"
+	"time"
+)
+
+// stampCacheTTL 安全戳缓存时长：本实例轮换安全戳时立即失效，其他实例轮换的安全戳最多延迟该时长生效
+const stampCacheTTL = 10 * time.Second
+
+// stampCacheSweep 每写入多少次缓存清理一次过期记录
+const stampCacheSweep = 1024
+
+// stampEntry 安全戳缓存记录，active 为用户是否可以登录（已激活且未删除）；
+// invalidated 为失效标记，标记之前开始的查询结果不写入缓存
+type stampEntry struct {
+	stamp       string
+	active      bool
+	cachedAt    time.Time
+	invalidated bool
+}
+
+// stampCache 按用户 UUID 缓存安全戳与账户状态，避免每个认证请求都查询数据库
+type stampCache struct {
+	mu      sync.Mutex
+	entries map[string]stampEntry
+	ttl     time.Duration
+	writes  int
+}
+
+func newStampCache(ttl time.Duration) *stampCache {
+	return &stampCache{entries: make(map[string]stampEntry), ttl: ttl}
+}
+
+// get 获取未过期的缓存记录
+func (c *stampCache) get(userUUID string, now time.Time) (stampEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[userUUID]
+	if !ok || entry.invalidated || now.Sub(entry.cachedAt) >= c.ttl {
+		return stampEntry{}, false
+	}
+	return entry, true
+}
+
+// set 缓存 loadedAt 时从数据库读取的安全戳与账户状态，读取之后缓存已失效时不缓存
+func (c *stampCache) set(userUUID, stamp string, active bool, loadedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[userUUID]; ok && entry.invalidated && !entry.cachedAt.Before(loadedAt) {
+		return
+	}
+	c.entries[userUUID] = stampEntry{stamp: stamp, active: active, cachedAt: loadedAt}
+
+	c.writes++
+	if c.writes >= stampCacheSweep {
+		c.writes = 0
+		now := time.Now()
+		for key, entry := range c.entries {
+			if now.Sub(entry.cachedAt) >= c.ttl {
+				delete(c.entries, key)
+			}
+		}
+	}
+}
+
+// invalidate 安全戳轮换或账户状态变化后使缓存失效
+func (c *stampCache) invalidate(userUUID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[userUUID] = stampEntry{cachedAt: time.Now(), invalidated: true}
+}
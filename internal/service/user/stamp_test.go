@@ -0,0 +1,224 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"goWebExample/internal/configs"
+	jwtpkg "goWebExample/internal/pkg/jwt"
+	"goWebExample/internal/repository/user"
+)
+
+// assertRevoked 校验 claims 的吊销状态
+func assertRevoked(t *testing.T, s *UserService, name string, claims *jwtpkg.CustomClaims, want bool) {
+	t.Helper()
+
+	revoked, err := s.jwtMgr.IsRevoked(context.Background(), claims)
+	if err != nil {
+		t.Fatalf("%s: IsRevoked() error = %v", name, err)
+	}
+	if revoked != want {
+		t.Errorf("%s: revoked = %v, want %v", name, revoked, want)
+	}
+}
+
+func TestSecurityStampChangeRevokesTokensAndSessions(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestService(t)
+	s.SetTokenStore(newFakeTokenStore())
+	s.SetSessionStore(newFakeSessionStore(), configs.SessionConfig{Enable: true})
+	s.jwtMgr.SetStampValidator(s)
+
+	stamp := "stamp-1"
+	u := repo.add(user.Users{UUID: "uuid-alice", Username: "alice", IsActive: true,
+		PasswordHash: mustHash(t, s, "old-password"), SecurityStamp: &stamp})
+
+	bearer, err := s.issueTokens(ctx, u, "")
+	if err != nil {
+		t.Fatalf("issueTokens() error = %v", err)
+	}
+	bearerClaims, err := s.jwtMgr.ParseToken(bearer.AccessToken)
+	if err != nil {
+		t.Fatalf("ParseToken() error = %v", err)
+	}
+
+	browser, err := s.issueTokens(WithSession(ctx, "127.0.0.1", "test"), u, "")
+	if err != nil {
+		t.Fatalf("issueTokens() with session error = %v", err)
+	}
+	sessionClaims, err := s.ResolveSession(ctx, browser.SessionToken)
+	if err != nil {
+		t.Fatalf("ResolveSession() error = %v", err)
+	}
+
+	assertRevoked(t, s, "access token", bearerClaims, false)
+	assertRevoked(t, s, "session", sessionClaims, false)
+
+	// The stamp is cached now, changing the password must still take effect immediately
+	changed, err := s.ChangePassword(ctx, u.UUID, "old-password", "new-password")
+	if err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	newClaims, err := s.jwtMgr.ParseToken(changed.AccessToken)
+	if err != nil {
+		t.Fatalf("ParseToken() error = %v", err)
+	}
+
+	assertRevoked(t, s, "access token", bearerClaims, true)
+	assertRevoked(t, s, "session", sessionClaims, true)
+	assertRevoked(t, s, "new access token", newClaims, false)
+}
+
+func TestSecurityStampIsCached(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestService(t)
+
+	stamp := "stamp-1"
+	u := repo.add(user.Users{UUID: "uuid-bob", Username: "bob", IsActive: true, SecurityStamp: &stamp})
+
+	for i := 0; i < 3; i++ {
+		if valid, err := s.IsSecurityStampValid(ctx, u.UUID, stamp); err != nil || !valid {
+			t.Fatalf("IsSecurityStampValid() = %v, %v, want valid", valid, err)
+		}
+	}
+	if repo.gets != 1 {
+		t.Errorf("repository lookups = %d, want the stamp cached after the first", repo.gets)
+	}
+
+	if _, err := s.LogoutOtherDevices(ctx, &jwtpkg.CustomClaims{UserID: u.UUID}, false); err != nil {
+		t.Fatalf("LogoutOtherDevices() error = %v", err)
+	}
+	if valid, _ := s.IsSecurityStampValid(ctx, u.UUID, stamp); valid {
+		t.Error("old stamp should be invalid right after it was rotated")
+	}
+}
+
+func TestStampCache(t *testing.T) {
+	c := newStampCache(time.Minute)
+	start := time.Now()
+
+	c.set("u1", "a", true, start)
+	if got, ok := c.get("u1", start.Add(30*time.Second)); !ok || got.stamp != "a" || !got.active {
+		t.Errorf("get() = %+v, %v, want cached stamp", got, ok)
+	}
+	if _, ok := c.get("u1", start.Add(time.Minute)); ok {
+		t.Error("get() should miss after the ttl")
+	}
+
+	// A lookup that started before the stamp was rotated must not cache the old stamp
+	loadedAt := time.Now()
+	c.invalidate("u1")
+	c.set("u1", "a", true, loadedAt)
+	if _, ok := c.get("u1", time.Now()); ok {
+		t.Error("stale stamp loaded before invalidation should not be cached")
+	}
+
+	reloadedAt := time.Now().Add(time.Millisecond)
+	c.set("u1", "b", false, reloadedAt)
+	if got, ok := c.get("u1", reloadedAt); !ok || got.stamp != "b" || got.active {
+		t.Errorf("get() = %+v, %v, want stamp loaded after invalidation", got, ok)
+	}
+}
+
+func TestAccountChangesRevokeTokensAndSessions(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		change func(s *UserService, operator, target *user.Users) error
+	}{
+		{
+			name: "deactivate",
+			change: func(s *UserService, _, target *user.Users) error {
+				inactive := false
+				_, err := s.UpdateUser(strconv.FormatUint(target.ID, 10), UpdateUserInput{IsActive: &inactive})
+				return err
+			},
+		},
+		{
+			name: "revoke superuser",
+			change: func(s *UserService, operator, target *user.Users) error {
+				_, err := s.SetSuperuser(operator.UUID, strconv.FormatUint(target.ID, 10), false)
+				return err
+			},
+		},
+		{
+			name: "delete",
+			change: func(s *UserService, _, target *user.Users) error {
+				return s.DeleteUser(strconv.FormatUint(target.ID, 10))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestService(t)
+			s.SetSessionStore(newFakeSessionStore(), configs.SessionConfig{Enable: true})
+			s.jwtMgr.SetStampValidator(s)
+
+			stamp := "stamp-1"
+			operator := repo.add(user.Users{UUID: "uuid-admin", Username: "admin", IsActive: true, IsSuperuser: true})
+			target := repo.add(user.Users{UUID: "uuid-bob", Username: "bob", IsActive: true, IsSuperuser: true, SecurityStamp: &stamp})
+
+			bearer, err := s.issueTokens(ctx, target, "")
+			if err != nil {
+				t.Fatalf("issueTokens() error = %v", err)
+			}
+			bearerClaims, _ := s.jwtMgr.ParseToken(bearer.AccessToken)
+			browser, err := s.issueTokens(WithSession(ctx, "127.0.0.1", "test"), target, "")
+			if err != nil {
+				t.Fatalf("issueTokens() with session error = %v", err)
+			}
+			sessionClaims, _ := s.ResolveSession(ctx, browser.SessionToken)
+
+			// Warm the stamp cache so the change has to invalidate it
+			assertRevoked(t, s, "access token", bearerClaims, false)
+
+			if err := tt.change(s, operator, target); err != nil {
+				t.Fatalf("change error = %v", err)
+			}
+			assertRevoked(t, s, "access token", bearerClaims, true)
+			assertRevoked(t, s, "session", sessionClaims, true)
+		})
+	}
+}
+
+func TestResolveSessionRefreshesSnapshot(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestService(t)
+	sessions := newFakeSessionStore()
+	s.SetSessionStore(sessions, configs.SessionConfig{Enable: true})
+
+	stamp := "stamp-1"
+	u := repo.add(user.Users{UUID: "uuid-carol", Username: "carol", IsActive: true, IsSuperuser: true, SecurityStamp: &stamp})
+	browser, err := s.issueTokens(WithSession(ctx, "127.0.0.1", "test"), u, "")
+	if err != nil {
+		t.Fatalf("issueTokens() error = %v", err)
+	}
+
+	// The flag is changed in the database without rotating the stamp, e.g. by a role update
+	_ = repo.Update(u.ID, map[string]interface{}{"is_superuser": false})
+	age := func() {
+		sess, _ := sessions.Get(ctx, hashRefreshToken(browser.SessionToken))
+		sess.LastSeenAt = time.Now().Add(-2 * sessionTouchInterval)
+		_ = sessions.Touch(ctx, sess, 0)
+	}
+	age()
+
+	claims, err := s.ResolveSession(ctx, browser.SessionToken)
+	if err != nil {
+		t.Fatalf("ResolveSession() error = %v", err)
+	}
+	if claims.IsAdmin || len(claims.Permissions) != 0 {
+		t.Errorf("admin = %v, permissions = %v, want the snapshot refreshed", claims.IsAdmin, claims.Permissions)
+	}
+
+	_ = repo.Update(u.ID, map[string]interface{}{"is_active": false})
+	age()
+	if _, err := s.ResolveSession(ctx, browser.SessionToken); !errors.Is(err, jwtpkg.ErrInvalidSession) {
+		t.Errorf("ResolveSession() error = %v, want the session of a disabled user rejected", err)
+	}
+}
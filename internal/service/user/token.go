@@ -0,0 +1,203 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	jwtpkg "goWebExample/internal/pkg/jwt"
+	"goWebExample/internal/repository/token"
+	"goWebExample/internal/repository/user"
+)
+
+// refreshTokenBytes refresh token 随机字节数
+const refreshTokenBytes = 32
+
+// SetTokenStore 设置 refresh token 与吊销列表存储，未设置时只签发 access token
+func (s *UserService) SetTokenStore(store token.Store) {
+	s.tokens = store
+}
+
+// issueTokens 为用户签发 access token 与 refresh token，familyID 为空时开启新的 token 家族
+//
+// 登录请求通过 WithSession 要求会话模式时，改为创建服务端会话。
+func (s *UserService) issueTokens(ctx context.Context, u *user.Users, familyID string) (*AuthResponse, error) {
+	if familyID == "" {
+		if req, ok := s.sessionRequested(ctx); ok {
+			return s.startSession(ctx, u, req)
+		}
+		familyID = uuid.NewString()
+	}
+
+	roles, permissions := s.resolvePermissions(u)
+	accessToken, _, err := s.jwtMgr.IssueAccessToken(jwtpkg.Subject{
+		UserID:      u.UUID,
+		Username:    u.Username,
+		Nickname:    u.Nickname,
+		IsAdmin:     u.IsSuperuser,
+		SessionID:   familyID,
+		Roles:       roles,
+		Permissions: permissions,
+		Stamp:       securityStamp(u),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("生成token失败: %w", err)
+	}
+
+	resp := &AuthResponse{
+		User:        toDTO(u),
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.jwtMgr.Duration().Seconds()),
+	}
+
+	if s.tokens == nil {
+		return resp, nil
+	}
+
+	refreshToken, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	refreshDuration := s.jwtMgr.RefreshDuration()
+	if err := s.tokens.SaveRefreshToken(ctx, &token.RefreshToken{
+		TokenHash: hash,
+		FamilyID:  familyID,
+		UserID:    u.ID,
+		Stamp:     securityStamp(u),
+		ExpiresAt: now.Add(refreshDuration),
+		CreatedAt: now,
+	}); err != nil {
+		return nil, fmt.Errorf("保存refresh token失败: %w", err)
+	}
+
+	resp.RefreshToken = refreshToken
+	resp.RefreshExpiresIn = int64(refreshDuration.Seconds())
+	return resp, nil
+}
+
+// RefreshToken 使用 refresh token 换取新的 token 对，旧 refresh token 随即失效
+//
+// 已使用过的 refresh token 再次出现说明可能已泄露，此时吊销整个 token 家族，
+// 合法持有者也需要重新登录。
+func (s *UserService) RefreshToken(ctx context.Context, refreshToken string) (*AuthResponse, error) {
+	if s.tokens == nil || refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	record, err := s.tokens.GetRefreshToken(ctx, hashRefreshToken(refreshToken))
+	if errors.Is(err, token.ErrRefreshTokenNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取refresh token失败: %w", err)
+	}
+
+	revoked, err := s.tokens.IsFamilyRevoked(ctx, record.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("检查token家族失败: %w", err)
+	}
+	if revoked {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	first, err := s.tokens.MarkRefreshTokenUsed(ctx, record.TokenHash)
+	if err != nil {
+		return nil, fmt.Errorf("轮换refresh token失败: %w", err)
+	}
+	if !first {
+		s.logger.Warn("检测到refresh token重复使用，吊销整个token家族",
+			zap.Uint64("userID", record.UserID),
+			zap.String("familyID", record.FamilyID))
+		if err := s.tokens.RevokeFamily(ctx, record.FamilyID, s.jwtMgr.RefreshDuration()); err != nil {
+			s.logger.Error("吊销token家族失败", zap.String("familyID", record.FamilyID), zap.Error(err))
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	userInfo, err := s.repo.GetByID(record.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	// 修改或重置密码后安全戳已变化，此前签发的 refresh token 不再有效
+	if record.Stamp != securityStamp(userInfo) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if !userInfo.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	if isLocked(userInfo, time.Now()) {
+		return nil, ErrAccountLocked
+	}
+
+	return s.issueTokens(ctx, userInfo, record.FamilyID)
+}
+
+// Logout 退出登录：吊销当前 access token 及其所属的 refresh token 家族，使用会话登录时删除会话
+func (s *UserService) Logout(ctx context.Context, claims *jwtpkg.CustomClaims) error {
+	if claims == nil {
+		return nil
+	}
+
+	// 会话认证的 claims 没有 jti
+	if s.sessions != nil && claims.ID == "" && claims.SessionID != "" {
+		if err := s.sessions.Delete(ctx, claims.SessionID); err != nil {
+			return fmt.Errorf("删除会话失败: %w", err)
+		}
+		s.logger.Info("用户退出登录", zap.String("username", claims.Username), zap.String("sessionID", claims.SessionID))
+		return nil
+	}
+
+	if s.tokens == nil {
+		return nil
+	}
+
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := s.tokens.RevokeAccessToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return fmt.Errorf("吊销access token失败: %w", err)
+		}
+	}
+
+	if claims.SessionID != "" {
+		if err := s.tokens.RevokeFamily(ctx, claims.SessionID, s.jwtMgr.RefreshDuration()); err != nil {
+			return fmt.Errorf("吊销refresh token失败: %w", err)
+		}
+	}
+
+	s.logger.Info("用户退出登录", zap.String("username", claims.Username), zap.String("sessionID", claims.SessionID))
+	return nil
+}
+
+// PruneExpiredTokens 删除已过期的 refresh token、access token 吊销记录与已使用的操作 token
+func (s *UserService) PruneExpiredTokens(ctx context.Context) (int64, error) {
+	if s.tokens == nil {
+		return 0, nil
+	}
+	return s.tokens.PruneExpired(ctx, time.Now())
+}
+
+// newRefreshToken 生成随机 refresh token，返回 token 及其摘要
+func newRefreshToken() (string, string, error) {
+	buf := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("生成refresh token失败: %w", err)
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(buf)
+	return refreshToken, hashRefreshToken(refreshToken), nil
+}
+
+// hashRefreshToken 计算 refresh token 摘要，服务端只保存摘要
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
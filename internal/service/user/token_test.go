@@ -0,0 +1,171 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"goWebExample/internal/repository/token"
+	"goWebExample/internal/repository/user"
+)
+
+// newTokenTestService 创建带内存 token 存储的用户服务，并为一个已登录用户签发 token
+func newTokenTestService(t *testing.T) (*UserService, *fakeUserRepo, *fakeTokenStore, *user.Users, *AuthResponse) {
+	t.Helper()
+
+	s, repo := newTestService(t)
+	store := newFakeTokenStore()
+	s.SetTokenStore(store)
+
+	stamp := "stamp-1"
+	u := repo.add(user.Users{UUID: "uuid-alice", Username: "alice", IsActive: true, SecurityStamp: &stamp})
+	resp, err := s.issueTokens(context.Background(), u, "")
+	if err != nil {
+		t.Fatalf("issueTokens() error = %v", err)
+	}
+	if resp.RefreshToken == "" {
+		t.Fatal("issueTokens() should return a refresh token when a token store is set")
+	}
+	return s, repo, store, u, resp
+}
+
+func TestRefreshToken(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// run 执行测试步骤，返回最后一次刷新的错误
+		run  func(t *testing.T, s *UserService, repo *fakeUserRepo, u *user.Users, login *AuthResponse) error
+		want error
+	}{
+		{
+			name: "rotation issues a new working token",
+			run: func(t *testing.T, s *UserService, _ *fakeUserRepo, _ *user.Users, login *AuthResponse) error {
+				rotated, err := s.RefreshToken(ctx, login.RefreshToken)
+				if err != nil {
+					t.Fatalf("first refresh error = %v", err)
+				}
+				if rotated.RefreshToken == login.RefreshToken {
+					t.Error("refresh should rotate the refresh token")
+				}
+				_, err = s.RefreshToken(ctx, rotated.RefreshToken)
+				return err
+			},
+		},
+		{
+			name: "reuse of a rotated token is detected",
+			run: func(t *testing.T, s *UserService, _ *fakeUserRepo, _ *user.Users, login *AuthResponse) error {
+				if _, err := s.RefreshToken(ctx, login.RefreshToken); err != nil {
+					t.Fatalf("first refresh error = %v", err)
+				}
+				_, err := s.RefreshToken(ctx, login.RefreshToken)
+				return err
+			},
+			want: ErrRefreshTokenReused,
+		},
+		{
+			name: "reuse revokes the whole family",
+			run: func(t *testing.T, s *UserService, _ *fakeUserRepo, _ *user.Users, login *AuthResponse) error {
+				rotated, err := s.RefreshToken(ctx, login.RefreshToken)
+				if err != nil {
+					t.Fatalf("first refresh error = %v", err)
+				}
+				// An attacker replays the stolen original token, the legitimate holder is logged out too
+				_, _ = s.RefreshToken(ctx, login.RefreshToken)
+				_, err = s.RefreshToken(ctx, rotated.RefreshToken)
+				return err
+			},
+			want: ErrInvalidRefreshToken,
+		},
+		{
+			name: "unknown token",
+			run: func(_ *testing.T, s *UserService, _ *fakeUserRepo, _ *user.Users, _ *AuthResponse) error {
+				_, err := s.RefreshToken(ctx, "not-a-token")
+				return err
+			},
+			want: ErrInvalidRefreshToken,
+		},
+		{
+			name: "security stamp changed",
+			run: func(_ *testing.T, s *UserService, repo *fakeUserRepo, u *user.Users, login *AuthResponse) error {
+				_ = repo.Update(u.ID, map[string]interface{}{"security_stamp": "stamp-2"})
+				_, err := s.RefreshToken(ctx, login.RefreshToken)
+				return err
+			},
+			want: ErrInvalidRefreshToken,
+		},
+		{
+			name: "disabled user",
+			run: func(_ *testing.T, s *UserService, repo *fakeUserRepo, u *user.Users, login *AuthResponse) error {
+				_ = repo.Update(u.ID, map[string]interface{}{"is_active": false})
+				_, err := s.RefreshToken(ctx, login.RefreshToken)
+				return err
+			},
+			want: ErrAccountDisabled,
+		},
+		{
+			name: "logout revokes the family",
+			run: func(t *testing.T, s *UserService, _ *fakeUserRepo, _ *user.Users, login *AuthResponse) error {
+				claims, err := s.jwtMgr.ParseToken(login.AccessToken)
+				if err != nil {
+					t.Fatalf("ParseToken() error = %v", err)
+				}
+				if err := s.Logout(ctx, claims); err != nil {
+					t.Fatalf("Logout() error = %v", err)
+				}
+				_, err = s.RefreshToken(ctx, login.RefreshToken)
+				return err
+			},
+			want: ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, _, u, login := newTokenTestService(t)
+			if err := tt.run(t, s, repo, u, login); !errors.Is(err, tt.want) {
+				t.Errorf("RefreshToken() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	ctx := context.Background()
+	s, _, store, _, login := newTokenTestService(t)
+
+	claims, err := s.jwtMgr.ParseToken(login.AccessToken)
+	if err != nil {
+		t.Fatalf("ParseToken() error = %v", err)
+	}
+	if err := s.Logout(ctx, claims); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+
+	if revoked, _ := store.IsAccessTokenRevoked(ctx, claims.ID); !revoked {
+		t.Error("access token should be revoked after logout")
+	}
+	if revoked, _ := store.IsFamilyRevoked(ctx, claims.SessionID); !revoked {
+		t.Error("refresh token family should be revoked after logout")
+	}
+}
+
+func TestPruneExpiredTokens(t *testing.T) {
+	ctx := context.Background()
+	s, _, store, _, _ := newTokenTestService(t)
+
+	_ = store.SaveRefreshToken(ctx, &token.RefreshToken{TokenHash: "expired", ExpiresAt: time.Now().Add(-time.Minute)})
+	_ = store.RevokeAccessToken(ctx, "expired-jti", time.Now().Add(-time.Minute))
+
+	deleted, err := s.PruneExpiredTokens(ctx)
+	if err != nil {
+		t.Fatalf("PruneExpiredTokens() error = %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("deleted = %d, want the 2 expired records", deleted)
+	}
+	if len(store.refresh) != 1 {
+		t.Errorf("refresh tokens left = %d, want the live login token kept", len(store.refresh))
+	}
+}
@@ -0,0 +1,415 @@
+package user
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"goWebExample/internal/configs"
+	jwtpkg "goWebExample/internal/pkg/jwt"
+	"goWebExample/internal/pkg/totp"
+	"goWebExample/internal/repository/user"
+	"goWebExample/pkg/envelope"
+)
+
+// recoveryCodeAlphabet 恢复码字符集，去掉了易混淆的 0/1/l/o
+const recoveryCodeAlphabet = "23456789abcdefghijkmnpqrstuvwxyz"
+
+// recoveryDigestPrefix 恢复码摘要前缀，没有前缀的是使用密码哈希算法保存的恢复码
+const recoveryDigestPrefix = "hmac-sha256:"
+
+// minRecoveryCodeKeySize 恢复码摘要密钥的最小长度（字节）
+const minRecoveryCodeKeySize = 32
+
+// TwoFactorEnrollment 双重认证注册信息，密钥只在此时返回一次
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauthUri"` // 用于生成二维码
+}
+
+// SetTwoFactor 设置双重认证配置与密钥加密器，加密器为 nil 时无法启用双重认证
+func (s *UserService) SetTwoFactor(config configs.TwoFactorConfig, secrets envelope.Cipher) {
+	s.twoFactor = config
+	s.secrets = secrets
+}
+
+// SetRecoveryCodeKey 设置恢复码摘要密钥，未设置时恢复码使用密码哈希算法保存
+//
+// 密钥只保存在服务端，数据库泄露后无法离线穷举恢复码；更换密钥后已生成的恢复码失效。
+func (s *UserService) SetRecoveryCodeKey(key []byte) error {
+	if len(key) < minRecoveryCodeKeySize {
+		return fmt.Errorf("恢复码摘要密钥至少需要 %d 字节", minRecoveryCodeKeySize)
+	}
+	s.recoveryKey = key
+	return nil
+}
+
+// EnrollTwoFactor 生成新的 TOTP 密钥并加密保存，需调用 ConfirmTwoFactor 校验验证码后才会启用
+func (s *UserService) EnrollTwoFactor(userUUID string) (*TwoFactorEnrollment, error) {
+	if s.secrets == nil {
+		return nil, ErrTwoFactorUnavailable
+	}
+
+	u, err := s.getByUUID(userUUID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Is2FAEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("生成双重认证密钥失败: %w", err)
+	}
+	encrypted, err := s.secrets.Encrypt([]byte(secret), secretAAD(u))
+	if errors.Is(err, envelope.ErrReadOnly) {
+		return nil, ErrTwoFactorUnavailable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("加密双重认证密钥失败: %w", err)
+	}
+	if err := s.repo.Update(u.ID, map[string]interface{}{"two_factor_secret": encrypted}); err != nil {
+		return nil, translateRepoError(err)
+	}
+
+	account := u.Email
+	if account == "" {
+		account = u.Username
+	}
+	return &TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.URI(s.twoFactor.Issuer, account, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor 校验验证码并启用双重认证，返回一次性恢复码（只返回这一次）
+func (s *UserService) ConfirmTwoFactor(ctx context.Context, userUUID, code string) ([]string, error) {
+	u, err := s.getByUUID(userUUID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Is2FAEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if u.TwoFactorSecret == nil {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	ok, err := s.verifyTOTP(ctx, u, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashed, err := s.newRecoveryCodes(u)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(u.ID, map[string]interface{}{
+		"is_2fa_enabled": true,
+		"recovery_codes": hashed,
+	}); err != nil {
+		return nil, translateRepoError(err)
+	}
+
+	s.logger.Info("已启用双重认证", zap.String("username", u.Username))
+	return codes, nil
+}
+
+// DisableTwoFactor 校验密码与验证码（或恢复码）后关闭双重认证
+func (s *UserService) DisableTwoFactor(ctx context.Context, userUUID, password, code string) error {
+	u, err := s.getByUUID(userUUID)
+	if err != nil {
+		return err
+	}
+	if !u.Is2FAEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	matched, err := s.hasher.Verify(password, u.PasswordHash)
+	if err != nil {
+		s.logger.Error("校验密码失败", zap.String("username", u.Username), zap.Error(err))
+	}
+	if !matched {
+		return ErrIncorrectPassword
+	}
+
+	ok, err := s.verifySecondFactor(ctx, u, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	if err := s.repo.Update(u.ID, map[string]interface{}{
+		"is_2fa_enabled":    false,
+		"two_factor_secret": nil,
+		"recovery_codes":    nil,
+	}); err != nil {
+		return translateRepoError(err)
+	}
+
+	s.logger.Info("已关闭双重认证", zap.String("username", u.Username))
+	return nil
+}
+
+// VerifyTwoFactorLogin 登录第二步：校验 challenge token 与验证码（或恢复码），通过后签发 token
+func (s *UserService) VerifyTwoFactorLogin(ctx context.Context, mfaToken, code, ip string) (*AuthResponse, error) {
+	claims, err := s.jwtMgr.ParseActionToken(mfaToken, jwtpkg.PurposeMFAChallenge)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	u, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+	// challenge 签发后修改过密码，或已关闭双重认证
+	if claims.Binding != securityStamp(u) || !u.Is2FAEnabled {
+		return nil, ErrInvalidMFAToken
+	}
+
+	if isLocked(u, time.Now()) {
+		return nil, ErrAccountLocked
+	}
+	if !u.IsActive {
+		return nil, ErrAccountDisabled
+	}
+
+	ok, err := s.verifySecondFactor(ctx, u, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.logger.Warn("双重认证验证码错误", zap.String("username", u.Username))
+		if s.recordLoginFailure(u) {
+			return nil, ErrAccountLocked
+		}
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	// challenge token 只能成功使用一次
+	if s.tokens == nil {
+		return nil, errors.New("token存储未初始化")
+	}
+	consumed, err := s.tokens.ConsumeActionToken(ctx, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return nil, fmt.Errorf("记录challenge token失败: %w", err)
+	}
+	if !consumed {
+		return nil, ErrInvalidMFAToken
+	}
+
+	s.resetLoginFailures(u)
+	authResp, err := s.issueTokens(ctx, u, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateLoginInfo(u.ID, ip); err != nil {
+		s.logger.Error("更新登录信息失败", zap.String("username", u.Username), zap.Error(err))
+	}
+	return authResp, nil
+}
+
+// startMFAChallenge 签发登录第二步使用的 challenge token
+func (s *UserService) startMFAChallenge(u *user.Users) (*AuthResponse, error) {
+	ttl := s.twoFactor.GetChallengeTTL()
+	signed, _, err := s.jwtMgr.IssueActionToken(jwtpkg.PurposeMFAChallenge, strconv.FormatUint(u.ID, 10), securityStamp(u), ttl)
+	if err != nil {
+		return nil, fmt.Errorf("生成challenge token失败: %w", err)
+	}
+
+	return &AuthResponse{
+		MFARequired: true,
+		MFAToken:    signed,
+		ExpiresIn:   int64(ttl.Seconds()),
+	}, nil
+}
+
+// verifySecondFactor 校验 TOTP 验证码或恢复码
+func (s *UserService) verifySecondFactor(ctx context.Context, u *user.Users, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return s.verifyTOTP(ctx, u, code)
+	}
+	return s.useRecoveryCode(u, code)
+}
+
+// verifyTOTP 校验 TOTP 验证码，同一时间步的验证码只能使用一次
+func (s *UserService) verifyTOTP(ctx context.Context, u *user.Users, code string) (bool, error) {
+	if s.secrets == nil {
+		return false, ErrTwoFactorUnavailable
+	}
+	if u.TwoFactorSecret == nil {
+		return false, nil
+	}
+
+	secret, err := s.secrets.Decrypt(*u.TwoFactorSecret, secretAAD(u))
+	if err != nil {
+		return false, fmt.Errorf("解密双重认证密钥失败: %w", err)
+	}
+
+	now := time.Now()
+	counter, ok, err := totp.Validate(code, string(secret), now, totp.DefaultSkew)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if s.tokens == nil {
+		return true, nil
+	}
+	// 以时间步为键记录已使用的验证码，保留到验证窗口结束
+	expiresAt := now.Add(time.Duration(totp.DefaultSkew+1) * totp.Period)
+	return s.tokens.ConsumeActionToken(ctx, fmt.Sprintf("totp:%d:%d", u.ID, counter), expiresAt)
+}
+
+// useRecoveryCode 校验并消耗一个恢复码
+func (s *UserService) useRecoveryCode(u *user.Users, code string) (bool, error) {
+	if u.RecoveryCodes == nil {
+		return false, nil
+	}
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, nil
+	}
+
+	var hashes []string
+	if err := json.Unmarshal([]byte(*u.RecoveryCodes), &hashes); err != nil {
+		return false, fmt.Errorf("解析恢复码失败: %w", err)
+	}
+
+	i := s.matchRecoveryCode(u, normalized, hashes)
+	if i < 0 {
+		return false, nil
+	}
+
+	remaining, err := json.Marshal(append(hashes[:i:i], hashes[i+1:]...))
+	if err != nil {
+		return false, err
+	}
+	// 条件更新，防止同一恢复码被并发使用两次
+	replaced, err := s.repo.ReplaceRecoveryCodes(u.ID, *u.RecoveryCodes, string(remaining))
+	if err != nil {
+		return false, err
+	}
+	if replaced {
+		s.logger.Info("已使用恢复码", zap.String("username", u.Username), zap.Int("remaining", len(hashes)-1))
+	}
+	return replaced, nil
+}
+
+// matchRecoveryCode 返回与恢复码匹配的摘要下标，未匹配时返回 -1
+//
+// 摘要逐个做常量时间比较且不提前返回，耗时与匹配位置无关。
+func (s *UserService) matchRecoveryCode(u *user.Users, normalized string, hashes []string) int {
+	var digest []byte
+	if s.recoveryKey != nil {
+		digest = []byte(recoveryCodeDigest(s.recoveryKey, u, normalized))
+	}
+	matched := -1
+	for i, hash := range hashes {
+		if !strings.HasPrefix(hash, recoveryDigestPrefix) {
+			// 未配置摘要密钥时保存的密码哈希
+			if ok, err := s.hasher.Verify(normalized, hash); err == nil && ok && matched < 0 {
+				matched = i
+			}
+			continue
+		}
+		if digest != nil && subtle.ConstantTimeCompare(digest, []byte(hash)) == 1 && matched < 0 {
+			matched = i
+		}
+	}
+	return matched
+}
+
+// hashRecoveryCode 计算恢复码的保存形式：配置了摘要密钥时为 HMAC 摘要，否则为密码哈希
+func (s *UserService) hashRecoveryCode(u *user.Users, normalized string) (string, error) {
+	if s.recoveryKey != nil {
+		return recoveryCodeDigest(s.recoveryKey, u, normalized), nil
+	}
+	return s.hasher.Hash(normalized)
+}
+
+// newRecoveryCodes 生成恢复码，返回明文与摘要的 JSON 数组
+func (s *UserService) newRecoveryCodes(u *user.Users) ([]string, string, error) {
+	count := s.twoFactor.GetRecoveryCodeCount()
+	codes := make([]string, 0, count)
+	hashes := make([]string, 0, count)
+
+	buf := make([]byte, 10)
+	for i := 0; i < count; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, "", err
+		}
+		for j := range buf {
+			buf[j] = recoveryCodeAlphabet[int(buf[j])%len(recoveryCodeAlphabet)]
+		}
+		code := string(buf[:5]) + "-" + string(buf[5:])
+
+		hash, err := s.hashRecoveryCode(u, normalizeRecoveryCode(code))
+		if err != nil {
+			return nil, "", err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hash)
+	}
+
+	data, err := json.Marshal(hashes)
+	if err != nil {
+		return nil, "", err
+	}
+	return codes, string(data), nil
+}
+
+// recoveryCodeDigest 计算恢复码摘要：以服务端密钥为键、对用户 UUID 与恢复码计算 HMAC-SHA256，
+// 相同恢复码在不同用户下摘要不同
+//
+// 恢复码是随机生成的字符串，有服务端密钥时不需要密码哈希算法的计算开销。
+func recoveryCodeDigest(key []byte, u *user.Users, normalized string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(u.UUID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(normalized))
+	return recoveryDigestPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// getByUUID 根据 UUID 获取用户
+func (s *UserService) getByUUID(userUUID string) (*user.Users, error) {
+	u, err := s.repo.GetByUUID(userUUID)
+	if err != nil {
+		return nil, translateRepoError(err)
+	}
+	return u, nil
+}
+
+// normalizeRecoveryCode 统一恢复码格式：去掉分隔符与空白并转为小写
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// secretAAD 加密 TOTP 密钥时的附加认证数据，使密文只能用于所属用户
+func secretAAD(u *user.Users) []byte {
+	return user.TwoFactorSecretAAD(u.ID)
+}
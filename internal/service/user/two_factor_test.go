@@ -0,0 +1,107 @@
+package user
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"goWebExample/internal/repository/user"
+)
+
+// testRecoveryCodeKey 测试用的恢复码摘要密钥
+var testRecoveryCodeKey = []byte("test-recovery-code-key-32-bytes!")
+
+func TestRecoveryCodes(t *testing.T) {
+	s, repo := newTestService(t)
+	if err := s.SetRecoveryCodeKey(testRecoveryCodeKey); err != nil {
+		t.Fatalf("SetRecoveryCodeKey() error = %v", err)
+	}
+	alice := user.Users{UUID: "uuid-alice", Username: "alice", IsActive: true}
+
+	codes, hashed, err := s.newRecoveryCodes(&alice)
+	if err != nil {
+		t.Fatalf("newRecoveryCodes() error = %v", err)
+	}
+	if strings.Contains(hashed, normalizeRecoveryCode(codes[0])) {
+		t.Fatal("recovery codes must not be stored in plain text")
+	}
+	var digests []string
+	if err := json.Unmarshal([]byte(hashed), &digests); err != nil {
+		t.Fatalf("stored codes are not a JSON array: %v", err)
+	}
+	for _, digest := range digests {
+		if !strings.HasPrefix(digest, recoveryDigestPrefix) {
+			t.Errorf("digest %q, want %s prefix", digest, recoveryDigestPrefix)
+		}
+	}
+	alice.RecoveryCodes = &hashed
+	u := repo.add(alice)
+
+	use := func(code string) bool {
+		t.Helper()
+		current := repo.get(u.ID)
+		ok, err := s.useRecoveryCode(&current, code)
+		if err != nil {
+			t.Fatalf("useRecoveryCode(%q) error = %v", code, err)
+		}
+		return ok
+	}
+
+	if use("wrong-code") {
+		t.Error("an unknown code should be rejected")
+	}
+	if !use(strings.ToUpper(codes[1])) {
+		t.Error("a valid code should be accepted regardless of case")
+	}
+	if use(codes[1]) {
+		t.Error("a used code should be rejected")
+	}
+	if !use(codes[0]) {
+		t.Error("the other codes should remain usable")
+	}
+
+	other := &user.Users{UUID: "uuid-bob"}
+	if recoveryCodeDigest(testRecoveryCodeKey, u, "abcde12345") == recoveryCodeDigest(testRecoveryCodeKey, other, "abcde12345") {
+		t.Error("the same code should have different digests for different users")
+	}
+
+	// The digest depends on the server-side key, the UUID stored next to it is not enough
+	otherKey := []byte("another-recovery-code-key-32-byt")
+	if recoveryCodeDigest(testRecoveryCodeKey, u, "abcde12345") == recoveryCodeDigest(otherKey, u, "abcde12345") {
+		t.Error("the digest should depend on the server-side key")
+	}
+	if err := s.SetRecoveryCodeKey([]byte("short")); err == nil {
+		t.Error("SetRecoveryCodeKey() should reject a short key")
+	}
+}
+
+func TestRecoveryCodesWithoutKey(t *testing.T) {
+	s, repo := newTestService(t)
+	carol := user.Users{UUID: "uuid-carol", Username: "carol", IsActive: true}
+
+	codes, hashed, err := s.newRecoveryCodes(&carol)
+	if err != nil {
+		t.Fatalf("newRecoveryCodes() error = %v", err)
+	}
+	if strings.Contains(hashed, recoveryDigestPrefix) {
+		t.Error("without a key recovery codes should be saved with the password hasher")
+	}
+	carol.RecoveryCodes = &hashed
+	u := repo.add(carol)
+
+	if ok, err := s.useRecoveryCode(u, codes[0]); err != nil || !ok {
+		t.Errorf("useRecoveryCode() = %v, %v, want the code accepted", ok, err)
+	}
+}
+
+func TestLegacyRecoveryCodes(t *testing.T) {
+	s, repo := newTestService(t)
+	legacy, _ := json.Marshal([]string{mustHash(t, s, "abcde23456")})
+	codes := string(legacy)
+	u := repo.add(user.Users{UUID: "uuid-carol", Username: "carol", IsActive: true, RecoveryCodes: &codes})
+
+	ok, err := s.useRecoveryCode(u, "abcde-23456")
+	if err != nil || !ok {
+		t.Errorf("useRecoveryCode() = %v, %v, want codes saved with the password hasher accepted", ok, err)
+	}
+}
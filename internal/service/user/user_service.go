@@ -1,13 +1,23 @@
 package user
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"goWebExample/internal/configs"
 	jwtpkg "goWebExample/internal/pkg/jwt"
+	"goWebExample/internal/pkg/mail"
+	"goWebExample/internal/pkg/password"
+	rbacRepo "goWebExample/internal/repository/rbac"
+	"goWebExample/internal/repository/session"
+	"goWebExample/internal/repository/token"
 	"goWebExample/internal/repository/user"
+	"goWebExample/pkg/envelope"
 	"strconv"
 	"time"
 
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 const ServiceName = "user"
@@ -15,6 +25,7 @@ const TimeFormat = "2006-01-02 15:04:05"
 
 // UserDTO 用户数据传输对象
 type UserDTO struct {
+	ID               uint64  `json:"id,omitempty"`
 	UUID             string  `json:"uuid,omitempty"`
 	Username         string  `json:"username,omitempty"`
 	Nickname         string  `json:"nickname,omitempty"`
@@ -41,10 +52,16 @@ type UserDTO struct {
 
 // AuthResponse 认证响应结构体
 type AuthResponse struct {
-	User        *UserDTO `json:"user"`
-	AccessToken string   `json:"accessToken"`
-	TokenType   string   `json:"tokenType"`
-	ExpiresIn   int64    `json:"expiresIn"`
+	User             *UserDTO `json:"user"`
+	AccessToken      string   `json:"accessToken,omitempty"`
+	TokenType        string   `json:"tokenType,omitempty"`
+	ExpiresIn        int64    `json:"expiresIn,omitempty"`
+	RefreshToken     string   `json:"refreshToken,omitempty"`
+	RefreshExpiresIn int64    `json:"refreshExpiresIn,omitempty"`
+	MFARequired      bool     `json:"mfaRequired,omitempty"` // 为 true 时需使用 MFAToken 与验证码完成第二步登录
+	MFAToken         string   `json:"mfaToken,omitempty"`
+	SessionToken     string   `json:"-"` // 会话模式下的 Cookie 值，由 handler 写入 Cookie，不出现在响应体中
+	SessionExpiresIn int64    `json:"sessionExpiresIn,omitempty"`
 }
 
 // formatTime 格式化时间
@@ -62,6 +79,7 @@ func toDTO(u *user.Users) *UserDTO {
 	}
 
 	return &UserDTO{
+		ID:               u.ID,
 		UUID:             u.UUID,
 		Username:         u.Username,
 		Nickname:         u.Nickname,
@@ -97,14 +115,31 @@ type UserService struct {
 	repo   user.RepositoryUser
 	logger *zap.Logger
 	jwtMgr *jwtpkg.JwtManager
+	hasher *password.Hasher
+
+	lockout      configs.LockoutConfig
+	registration configs.RegistrationConfig
+	reset        configs.PasswordResetConfig
+	tokens       token.Store
+	rbac         rbacRepo.RepositoryRBAC
+	mailer       mail.Sender
+	twoFactor    configs.TwoFactorConfig
+	secrets      envelope.Cipher
+	recoveryKey  []byte
+	identities   user.RepositoryIdentity
+	sessions     session.Store
+	sessionCfg   configs.SessionConfig
+	stamps       *stampCache
 }
 
 // NewUserService 创建 UserService 实例
-func NewUserService(repo user.RepositoryUser, logger *zap.Logger, jwtMgr *jwtpkg.JwtManager) *UserService {
+func NewUserService(repo user.RepositoryUser, logger *zap.Logger, jwtMgr *jwtpkg.JwtManager, hasher *password.Hasher) *UserService {
 	return &UserService{
 		repo:   repo,
 		logger: logger,
 		jwtMgr: jwtMgr,
+		hasher: hasher,
+		stamps: newStampCache(stampCacheTTL),
 	}
 }
 
@@ -125,52 +160,112 @@ func (s *UserService) GetUserDetail(userID string) (*UserDTO, error) {
 	return toDTO(userInfo), nil
 }
 
-func (s *UserService) Login(username string, password string, ip string) (*AuthResponse, error) {
+// Login 用户登录，成功后签发 access token 与 refresh token；启用双重认证时只返回第二步所需的 challenge token
+func (s *UserService) Login(ctx context.Context, username string, password string, ip string) (*AuthResponse, error) {
 	s.logger.Info("用户登录", zap.String("username", username), zap.String("ip", ip))
 
 	// 1. 根据用户名获取用户信息
 	userInfo, err := s.repo.GetUserByUsername(username)
 	if err != nil {
 		s.logger.Error("用户不存在", zap.String("username", username), zap.Error(err))
-		return nil, fmt.Errorf("用户不存在或密码错误")
+		// 用户不存在时同样计算一次哈希，避免通过响应时间枚举用户名
+		_, _ = s.hasher.Hash(password)
+		return nil, ErrInvalidCredentials
+	}
+
+	// 2. 验证密码，锁定期内同样计算哈希，响应时间与错误信息不暴露账户是否存在或被锁定
+	matched, err := s.hasher.Verify(password, userInfo.PasswordHash)
+	if err != nil {
+		s.logger.Error("校验密码失败", zap.String("username", username), zap.Error(err))
 	}
 
-	// 2. 验证密码
-	if userInfo.PasswordHash != password { // 注意：实际应用中应该使用安全的密码哈希比较
+	// 3. 锁定期内不记录失败次数，只有密码正确时才提示账户已锁定
+	if isLocked(userInfo, time.Now()) {
+		s.logger.Warn("用户被锁定", zap.String("username", username), zap.Time("lockoutEnd", *userInfo.LockoutEnd))
+		if !matched {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, ErrAccountLocked
+	}
+	if !matched {
 		s.logger.Warn("密码错误", zap.String("username", username))
-		return nil, fmt.Errorf("用户不存在或密码错误")
+		s.recordLoginFailure(userInfo)
+		return nil, ErrInvalidCredentials
+	}
+
+	if !userInfo.IsActive {
+		s.logger.Warn("用户已禁用", zap.String("username", username))
+		return nil, ErrAccountDisabled
 	}
-	if userInfo.LockoutEnd != nil && time.Now().Before(*userInfo.LockoutEnd) || !userInfo.IsActive {
-		s.logger.Warn("用户被锁定", zap.String("username", username))
-		return nil, fmt.Errorf("用户被锁定")
+	if s.registration.RequireEmailVerification && !userInfo.EmailVerified {
+		s.logger.Warn("邮箱未验证", zap.String("username", username))
+		return nil, ErrEmailNotVerified
 	}
+	s.rehashIfNeeded(userInfo, password)
 
-	userDTO := toDTO(userInfo)
+	// 4. 启用双重认证时只返回第二步所需的 challenge token，失败计数在第二步成功后才清零
+	if userInfo.Is2FAEnabled {
+		return s.startMFAChallenge(userInfo)
+	}
+	s.resetLoginFailures(userInfo)
 
-	// 3. 使用 jwtMgr 生成 token
-	token, err := s.jwtMgr.GenerateToken(
-		userDTO.UUID,
-		userDTO.Username,
-		userDTO.Nickname,
-		userDTO.IsSuperuser,
-	)
+	// 5. 签发 token
+	authResp, err := s.issueTokens(ctx, userInfo, "")
 	if err != nil {
 		s.logger.Error("生成token失败", zap.String("username", username), zap.Error(err))
-		return nil, fmt.Errorf("生成token失败: %w", err)
+		return nil, err
 	}
 
-	// 4. 更新登录信息
+	// 6. 更新登录信息
 	if err := s.repo.UpdateLoginInfo(userInfo.ID, ip); err != nil {
 		s.logger.Error("更新登录信息失败", zap.String("username", username), zap.Error(err))
 		// 即使更新登录信息失败，仍然允许用户登录
 	}
 
-	return &AuthResponse{
-		User:        userDTO,
-		AccessToken: token,
-		TokenType:   "Bearer",
-		ExpiresIn:   int64(24 * time.Hour.Seconds()), // 与 JWT 配置中的 Duration 保持一致
-	}, nil
+	return authResp, nil
+}
+
+// rehashIfNeeded 登录成功后按当前配置升级存量哈希（明文、旧算法或旧参数），失败不影响登录
+func (s *UserService) rehashIfNeeded(userInfo *user.Users, plain string) {
+	if !s.hasher.NeedsRehash(userInfo.PasswordHash) {
+		return
+	}
+
+	hash, err := s.hasher.Hash(plain)
+	if err != nil {
+		s.logger.Error("重新计算密码哈希失败", zap.String("username", userInfo.Username), zap.Error(err))
+		return
+	}
+	if err := s.repo.UpdatePasswordHash(userInfo.ID, hash); err != nil {
+		s.logger.Error("升级密码哈希失败", zap.String("username", userInfo.Username), zap.Error(err))
+		return
+	}
+
+	userInfo.PasswordHash = hash
+	s.logger.Info("密码哈希已升级", zap.String("username", userInfo.Username), zap.String("algorithm", s.hasher.Algorithm()))
+}
+
+// UnlockUser 解除账户锁定并清零登录失败次数
+func (s *UserService) UnlockUser(userID string) error {
+	id, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return ErrInvalidUserID
+	}
+
+	userInfo, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if err := s.repo.ResetLoginFailures(userInfo.ID); err != nil {
+		return fmt.Errorf("解除账户锁定失败: %w", err)
+	}
+
+	s.logger.Info("账户已解锁", zap.String("username", userInfo.Username))
+	return nil
 }
 
 // GetUserFromToken 从 token 中获取用户信息
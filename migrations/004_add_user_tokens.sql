@@ -0,0 +1,22 @@
+-- refresh token 表，只保存 token 的 SHA-256 摘要
+CREATE TABLE IF NOT EXISTS `user_refresh_tokens` (
+  `token_hash` CHAR(64) NOT NULL COMMENT 'refresh token SHA-256 摘要',
+  `family_id` CHAR(36) NOT NULL COMMENT 'token 家族ID，同一次登录轮换出的 token 属于同一家族',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+  `expires_at` DATETIME NOT NULL COMMENT '过期时间',
+  `used_at` DATETIME NULL COMMENT '轮换使用时间',
+  `revoked_at` DATETIME NULL COMMENT '吊销时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`token_hash`),
+  INDEX `idx_user_refresh_tokens_family_id` (`family_id`),
+  INDEX `idx_user_refresh_tokens_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- access token 吊销列表（jti），过期后的记录可定期清理
+CREATE TABLE IF NOT EXISTS `user_revoked_tokens` (
+  `jti` VARCHAR(64) NOT NULL COMMENT 'access token jti',
+  `expires_at` DATETIME NOT NULL COMMENT 'token 过期时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '吊销时间',
+  PRIMARY KEY (`jti`),
+  INDEX `idx_user_revoked_tokens_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,57 @@
+-- 角色表
+CREATE TABLE IF NOT EXISTS `roles` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `name` VARCHAR(64) NOT NULL COMMENT '角色名',
+  `description` VARCHAR(255) NULL COMMENT '描述',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_roles_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 权限表，code 格式为 资源:操作，支持 * 通配（如 users:*、*）
+CREATE TABLE IF NOT EXISTS `permissions` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `code` VARCHAR(128) NOT NULL COMMENT '权限码',
+  `description` VARCHAR(255) NULL COMMENT '描述',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_permissions_code` (`code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 角色权限关联表
+CREATE TABLE IF NOT EXISTS `role_permissions` (
+  `role_id` BIGINT UNSIGNED NOT NULL COMMENT '角色ID',
+  `permission_id` BIGINT UNSIGNED NOT NULL COMMENT '权限ID',
+  PRIMARY KEY (`role_id`, `permission_id`),
+  INDEX `idx_role_permissions_permission_id` (`permission_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 用户角色关联表
+CREATE TABLE IF NOT EXISTS `user_roles` (
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+  `role_id` BIGINT UNSIGNED NOT NULL COMMENT '角色ID',
+  PRIMARY KEY (`user_id`, `role_id`),
+  INDEX `idx_user_roles_role_id` (`role_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 内置角色与权限
+INSERT IGNORE INTO `permissions` (`code`, `description`)
+VALUES
+  ('*', '全部权限'),
+  ('admin:access', '访问管理后台'),
+  ('users:read', '查看用户'),
+  ('users:write', '创建、修改、删除用户'),
+  ('users:unlock', '解除用户锁定');
+
+INSERT IGNORE INTO `roles` (`name`, `description`)
+VALUES
+  ('admin', '系统管理员，拥有全部权限'),
+  ('user-manager', '用户管理员'),
+  ('auditor', '只读审计');
+
+INSERT IGNORE INTO `role_permissions` (`role_id`, `permission_id`)
+SELECT r.id, p.id FROM `roles` r JOIN `permissions` p
+WHERE (r.name = 'admin' AND p.code = '*')
+   OR (r.name = 'user-manager' AND p.code IN ('admin:access', 'users:read', 'users:write', 'users:unlock'))
+   OR (r.name = 'auditor' AND p.code IN ('admin:access', 'users:read'));
@@ -0,0 +1,8 @@
+-- 已使用的一次性操作 token（邮箱验证等），token 本身为签名 JWT，只需记录 jti 防止重复使用
+CREATE TABLE IF NOT EXISTS `user_used_action_tokens` (
+  `jti` VARCHAR(64) NOT NULL COMMENT '操作 token jti',
+  `expires_at` DATETIME NOT NULL COMMENT 'token 过期时间，过期后的记录可定期清理',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '使用时间',
+  PRIMARY KEY (`jti`),
+  INDEX `idx_user_used_action_tokens_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,3 @@
+-- refresh token 记录签发时的用户安全戳，修改或重置密码后旧 refresh token 失效
+ALTER TABLE `user_refresh_tokens`
+  ADD COLUMN `security_stamp` VARCHAR(100) NOT NULL DEFAULT '' COMMENT '签发时的用户安全戳' AFTER `user_id`;
@@ -0,0 +1,13 @@
+-- 用户在第三方身份提供方（OIDC）的身份关联，同一提供方的 subject 只能关联一个用户
+CREATE TABLE IF NOT EXISTS `user_identities` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+  `provider` VARCHAR(64) NOT NULL COMMENT '身份提供方',
+  `subject` VARCHAR(255) NOT NULL COMMENT '提供方用户标识（sub）',
+  `email` VARCHAR(255) NULL COMMENT '提供方返回的邮箱',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '关联时间',
+  `last_login_at` DATETIME NULL COMMENT '最后通过该身份登录时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_identities_provider_subject` (`provider`, `subject`),
+  INDEX `idx_user_identities_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
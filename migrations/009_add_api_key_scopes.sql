@@ -0,0 +1,19 @@
+-- API 密钥表，同时作为 OAuth2 客户端：api_key 为 client_id，api_secret 为 client_secret
+CREATE TABLE IF NOT EXISTS `api_keys` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `api_key` VARCHAR(64) NOT NULL COMMENT 'API密钥',
+  `api_secret` VARCHAR(128) NOT NULL COMMENT 'API密钥对应的秘钥',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '状态：0-禁用，1-启用',
+  `expired_at` TIMESTAMP NOT NULL COMMENT '过期时间',
+  `description` VARCHAR(255) NULL COMMENT '描述',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '更新时间',
+  `deleted_at` DATETIME NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_api_keys_api_key` (`api_key`),
+  INDEX `idx_api_keys_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 客户端可申请的 OAuth2 授权范围
+ALTER TABLE `api_keys`
+  ADD COLUMN `scopes` VARCHAR(512) NOT NULL DEFAULT '' COMMENT 'OAuth2 授权范围，空格分隔' AFTER `description`;
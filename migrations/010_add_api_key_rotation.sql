@@ -0,0 +1,14 @@
+-- API 密钥秘钥轮换：轮换后旧秘钥在过渡期内仍可验证
+ALTER TABLE `api_keys`
+  ADD COLUMN `previous_secret` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '轮换前的秘钥，过渡期内仍可使用' AFTER `api_secret`,
+  ADD COLUMN `previous_secret_expires_at` TIMESTAMP NULL COMMENT '旧秘钥失效时间' AFTER `previous_secret`;
+
+-- API 密钥管理权限
+INSERT IGNORE INTO `permissions` (`code`, `description`)
+VALUES
+  ('apikeys:read', '查看API密钥'),
+  ('apikeys:write', '创建、禁用、续期、轮换API密钥');
+
+INSERT IGNORE INTO `role_permissions` (`role_id`, `permission_id`)
+SELECT r.id, p.id FROM `roles` r JOIN `permissions` p
+WHERE r.name = 'auditor' AND p.code = 'apikeys:read';
@@ -0,0 +1,3 @@
+-- API 密钥允许访问的路由规则，格式为 [METHOD[,METHOD...]:]/path/prefix，空格分隔，为空时不限制
+ALTER TABLE `api_keys`
+  ADD COLUMN `allowed_routes` VARCHAR(1024) NOT NULL DEFAULT '' COMMENT '允许访问的路由，空格分隔，为空时不限制' AFTER `scopes`;
@@ -0,0 +1,15 @@
+-- API 密钥限流与配额，0 表示不限制
+ALTER TABLE `api_keys`
+  ADD COLUMN `rate_limit` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '每分钟请求数上限，0 表示不限制' AFTER `allowed_routes`,
+  ADD COLUMN `daily_quota` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '每日请求数配额，0 表示不限制' AFTER `rate_limit`,
+  ADD COLUMN `monthly_quota` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '每月请求数配额，0 表示不限制' AFTER `daily_quota`;
+
+-- API 密钥每日调用量，由定时任务从 Redis 计数器刷入
+CREATE TABLE IF NOT EXISTS `api_key_usage` (
+  `api_key` VARCHAR(64) NOT NULL COMMENT 'API密钥',
+  `usage_date` DATE NOT NULL COMMENT '统计日期',
+  `request_count` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '请求数',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`api_key`, `usage_date`),
+  INDEX `idx_api_key_usage_date` (`usage_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,7 @@
+-- 敏感字段加密存储：信封加密的密文比明文长，扩大列长度
+ALTER TABLE `api_keys`
+  MODIFY COLUMN `api_secret` VARCHAR(512) NOT NULL COMMENT 'API密钥对应的秘钥（配置主密钥后加密存储）',
+  MODIFY COLUMN `previous_secret` VARCHAR(512) NOT NULL DEFAULT '' COMMENT '轮换前的秘钥（加密存储），过渡期内仍可使用';
+
+ALTER TABLE `t_users`
+  MODIFY COLUMN `two_factor_secret` VARCHAR(512) NULL COMMENT '双重认证秘钥（加密存储）';
@@ -0,0 +1,9 @@
+-- 调度任务管理权限
+INSERT IGNORE INTO `permissions` (`code`, `description`)
+VALUES
+  ('scheduler:read', '查看调度任务'),
+  ('scheduler:write', '触发、暂停、恢复、修改、删除调度任务');
+
+INSERT IGNORE INTO `role_permissions` (`role_id`, `permission_id`)
+SELECT r.id, p.id FROM `roles` r JOIN `permissions` p
+WHERE r.name = 'auditor' AND p.code = 'scheduler:read';
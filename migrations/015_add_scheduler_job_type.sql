@@ -0,0 +1,11 @@
+-- 调度任务记录任务类型与参数，服务重启后按任务类型绑定已注册的执行函数
+ALTER TABLE `scheduler_tasks`
+  ADD COLUMN `job_type` VARCHAR(64) NOT NULL DEFAULT '' COMMENT '任务类型，对应代码中注册的任务处理函数' AFTER `schedule`,
+  ADD COLUMN `params` TEXT NULL COMMENT '任务参数（JSON）' AFTER `job_type`,
+  ADD INDEX `idx_scheduler_tasks_job_type` (`job_type`);
+
+-- 为已有的内置任务补充任务类型
+UPDATE `scheduler_tasks` SET `job_type` = 'demo.log', `params` = '{"message":"Demo task 1 executed"}' WHERE `id` = 'demo-task-1';
+UPDATE `scheduler_tasks` SET `job_type` = 'demo.log', `params` = '{"message":"Demo task 2 executed"}' WHERE `id` = 'demo-task-2';
+UPDATE `scheduler_tasks` SET `job_type` = 'demo.log', `params` = '{"message":"Demo task 3 executed (cron expression)"}' WHERE `id` = 'demo-task-3';
+UPDATE `scheduler_tasks` SET `job_type` = 'apikey.usage_flush' WHERE `id` = 'apikey-usage-flush';
@@ -0,0 +1,16 @@
+-- 调度任务执行历史，每次执行一条记录，超过保留期的记录由定时任务清理
+CREATE TABLE IF NOT EXISTS `scheduler_task_runs` (
+  `id` VARCHAR(36) NOT NULL COMMENT '执行ID',
+  `task_id` VARCHAR(64) NOT NULL COMMENT '任务ID',
+  `started_at` DATETIME(3) NOT NULL COMMENT '开始时间',
+  `finished_at` DATETIME(3) NULL COMMENT '结束时间，执行中为空',
+  `duration_ms` BIGINT NOT NULL DEFAULT 0 COMMENT '耗时（毫秒）',
+  `status` VARCHAR(20) NOT NULL COMMENT '执行状态：running、completed、failed',
+  `error` TEXT NULL COMMENT '错误信息',
+  `output` MEDIUMTEXT NULL COMMENT '任务输出',
+  `node` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '执行实例',
+  `trigger` VARCHAR(20) NOT NULL DEFAULT '' COMMENT '触发方式：schedule 按计划、manual 手动',
+  PRIMARY KEY (`id`),
+  INDEX `idx_scheduler_task_runs_task_started` (`task_id`, `started_at`),
+  INDEX `idx_scheduler_task_runs_started_at` (`started_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
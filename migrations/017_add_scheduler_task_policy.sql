@@ -0,0 +1,6 @@
+-- 调度任务的执行策略（超时与重试），为空时使用配置中的默认策略；执行历史记录每次重试
+ALTER TABLE `scheduler_tasks`
+  ADD COLUMN `policy` TEXT NULL COMMENT '执行策略（JSON）：超时时间、最大执行次数、退避时间与抖动，为空时使用默认策略' AFTER `params`;
+
+ALTER TABLE `scheduler_task_runs`
+  ADD COLUMN `attempt` INT NOT NULL DEFAULT 1 COMMENT '第几次执行，重试时递增' AFTER `trigger`;
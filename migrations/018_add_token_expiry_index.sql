@@ -0,0 +1,3 @@
+-- 过期 token 由定时任务按 expires_at 清理，为 refresh token 表补充过期时间索引
+ALTER TABLE `user_refresh_tokens`
+  ADD INDEX `idx_user_refresh_tokens_expires_at` (`expires_at`);
@@ -0,0 +1,5 @@
+-- 超级管理员的授予与撤销使用独立权限，users:write 不再能修改 is_superuser
+-- 仅 admin 角色（通配符）拥有该权限，不授予 user-manager
+INSERT IGNORE INTO `permissions` (`code`, `description`)
+VALUES
+  ('users:admin', '授予或撤销超级管理员');
@@ -0,0 +1,4 @@
+-- 邮箱验证上线前创建的用户从未收到验证邮件，视为已验证，避免开启 requireEmailVerification 后无法登录
+UPDATE `t_users`
+SET `email_verified` = 1
+WHERE `email_verified` = 0;
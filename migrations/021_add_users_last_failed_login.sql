@@ -0,0 +1,3 @@
+-- 记录最近一次登录失败时间，超过观察窗口的失败不再累计，避免零星输错密码累积到锁定
+ALTER TABLE `t_users`
+  ADD COLUMN `last_failed_login_at` DATETIME NULL COMMENT '最近一次登录失败时间' AFTER `failed_login_attempts`;
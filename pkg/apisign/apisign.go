@@ -0,0 +1,225 @@
+// Package apisign 实现 OpenAPI 请求签名，供服务端验证与合作方客户端签名共用
+//
+// 签名步骤：
+//
+//  1. 构造规范请求（各部分以换行分隔）：
+//     HTTP 方法（大写）、URL 编码后的路径、按参数名与参数值排序的查询字符串、
+//     请求体的 SHA-256（十六进制）、时间戳（Unix 秒）、随机数 nonce；
+//  2. 待签名字符串为 Algorithm + "\n" + APIKey + "\n" + 规范请求；
+//  3. 签名为 HMAC-SHA256(APISecret, 待签名字符串) 的十六进制编码。
+//
+// 签名与 APIKey、时间戳、nonce 分别通过 X-API-Sign、X-API-Key、X-API-Timestamp、X-API-Nonce 请求头传递。
+// 服务端只接受时间偏差在 5 分钟内的请求，且同一 nonce 只能使用一次。
+package apisign
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Algorithm 签名算法标识
+const Algorithm = "OPENAPI-HMAC-SHA256"
+
+// 签名相关请求头
+const (
+	HeaderAPIKey    = "X-API-Key"
+	HeaderSign      = "X-API-Sign"
+	HeaderTimestamp = "X-API-Timestamp"
+	HeaderNonce     = "X-API-Nonce"
+)
+
+// nonce 长度限制
+const (
+	nonceBytes     = 16
+	minNonceLength = 16
+	maxNonceLength = 64
+)
+
+// ErrInvalidQuery 查询字符串格式错误
+var ErrInvalidQuery = errors.New("apisign: invalid query string")
+
+// BodyHash 计算请求体的 SHA-256 十六进制摘要，空请求体同样需要计算
+func BodyHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// CanonicalRequest 构造规范请求
+func CanonicalRequest(method, escapedPath, rawQuery, bodyHash, timestamp, nonce string) (string, error) {
+	query, err := canonicalQuery(rawQuery)
+	if err != nil {
+		return "", err
+	}
+	if escapedPath == "" {
+		escapedPath = "/"
+	}
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		escapedPath,
+		query,
+		bodyHash,
+		timestamp,
+		nonce,
+	}, "\n"), nil
+}
+
+// CanonicalHTTPRequest 根据 HTTP 请求构造规范请求，body 为完整的请求体
+func CanonicalHTTPRequest(req *http.Request, body []byte, timestamp, nonce string) (string, error) {
+	return CanonicalRequest(req.Method, req.URL.EscapedPath(), req.URL.RawQuery, BodyHash(body), timestamp, nonce)
+}
+
+// Sign 计算签名
+func Sign(apiKey, secret, canonical string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(Algorithm + "\n" + apiKey + "\n" + canonical))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify 使用常量时间比较校验签名
+func Verify(apiKey, secret, canonical, signature string) bool {
+	provided, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	expected, _ := hex.DecodeString(Sign(apiKey, secret, canonical))
+	return hmac.Equal(provided, expected)
+}
+
+// NewNonce 生成随机 nonce
+func NewNonce() (string, error) {
+	buf := make([]byte, nonceBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// ValidNonce 检查 nonce 格式：16 至 64 位字母、数字、"-" 或 "_"
+func ValidNonce(nonce string) bool {
+	if len(nonce) < minNonceLength || len(nonce) > maxNonceLength {
+		return false
+	}
+	for _, r := range nonce {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// Signer 客户端签名器
+type Signer struct {
+	APIKey string
+	Secret string
+	// Now 获取当前时间，为空时使用 time.Now
+	Now func() time.Time
+}
+
+// NewSigner 创建客户端签名器
+func NewSigner(apiKey, secret string) *Signer {
+	return &Signer{APIKey: apiKey, Secret: secret}
+}
+
+// SignRequest 为请求设置签名相关请求头；会读取并还原请求体
+func (s *Signer) SignRequest(req *http.Request) error {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	timestamp := strconv.FormatInt(now().Unix(), 10)
+	nonce, err := NewNonce()
+	if err != nil {
+		return err
+	}
+
+	canonical, err := CanonicalHTTPRequest(req, body, timestamp, nonce)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(HeaderAPIKey, s.APIKey)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(HeaderSign, Sign(s.APIKey, s.Secret, canonical))
+	return nil
+}
+
+// Transport 返回自动为每个请求签名的 http.RoundTripper，base 为空时使用 http.DefaultTransport
+func (s *Signer) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{signer: s, base: base}
+}
+
+type transport struct {
+	signer *Signer
+	base   http.RoundTripper
+}
+
+// RoundTrip 签名请求的副本后发送，不修改调用方的请求
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	clone := req.Clone(req.Context())
+	if err := t.signer.SignRequest(clone); err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+	return t.base.RoundTrip(clone)
+}
+
+// canonicalQuery 按参数名、参数值排序并重新编码查询字符串，空格编码为 %20
+func canonicalQuery(rawQuery string) (string, error) {
+	if rawQuery == "" {
+		return "", nil
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", ErrInvalidQuery
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			pairs = append(pairs, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(pairs, "&"), nil
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
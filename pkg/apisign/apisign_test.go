@@ -0,0 +1,117 @@
+package apisign
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testKey    = "ak_test"
+	testSecret = "secret"
+)
+
+// verifyRequest 按服务端的方式校验请求签名
+func verifyRequest(t *testing.T, req *http.Request) bool {
+	t.Helper()
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := CanonicalHTTPRequest(req, body, req.Header.Get(HeaderTimestamp), req.Header.Get(HeaderNonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Verify(req.Header.Get(HeaderAPIKey), testSecret, canonical, req.Header.Get(HeaderSign))
+}
+
+func TestSignRequest(t *testing.T) {
+	signer := NewSigner(testKey, testSecret)
+	signer.Now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	req := httptest.NewRequest(http.MethodPost, "/openapi/data?b=2&a=x+y&a=1", strings.NewReader(`{"id":1}`))
+	if err := signer.SignRequest(req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get(HeaderTimestamp) != "1700000000" || !ValidNonce(req.Header.Get(HeaderNonce)) {
+		t.Fatalf("unexpected headers: %v", req.Header)
+	}
+
+	// 请求体签名后需可再次读取
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != `{"id":1}` {
+		t.Fatalf("body = %q", body)
+	}
+	req.Body = io.NopCloser(strings.NewReader(string(body)))
+	if !verifyRequest(t, req) {
+		t.Fatal("signature should verify")
+	}
+
+	// 查询参数顺序不影响签名
+	reordered := httptest.NewRequest(http.MethodPost, "/openapi/data?a=1&b=2&a=x%20y", strings.NewReader(`{"id":1}`))
+	reordered.Header = req.Header.Clone()
+	if !verifyRequest(t, reordered) {
+		t.Error("reordered query should verify")
+	}
+
+	for name, tampered := range map[string]*http.Request{
+		"body":   httptest.NewRequest(http.MethodPost, "/openapi/data?b=2&a=x+y&a=1", strings.NewReader(`{"id":2}`)),
+		"query":  httptest.NewRequest(http.MethodPost, "/openapi/data?b=3&a=x+y&a=1", strings.NewReader(`{"id":1}`)),
+		"path":   httptest.NewRequest(http.MethodPost, "/openapi/other?b=2&a=x+y&a=1", strings.NewReader(`{"id":1}`)),
+		"method": httptest.NewRequest(http.MethodPut, "/openapi/data?b=2&a=x+y&a=1", strings.NewReader(`{"id":1}`)),
+	} {
+		tampered.Header = req.Header.Clone()
+		if verifyRequest(t, tampered) {
+			t.Errorf("tampered %s should not verify", name)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var verified bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verified = verifyRequest(t, r)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewSigner(testKey, testSecret).Transport(nil)}
+	resp, err := client.Post(server.URL+"/openapi/data?q=1", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if !verified {
+		t.Error("request sent through Transport should verify")
+	}
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	got, err := canonicalQuery("b=2&a=x+y&a=1&c=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a=1&a=x%20y&b=2&c="; got != want {
+		t.Errorf("canonicalQuery = %q, want %q", got, want)
+	}
+	if _, err := canonicalQuery("a=%zz"); err != ErrInvalidQuery {
+		t.Errorf("error = %v, want ErrInvalidQuery", err)
+	}
+}
+
+func TestValidNonce(t *testing.T) {
+	nonce, _ := NewNonce()
+	for n, want := range map[string]bool{
+		nonce:                    true,
+		"short":                  false,
+		strings.Repeat("a", 65):  false,
+		"0123456789abcdef-_ABCD": true,
+		"0123456789abcdef:12345": false,
+	} {
+		if ValidNonce(n) != want {
+			t.Errorf("ValidNonce(%q) = %v, want %v", n, !want, want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Package envelope 实现基于 AES-256-GCM 的信封加密
+//
+// 每次加密生成随机的数据密钥（DEK）加密明文，再用主密钥（KEK）加密数据密钥，
+// 密文格式为 "v1:<主密钥ID>:<base64url(加密的数据密钥)>:<base64url(加密的数据)>"。
+// 密文中带有主密钥ID，轮换主密钥后旧密文仍可由旧主密钥解密，
+// 并可通过 Rewrap 只重新加密数据密钥，将其迁移到新主密钥下。
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// KeySize 主密钥与数据密钥的字节数（AES-256）
+const KeySize = 32
+
+// version 密文格式版本
+const version = "v1"
+
+var (
+	// ErrInvalidKey 主密钥长度错误
+	ErrInvalidKey = errors.New("主密钥必须为32字节")
+	// ErrNoKeys 未提供主密钥
+	ErrNoKeys = errors.New("至少需要一个主密钥")
+	// ErrMalformedCiphertext 密文格式错误
+	ErrMalformedCiphertext = errors.New("密文格式错误")
+	// ErrUnknownKey 密文的主密钥不在密钥环中
+	ErrUnknownKey = errors.New("密文的主密钥不在密钥环中")
+	// ErrDecrypt 解密失败（密文被篡改、附加数据不一致或密钥错误）
+	ErrDecrypt = errors.New("解密失败")
+)
+
+// masterKey 主密钥
+type masterKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// Keyring 主密钥环：使用主密钥加密，使用任一密钥解密
+type Keyring struct {
+	primary *masterKey
+	keys    map[string]*masterKey
+}
+
+// NewKeyring 创建密钥环，第一个密钥为加密使用的主密钥，其余密钥只用于解密轮换前的密文
+func NewKeyring(keys ...[]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	k := &Keyring{keys: make(map[string]*masterKey, len(keys))}
+	for _, key := range keys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		mk := &masterKey{id: KeyID(key), aead: aead}
+		if k.primary == nil {
+			k.primary = mk
+		}
+		if _, exists := k.keys[mk.id]; !exists {
+			k.keys[mk.id] = mk
+		}
+	}
+	return k, nil
+}
+
+// KeyID 由主密钥派生出不泄露密钥内容的短ID
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(append([]byte("key-id:"), key...))
+	return hex.EncodeToString(sum[:4])
+}
+
+// PrimaryKeyID 获取加密使用的主密钥ID
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary.id
+}
+
+// Encrypt 加密明文，associatedData 为附加认证数据（如记录ID），解密时必须提供相同的值
+func (k *Keyring) Encrypt(plaintext, associatedData []byte) (string, error) {
+	dek := make([]byte, KeySize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := seal(aead, plaintext, associatedData)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := k.wrap(k.primary, dek)
+	if err != nil {
+		return "", err
+	}
+	return format(k.primary.id, wrapped, data), nil
+}
+
+// Decrypt 解密由 Encrypt 生成的密文
+func (k *Keyring) Decrypt(ciphertext string, associatedData []byte) ([]byte, error) {
+	keyID, wrapped, data, err := parse(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := k.unwrap(keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+	return open(aead, data, associatedData)
+}
+
+// NeedsRewrap 判断密文是否由主密钥以外的密钥加密
+func (k *Keyring) NeedsRewrap(ciphertext string) bool {
+	keyID, _, _, err := parse(ciphertext)
+	return err == nil && keyID != k.primary.id
+}
+
+// Rewrap 使用主密钥重新加密密文中的数据密钥，数据部分保持不变，无需附加认证数据
+func (k *Keyring) Rewrap(ciphertext string) (string, error) {
+	keyID, wrapped, data, err := parse(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	if keyID == k.primary.id {
+		return ciphertext, nil
+	}
+
+	dek, err := k.unwrap(keyID, wrapped)
+	if err != nil {
+		return "", err
+	}
+	rewrapped, err := k.wrap(k.primary, dek)
+	if err != nil {
+		return "", err
+	}
+	return format(k.primary.id, rewrapped, data), nil
+}
+
+// IsEncrypted 判断值是否为信封加密的密文，用于兼容加密前保存的明文
+func IsEncrypted(value string) bool {
+	_, _, _, err := parse(value)
+	return err == nil
+}
+
+// wrap 使用主密钥加密数据密钥，以主密钥ID为附加数据
+func (k *Keyring) wrap(mk *masterKey, dek []byte) ([]byte, error) {
+	return seal(mk.aead, dek, []byte(mk.id))
+}
+
+func (k *Keyring) unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	mk, ok := k.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return open(mk.aead, wrapped, []byte(keyID))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal 加密并在结果前附加随机 nonce
+func seal(aead cipher.AEAD, plaintext, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func open(aead cipher.AEAD, sealed, associatedData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformedCiphertext
+	}
+	nonce, body := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, body, associatedData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func format(keyID string, wrapped, data []byte) string {
+	return strings.Join([]string{
+		version,
+		keyID,
+		base64.RawURLEncoding.EncodeToString(wrapped),
+		base64.RawURLEncoding.EncodeToString(data),
+	}, ":")
+}
+
+func parse(ciphertext string) (string, []byte, []byte, error) {
+	parts := strings.Split(ciphertext, ":")
+	if len(parts) != 4 || parts[0] != version || parts[1] == "" {
+		return "", nil, nil, ErrMalformedCiphertext
+	}
+	wrapped, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, ErrMalformedCiphertext
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return "", nil, nil, ErrMalformedCiphertext
+	}
+	return parts[1], wrapped, data, nil
+}
@@ -0,0 +1,146 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestRoundTrip(t *testing.T) {
+	k, err := NewKeyring(newKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := k.Encrypt([]byte("api-secret"), []byte("api_keys:ak_1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(ciphertext, "v1:"+k.PrimaryKeyID()+":") || !IsEncrypted(ciphertext) {
+		t.Errorf("ciphertext %q should carry version and key id", ciphertext)
+	}
+
+	plaintext, err := k.Decrypt(ciphertext, []byte("api_keys:ak_1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(plaintext, []byte("api-secret")) {
+		t.Errorf("plaintext = %q", plaintext)
+	}
+
+	// 附加认证数据不同（如把密文复制到其他记录）时解密失败
+	if _, err := k.Decrypt(ciphertext, []byte("api_keys:ak_2")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("error = %v, want ErrDecrypt", err)
+	}
+
+	another, _ := k.Encrypt([]byte("api-secret"), []byte("api_keys:ak_1"))
+	if another == ciphertext {
+		t.Error("ciphertexts should be randomized")
+	}
+}
+
+func TestRotation(t *testing.T) {
+	oldKey, newKeyBytes := newKey(t), newKey(t)
+	old, _ := NewKeyring(oldKey)
+	ciphertext, _ := old.Encrypt([]byte("secret"), nil)
+
+	// 轮换后新主密钥在前，旧主密钥仍可解密
+	rotated, err := NewKeyring(newKeyBytes, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rotated.NeedsRewrap(ciphertext) {
+		t.Error("ciphertext under old key should need rewrap")
+	}
+	if plaintext, err := rotated.Decrypt(ciphertext, nil); err != nil || string(plaintext) != "secret" {
+		t.Fatalf("Decrypt = %q, %v", plaintext, err)
+	}
+
+	rewrapped, err := rotated.Rewrap(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.NeedsRewrap(rewrapped) {
+		t.Error("rewrapped ciphertext should use the primary key")
+	}
+
+	// 移除旧主密钥后，重新包装的密文仍可解密，未包装的旧密文无法解密
+	onlyNew, _ := NewKeyring(newKeyBytes)
+	if plaintext, err := onlyNew.Decrypt(rewrapped, nil); err != nil || string(plaintext) != "secret" {
+		t.Fatalf("Decrypt rewrapped = %q, %v", plaintext, err)
+	}
+	if _, err := onlyNew.Decrypt(ciphertext, nil); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("error = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestDecryptRejectsMalformed(t *testing.T) {
+	k, _ := NewKeyring(newKey(t))
+	ciphertext, _ := k.Encrypt([]byte("secret"), nil)
+	parts := strings.Split(ciphertext, ":")
+
+	for _, tc := range []string{
+		"",
+		"plaintext-secret",
+		"abcd1234:legacyformat",
+		"v2:" + strings.Join(parts[1:], ":"),
+		strings.Join(parts[:3], ":"),
+		parts[0] + "::" + strings.Join(parts[2:], ":"),
+		strings.Join(parts[:3], ":") + ":!!",
+	} {
+		if IsEncrypted(tc) {
+			t.Errorf("IsEncrypted(%q) = true", tc)
+		}
+		if _, err := k.Decrypt(tc, nil); !errors.Is(err, ErrMalformedCiphertext) {
+			t.Errorf("Decrypt(%q) error = %v, want ErrMalformedCiphertext", tc, err)
+		}
+	}
+
+	// 篡改数据部分
+	tampered := strings.Join(parts[:3], ":") + ":" + parts[3][:len(parts[3])-2] + "AA"
+	if _, err := k.Decrypt(tampered, nil); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("error = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	primary, old := newKey(t), newKey(t)
+	path := filepath.Join(t.TempDir(), "master.keys")
+	content := "# 当前主密钥\n" + base64.StdEncoding.EncodeToString(primary) + "\n\n" + base64.StdEncoding.EncodeToString(old) + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := NewKeyring(keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.PrimaryKeyID() != KeyID(primary) {
+		t.Error("first key in file should be the primary key")
+	}
+
+	if _, err := ParseKeys([]string{base64.StdEncoding.EncodeToString([]byte("short"))}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("error = %v, want ErrInvalidKey", err)
+	}
+	if _, err := NewKeyring(); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("error = %v, want ErrNoKeys", err)
+	}
+}
@@ -0,0 +1,48 @@
+package envelope
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ParseKeys 解析 base64 编码的主密钥列表
+func ParseKeys(encoded []string) ([][]byte, error) {
+	keys := make([][]byte, 0, len(encoded))
+	for i, e := range encoded {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(e))
+		if err != nil {
+			return nil, fmt.Errorf("%w: 第%d个密钥不是合法的base64", ErrInvalidKey, i+1)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("%w: 第%d个密钥长度为%d字节", ErrInvalidKey, i+1, len(key))
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// LoadKeyFile 从文件加载主密钥：每行一个 base64 编码的密钥，第一个为加密使用的主密钥，忽略空行与 # 开头的注释
+func LoadKeyFile(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var encoded []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		encoded = append(encoded, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ParseKeys(encoded)
+}
@@ -0,0 +1,98 @@
+package envelope
+
+import (
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrReadOnly 没有主密钥，只能解密旧格式的密文
+	ErrReadOnly = errors.New("旧的加密密钥只能用于解密，请配置主密钥")
+	// ErrKeyMismatch 旧格式密文不是由当前旧密钥加密的
+	ErrKeyMismatch = errors.New("密文的密钥ID与旧密钥不匹配")
+)
+
+// Cipher 字段加密器，Keyring 与 WithLegacy 的返回值实现该接口
+type Cipher interface {
+	// Encrypt 加密明文，associatedData 为附加认证数据，解密时必须提供相同的值
+	Encrypt(plaintext, associatedData []byte) (string, error)
+	// Decrypt 解密密文
+	Decrypt(ciphertext string, associatedData []byte) ([]byte, error)
+}
+
+// LegacyKey 信封加密之前的单密钥 AES-256-GCM 密钥，只用于解密旧格式的密文
+//
+// 旧格式密文为 "<密钥ID>:<base64(nonce || ciphertext || tag)>"，密钥ID 与 KeyID 的派生方式相同。
+//
+// Deprecated: 执行 cmd/rekey 将旧密文迁移到信封加密后不再需要，v2.0.0 将与 twoFactor.encryptionKey 配置一并移除。
+type LegacyKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewLegacyKey 使用 32 字节密钥创建旧密钥
+func NewLegacyKey(key []byte) (*LegacyKey, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &LegacyKey{id: KeyID(key), aead: aead}, nil
+}
+
+// ParseLegacyKey 使用 base64 编码的密钥创建旧密钥
+func ParseLegacyKey(encoded string) (*LegacyKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("%w: 不是合法的base64", ErrInvalidKey)
+	}
+	return NewLegacyKey(key)
+}
+
+// Decrypt 解密旧格式的密文，associatedData 须与加密时相同
+func (l *LegacyKey) Decrypt(ciphertext string, associatedData []byte) ([]byte, error) {
+	keyID, encoded, ok := strings.Cut(ciphertext, ":")
+	if !ok {
+		return nil, ErrMalformedCiphertext
+	}
+	if keyID != l.id {
+		return nil, ErrKeyMismatch
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrMalformedCiphertext
+	}
+	return open(l.aead, sealed, associatedData)
+}
+
+// legacyCipher 使用密钥环加密，解密时按密文格式选择密钥环或旧密钥
+type legacyCipher struct {
+	keyring *Keyring
+	legacy  *LegacyKey
+}
+
+// WithLegacy 返回兼容旧格式密文的加密器，用于从单密钥密文迁移到信封加密：
+// 使用 keyring 加密，keyring 为 nil 时只能解密旧密文，Encrypt 返回 ErrReadOnly；legacy 为 nil 时返回 keyring
+func WithLegacy(keyring *Keyring, legacy *LegacyKey) Cipher {
+	if legacy == nil {
+		return keyring
+	}
+	return &legacyCipher{keyring: keyring, legacy: legacy}
+}
+
+func (c *legacyCipher) Encrypt(plaintext, associatedData []byte) (string, error) {
+	if c.keyring == nil {
+		return "", ErrReadOnly
+	}
+	return c.keyring.Encrypt(plaintext, associatedData)
+}
+
+func (c *legacyCipher) Decrypt(ciphertext string, associatedData []byte) ([]byte, error) {
+	if c.keyring != nil && IsEncrypted(ciphertext) {
+		return c.keyring.Decrypt(ciphertext, associatedData)
+	}
+	return c.legacy.Decrypt(ciphertext, associatedData)
+}
@@ -0,0 +1,80 @@
+package envelope
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+// legacyEncrypt 生成旧格式的密文，旧密钥已不再提供加密
+func legacyEncrypt(t *testing.T, l *LegacyKey, plaintext, associatedData []byte) string {
+	t.Helper()
+	sealed, err := seal(l.aead, plaintext, associatedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l.id + ":" + base64.RawStdEncoding.EncodeToString(sealed)
+}
+
+func TestLegacyKey(t *testing.T) {
+	l, err := ParseLegacyKey(base64.StdEncoding.EncodeToString(newKey(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := legacyEncrypt(t, l, []byte("JBSWY3DPEHPK3PXP"), []byte("user:1"))
+	plaintext, err := l.Decrypt(ciphertext, []byte("user:1"))
+	if err != nil || string(plaintext) != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("Decrypt() = %q, %v", plaintext, err)
+	}
+
+	// 附加认证数据不同（如把密文复制给其他用户）时解密失败
+	if _, err := l.Decrypt(ciphertext, []byte("user:2")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("error = %v, want ErrDecrypt", err)
+	}
+
+	other, _ := NewLegacyKey(newKey(t))
+	if _, err := other.Decrypt(ciphertext, nil); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("error = %v, want ErrKeyMismatch", err)
+	}
+	if _, err := l.Decrypt("no-separator", nil); !errors.Is(err, ErrMalformedCiphertext) {
+		t.Errorf("error = %v, want ErrMalformedCiphertext", err)
+	}
+
+	if _, err := NewLegacyKey([]byte("short")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("error = %v, want ErrInvalidKey", err)
+	}
+	if _, err := ParseLegacyKey("%%%"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("error = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestWithLegacy(t *testing.T) {
+	legacy, _ := NewLegacyKey(newKey(t))
+	old := legacyEncrypt(t, legacy, []byte("secret"), nil)
+
+	// 没有主密钥时只能读取已有的旧密文
+	readOnly := WithLegacy(nil, legacy)
+	if _, err := readOnly.Encrypt([]byte("secret"), nil); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Encrypt() error = %v, want ErrReadOnly", err)
+	}
+	if plaintext, err := readOnly.Decrypt(old, nil); err != nil || string(plaintext) != "secret" {
+		t.Errorf("Decrypt() = %q, %v, want existing ciphertexts readable", plaintext, err)
+	}
+
+	k, _ := NewKeyring(newKey(t))
+	migrating := WithLegacy(k, legacy)
+	ciphertext, err := migrating.Encrypt([]byte("new"), nil)
+	if err != nil || !IsEncrypted(ciphertext) {
+		t.Fatalf("Encrypt() = %q, %v, want an envelope ciphertext", ciphertext, err)
+	}
+	for value, want := range map[string]string{ciphertext: "new", old: "secret"} {
+		if plaintext, err := migrating.Decrypt(value, nil); err != nil || string(plaintext) != want {
+			t.Errorf("Decrypt() = %q, %v, want %q", plaintext, err, want)
+		}
+	}
+
+	if WithLegacy(k, nil) != Cipher(k) {
+		t.Error("WithLegacy() without a legacy key should return the keyring")
+	}
+}